@@ -0,0 +1,114 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+var (
+	buffValueAdditions []string
+	buffValueCosts     []string
+	buffValueAddAll    bool
+	buffValueFormat    string
+)
+
+var buffValueCmd = &cobra.Command{
+	Use:   "buffvalue",
+	Short: "simulate the dps value of each buff, debuff and consumable",
+	Long: `simulate the dps value of each buff, debuff and consumable
+
+Every enabled buff, world buff, debuff and consumable is removed one at a time.
+With --additions, every disabled buff and debuff is added one at a time as well.
+Consumables to add can be given with --add path=value, e.g.
+  --add raid.parties.0.players.0.consumes.flask=FlaskOfSupremePower
+Gold costs per fight are given with --cost key=gold, where key is the entry
+path or a suffix of it, e.g. --cost consumes.flask=12.5`,
+	Run: buffValueMain,
+}
+
+func init() {
+	buffValueCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	buffValueCmd.Flags().BoolVar(&buffValueAddAll, "additions", false, "also evaluate adding each disabled buff and debuff")
+	buffValueCmd.Flags().StringArrayVar(&buffValueAdditions, "add", nil, "entry to evaluate adding, in the form path=value (repeatable)")
+	buffValueCmd.Flags().StringArrayVar(&buffValueCosts, "cost", nil, "gold cost of an entry, in the form key=gold (repeatable)")
+	buffValueCmd.Flags().StringVar(&buffValueFormat, "format", "table", "output format, 'table', 'csv' or 'json'")
+	buffValueCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	buffValueCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	buffValueCmd.MarkFlagRequired("infile")
+}
+
+func buffValueMain(cmd *cobra.Command, args []string) {
+	request := &proto.BuffValueRequest{
+		BaseSettings:     loadRaidSimRequest(infile),
+		IncludeAdditions: buffValueAddAll,
+		GoldCosts:        map[string]float64{},
+	}
+	for _, addition := range buffValueAdditions {
+		path, value, ok := strings.Cut(addition, "=")
+		if !ok || path == "" || value == "" {
+			log.Fatalf("invalid addition %q, expected path=value", addition)
+		}
+		request.Additions = append(request.Additions, &proto.SweepPointValue{Path: path, Value: value})
+	}
+	for _, cost := range buffValueCosts {
+		key, value, ok := strings.Cut(cost, "=")
+		gold, err := strconv.ParseFloat(value, 64)
+		if !ok || key == "" || err != nil {
+			log.Fatalf("invalid cost %q, expected key=gold", cost)
+		}
+		request.GoldCosts[key] = gold
+	}
+
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunBuffValueAsync(request, reporter, "cmd-buff-value")
+
+	var result *proto.BuffValueResult
+	for v := range reporter {
+		if v.FinalBuffValueResult != nil {
+			result = v.FinalBuffValueResult
+			break
+		}
+		if verbose && v.TotalSims > 0 {
+			fmt.Printf("Buff Value Progress: %d / %d iterations (completed %d / %d sims)\n", v.CompletedIterations, v.TotalIterations, v.CompletedSims, v.TotalSims)
+		}
+	}
+	if result.Error != nil {
+		log.Fatalf("buff value failed: %s", result.Error.Message)
+	}
+
+	writeOutput(formatResult(buffValueFormat, buffValueRows(result), result))
+}
+
+// buffValueRows returns the header and one row per entry, highest value first.
+func buffValueRows(result *proto.BuffValueResult) [][]string {
+	rows := [][]string{{"entry", "value", "change", "dps_delta", "dps_delta_stdev", "raid_dps_delta", "gold_cost", "dps_per_gold"}}
+	rows = append(rows, []string{"[BASE RESULT]", "", "", fmt.Sprintf("%0.1f", result.BaseDps), "", fmt.Sprintf("%0.1f", result.BaseRaidDps), "", ""})
+	for _, entry := range result.Entries {
+		change := "added"
+		if entry.Removed {
+			change = "removed"
+		}
+		goldCost, dpsPerGold := "", ""
+		if entry.GoldCost > 0 {
+			goldCost = fmt.Sprintf("%0.2f", entry.GoldCost)
+			dpsPerGold = fmt.Sprintf("%0.2f", entry.DpsPerGold)
+		}
+		rows = append(rows, []string{
+			entry.Path,
+			entry.Value,
+			change,
+			fmt.Sprintf("%0.2f", entry.DpsDelta),
+			fmt.Sprintf("%0.2f", entry.DpsDeltaStdev),
+			fmt.Sprintf("%0.2f", entry.RaidDpsDelta),
+			goldCost,
+			dpsPerGold,
+		})
+	}
+	return rows
+}
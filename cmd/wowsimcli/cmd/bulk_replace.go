@@ -5,13 +5,15 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/assets/database"
 	"github.com/wowsims/classic/sim/core"
 	"github.com/wowsims/classic/sim/core/proto"
-	"google.golang.org/protobuf/encoding/protojson"
 )
 
 var (
@@ -19,6 +21,9 @@ var (
 	replacefile string
 	outfile     string
 	verbose     bool
+
+	bulkCheckpointDir string
+	bulkResume        bool
 )
 
 var bulkCmd = &cobra.Command{
@@ -33,28 +38,25 @@ func init() {
 	bulkCmd.Flags().StringVar(&replacefile, "replacefile", "", "location of replacement items file. Writes a CSV result of the items replaced instead of JSON")
 	bulkCmd.Flags().StringVar(&outfile, "output", "", "location of output file, defaults to stdout")
 	bulkCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	bulkCmd.Flags().StringVar(&bulkCheckpointDir, "checkpoint-dir", "", "directory to periodically write completed combos to, disabled if empty")
+	bulkCmd.Flags().BoolVar(&bulkResume, "resume", false, "continue from the checkpoint in --checkpoint-dir of an interrupted run of the same input. Only gives the same results as an uninterrupted run if the input has a fixed random seed")
 	bulkCmd.MarkFlagRequired("infile")
 	bulkCmd.MarkFlagRequired("replacefile")
 }
 
 func bulkSimMain(cmd *cobra.Command, args []string) {
-	data, err := os.ReadFile(infile)
-	if err != nil {
-		log.Fatalf("failed to load input json file %q: %v", infile, err)
-	}
-	input := &proto.RaidSimRequest{}
-
-	err = protojson.UnmarshalOptions{DiscardUnknown: true}.Unmarshal(data, input)
-	if err != nil {
-		log.Fatalf("failed to load input json file: %s", err)
+	input := loadRaidSimRequest(infile)
+	if bulkResume && bulkCheckpointDir == "" {
+		log.Fatalf("--resume needs a --checkpoint-dir to resume from")
 	}
+	core.EnableCheckpoints(bulkCheckpointDir, bulkResume)
 
 	output := BulkSim(input, replacefile, verbose)
 
 	if outfile == "" {
 		print(string(output))
 	} else {
-		err = os.WriteFile(outfile, []byte(output), 0666)
+		err := os.WriteFile(outfile, []byte(output), 0666)
 		if err != nil {
 			log.Fatalf("failed to write output file:: %s", err)
 		}
@@ -65,9 +67,17 @@ func bulkSimMain(cmd *cobra.Command, args []string) {
 }
 
 type ItemReplacementInput struct {
-	Combinations bool              `json:"combinations"`
-	FastMode     bool              `json:"fast_mode"`
-	Items        []*proto.ItemSpec // spec for replacement
+	Combinations  bool                `json:"combinations"`
+	FastMode      bool                `json:"fast_mode"`
+	Items         []*proto.ItemSpec   // spec for replacement
+	Runes         []int32             `json:"runes"` // IDs of runes to sim in their rune slots
+	RuneRotations []RuneRotationInput `json:"rune_rotations"`
+}
+
+// RuneRotationInput selects the APL to use for combinations with all of the runes.
+type RuneRotationInput struct {
+	Runes   []int32 `json:"runes"`
+	APLFile string  `json:"apl_file"` // APLRotation in protojson format
 }
 
 type ReplaceIter struct {
@@ -96,6 +106,19 @@ func BulkSim(input *proto.RaidSimRequest, replaceFile string, verbose bool) stri
 			FastMode:           replaceInput.FastMode,
 		},
 	}
+	if len(replaceInput.Runes) > 0 {
+		bsr.BulkSettings.RunesToSim = loadBulkRunes(replaceInput.Runes)
+	}
+	for _, runeRotation := range replaceInput.RuneRotations {
+		aplData, err := os.ReadFile(runeRotation.APLFile)
+		if err != nil {
+			log.Fatalf("failed to load apl file %q: %s", runeRotation.APLFile, err)
+		}
+		bsr.BulkSettings.RuneRotations = append(bsr.BulkSettings.RuneRotations, &proto.RuneRotation{
+			RuneIds:  runeRotation.Runes,
+			Rotation: core.APLRotationFromJsonString(string(aplData)),
+		})
+	}
 	progress := make(chan *proto.ProgressMetrics, 100)
 	core.RunBulkSimAsync(bsr, progress, "cmd-bulk-sim")
 
@@ -151,14 +174,36 @@ func BulkSim(input *proto.RaidSimRequest, replaceFile string, verbose bool) stri
 	}
 }
 
+// loadBulkRunes looks up the runes in the database, for their slot and restrictions.
+func loadBulkRunes(ids []int32) []*proto.BulkRune {
+	runesByID := make(map[int32]*proto.UIRune)
+	for _, r := range database.Load().Runes {
+		runesByID[r.Id] = r
+	}
+	runes := make([]*proto.BulkRune, 0, len(ids))
+	for _, id := range ids {
+		r, ok := runesByID[id]
+		if !ok {
+			log.Fatalf("unknown rune with id %d", id)
+		}
+		runes = append(runes, &proto.BulkRune{
+			Id:             r.Id,
+			Type:           r.Type,
+			RequiresLevel:  r.RequiresLevel,
+			ClassAllowlist: r.ClassAllowlist,
+		})
+	}
+	return runes
+}
+
 func printCombos(results *proto.BulkSimResult) string {
 	result := ""
 	foundBase := false
 	for i := 0; i < len(results.Results); i++ {
-		if len(results.Results[i].ItemsAdded) == 0 {
+		if isBaseCombo(results.Results[i], results.EquippedGearResult) {
 			foundBase = true
 		}
-		result += printCombo(results.Results[i])
+		result += printCombo(results.Results[i], results.EquippedGearResult)
 	}
 	if !foundBase {
 		result += fmt.Sprintf("[BASE RESULT],%0.1f\n", results.EquippedGearResult.UnitMetrics.Dps.Avg)
@@ -166,9 +211,15 @@ func printCombos(results *proto.BulkSimResult) string {
 	return result
 }
 
-func printCombo(combo *proto.BulkComboResult) string {
+func isBaseCombo(combo *proto.BulkComboResult, base *proto.BulkComboResult) bool {
+	return len(combo.ItemsAdded) == 0 && slices.EqualFunc(combo.Runes, base.Runes, func(a, b *proto.RuneWithSlot) bool {
+		return a.RuneId == b.RuneId && a.Slot == b.Slot
+	})
+}
+
+func printCombo(combo *proto.BulkComboResult, base *proto.BulkComboResult) string {
 	itemtext := "["
-	if len(combo.ItemsAdded) == 0 {
+	if isBaseCombo(combo, base) {
 		itemtext += "BASE RESULT"
 	}
 	for j, item := range combo.ItemsAdded {
@@ -178,5 +229,12 @@ func printCombo(combo *proto.BulkComboResult) string {
 		itemtext += fmt.Sprintf("%s@%s", core.ItemsByID[item.Item.Id].Name, item.Slot.String())
 	}
 	itemtext += "]"
+	if len(combo.Runes) > 0 {
+		runeIDs := make([]string, len(combo.Runes))
+		for i, r := range combo.Runes {
+			runeIDs[i] = fmt.Sprintf("%d@%s", r.RuneId, r.Slot.String())
+		}
+		itemtext += ",[" + strings.Join(runeIDs, ";") + "]"
+	}
 	return fmt.Sprintf("%s,%0.1f\n", itemtext, combo.UnitMetrics.Dps.Avg)
 }
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+var (
+	cooldownOptimizerStep      float64
+	cooldownOptimizerRounds    int32
+	cooldownOptimizerCooldowns []string
+	cooldownOptimizerAlign     []string
+	cooldownOptimizerFormat    string
+)
+
+var cooldownOptimizerCmd = &cobra.Command{
+	Use:   "cooldowns",
+	Short: "search for the best major cooldown timings",
+	Long: `search for the best major cooldown timings
+
+Tries delaying each major cooldown of the first player, holding it for the
+execute phase and aligning it with the other cooldowns, keeping whichever
+timings result in the highest DPS.
+Cooldowns are given as spell:<id>, item:<id> or other:<id>, e.g.
+  --align item:19950,spell:10060
+always uses a trinket and Power Infusion at the same time.`,
+	Run: cooldownOptimizerMain,
+}
+
+func init() {
+	cooldownOptimizerCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	cooldownOptimizerCmd.Flags().Float64Var(&cooldownOptimizerStep, "step", 5, "seconds between candidate timings of the first activation")
+	cooldownOptimizerCmd.Flags().Int32Var(&cooldownOptimizerRounds, "rounds", 3, "maximum number of passes over all cooldowns")
+	cooldownOptimizerCmd.Flags().StringArrayVar(&cooldownOptimizerCooldowns, "cooldown", nil, "cooldown to optimize, defaults to all (repeatable)")
+	cooldownOptimizerCmd.Flags().StringArrayVar(&cooldownOptimizerAlign, "align", nil, "comma separated cooldowns which are always used together (repeatable)")
+	cooldownOptimizerCmd.Flags().StringVar(&cooldownOptimizerFormat, "format", "table", "output format, 'table', 'csv' or 'json'")
+	cooldownOptimizerCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	cooldownOptimizerCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	cooldownOptimizerCmd.MarkFlagRequired("infile")
+}
+
+func cooldownOptimizerMain(cmd *cobra.Command, args []string) {
+	request := &proto.CooldownOptimizerRequest{
+		BaseSettings: loadRaidSimRequest(infile),
+		StepSeconds:  cooldownOptimizerStep,
+		MaxRounds:    cooldownOptimizerRounds,
+	}
+	for _, cooldown := range cooldownOptimizerCooldowns {
+		request.Cooldowns = append(request.Cooldowns, parseActionID(cooldown))
+	}
+	for _, group := range cooldownOptimizerAlign {
+		alignmentGroup := &proto.CooldownAlignmentGroup{}
+		for _, cooldown := range strings.Split(group, ",") {
+			alignmentGroup.Cooldowns = append(alignmentGroup.Cooldowns, parseActionID(cooldown))
+		}
+		request.AlignmentGroups = append(request.AlignmentGroups, alignmentGroup)
+	}
+
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunCooldownOptimizerAsync(request, reporter, "cmd-cooldown-optimizer")
+
+	var result *proto.CooldownOptimizerResult
+	for v := range reporter {
+		if v.FinalCooldownOptimizerResult != nil {
+			result = v.FinalCooldownOptimizerResult
+			break
+		}
+		if verbose && v.TotalSims > 0 {
+			fmt.Printf("Cooldown Optimizer Progress: %d / %d iterations (completed %d / %d sims)\n", v.CompletedIterations, v.TotalIterations, v.CompletedSims, v.TotalSims)
+		}
+	}
+	if result.Error != nil {
+		log.Fatalf("cooldown optimizer failed: %s", result.Error.Message)
+	}
+
+	writeOutput(formatResult(cooldownOptimizerFormat, cooldownOptimizerRows(result), result))
+}
+
+// parseActionID parses an action ID in the form spell:<id>, item:<id> or other:<id>.
+func parseActionID(str string) *proto.ActionID {
+	kind, value, _ := strings.Cut(strings.TrimSpace(str), ":")
+	id, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		log.Fatalf("invalid action id %q, expected spell:<id>, item:<id> or other:<id>", str)
+	}
+	switch kind {
+	case "spell":
+		return &proto.ActionID{RawId: &proto.ActionID_SpellId{SpellId: int32(id)}}
+	case "item":
+		return &proto.ActionID{RawId: &proto.ActionID_ItemId{ItemId: int32(id)}}
+	case "other":
+		return &proto.ActionID{RawId: &proto.ActionID_OtherId{OtherId: proto.OtherAction(id)}}
+	default:
+		log.Fatalf("invalid action id %q, expected spell:<id>, item:<id> or other:<id>", str)
+		return nil
+	}
+}
+
+// cooldownOptimizerRows returns the header, the DPS before and after, and one row per cooldown.
+func cooldownOptimizerRows(result *proto.CooldownOptimizerResult) [][]string {
+	rows := [][]string{{"cooldown", "cd", "strategy", "timings"}}
+	for _, timing := range result.Timings {
+		rows = append(rows, []string{
+			core.ProtoToActionID(timing.Id).String(),
+			fmt.Sprintf("%0.0fs", timing.CooldownSeconds),
+			timing.Strategy,
+			strings.Join(core.MapSlice(timing.Timings, func(t float64) string { return strconv.FormatFloat(t, 'f', -1, 64) }), " "),
+		})
+	}
+	rows = append(rows,
+		[]string{"[BASE DPS]", "", "", fmt.Sprintf("%0.1f", result.BaseDps)},
+		[]string{"[BEST DPS]", "", "", fmt.Sprintf("%0.1f", result.BestDps)},
+		[]string{"[DPS GAIN]", "", "", fmt.Sprintf("%0.2f +/- %0.2f", result.DpsGain, result.DpsGainStdev)},
+	)
+	return rows
+}
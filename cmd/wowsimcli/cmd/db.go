@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/tools"
+	"github.com/wowsims/classic/tools/database"
+)
+
+var dbDiffFormat string
+
+var dbCmd = &cobra.Command{
+	Use:   "db",
+	Short: "tools for the item database",
+}
+
+var dbDiffCmd = &cobra.Command{
+	Use:   "diff <old db.json> <new db.json>",
+	Short: "report the changes between two versions of the database",
+	Long: `report the changes between two versions of the database
+
+Lists the added and removed items, enchants, runes and random suffixes, and
+every changed field of the others, like stats, phases and sources, so a
+rebuild of assets/database can be reviewed like code. For example:
+
+  wowsimcli db diff <(git show master:assets/database/db.json) assets/database/db.json`,
+	Args: cobra.ExactArgs(2),
+	Run:  dbDiffMain,
+}
+
+func init() {
+	dbDiffCmd.Flags().StringVar(&dbDiffFormat, "format", "table", "output format, 'table', 'csv' or 'markdown'")
+	dbDiffCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	dbCmd.AddCommand(dbDiffCmd)
+}
+
+func dbDiffMain(cmd *cobra.Command, args []string) {
+	oldDB := database.ReadDatabaseFromJson(tools.ReadFile(args[0]))
+	newDB := database.ReadDatabaseFromJson(tools.ReadFile(args[1]))
+	changes := database.DiffDatabases(oldDB, newDB)
+
+	rows := [][]string{{"Kind", "ID", "Name", "Change", "Field", "Old", "New"}}
+	for _, change := range changes {
+		rows = append(rows, []string{change.Kind, change.ID, change.Name, string(change.Change), change.Field, change.Old, change.New})
+	}
+
+	if len(changes) == 0 && dbDiffFormat != "csv" {
+		writeOutput("No changes.\n")
+		return
+	}
+	switch dbDiffFormat {
+	case "table", "csv":
+		writeOutput(formatResult(dbDiffFormat, rows, nil))
+	case "markdown":
+		writeOutput(formatMarkdownTable(rows))
+	default:
+		log.Fatalf("unknown output format %q", dbDiffFormat)
+	}
+}
+
+func formatMarkdownTable(rows [][]string) string {
+	sb := &strings.Builder{}
+	for i, row := range rows {
+		cells := make([]string, len(row))
+		for j, cell := range row {
+			cells[j] = strings.ReplaceAll(cell, "|", "\\|")
+		}
+		fmt.Fprintf(sb, "| %s |\n", strings.Join(cells, " | "))
+		if i == 0 {
+			fmt.Fprintf(sb, "|%s\n", strings.Repeat(" --- |", len(row)))
+		}
+	}
+	return sb.String()
+}
@@ -1,19 +1,17 @@
 package cmd
 
 import (
-	"bytes"
-	"compress/zlib"
-	"encoding/base64"
-	"errors"
 	"fmt"
-	"strings"
 
 	"github.com/spf13/cobra"
 	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/importer"
 	"google.golang.org/protobuf/encoding/protojson"
 	goproto "google.golang.org/protobuf/proto"
 )
 
+var decodeLinkAsRequest bool
+
 var decodeLinkCmd = &cobra.Command{
 	Use:   "decodelink [link]",
 	Short: "decode wowsims link/url",
@@ -24,42 +22,24 @@ var decodeLinkCmd = &cobra.Command{
 	},
 }
 
-var errInvalidLink = errors.New("invalid wowsims export link")
+func init() {
+	decodeLinkCmd.Flags().BoolVar(&decodeLinkAsRequest, "request", false, "output a RaidSimRequest which can be used as input of the other commands")
+}
 
 func decodeLink(link string) error {
-	parts := strings.Split(link, "#")
-	switch {
-	case len(parts) != 2:
-		return errInvalidLink
-	case parts[1] == "":
-		return errInvalidLink
-	}
-
-	raw, err := base64.StdEncoding.DecodeString(parts[1])
+	settings, err := importer.DecodeSettingsLink(link)
 	if err != nil {
-		return fmt.Errorf("cannot decode proto from link: %w", err)
-	}
-
-	r, err := zlib.NewReader(bytes.NewReader(raw))
-	if err != nil {
-		return fmt.Errorf("cannot create zlib reader: %w", err)
-	}
-	defer r.Close()
-
-	var buf bytes.Buffer
-	if _, err := buf.ReadFrom(r); err != nil {
-		return fmt.Errorf("reading zlib data failed: %w", err)
-	}
-
-	var settings goproto.Message
-	if strings.Contains(link, "/raid/") {
-		settings = &proto.RaidSimSettings{}
-	} else {
-		settings = &proto.IndividualSimSettings{}
+		return err
 	}
 
-	if err := goproto.Unmarshal(buf.Bytes(), settings); err != nil {
-		return fmt.Errorf("cannot unmarshal raw proto: %w", err)
+	if decodeLinkAsRequest {
+		switch settings := settings.(type) {
+		case *proto.IndividualSimSettings:
+			fmt.Println(protojson.Format(importer.IndividualSettingsToRequest(settings)))
+		case *proto.RaidSimSettings:
+			fmt.Println(protojson.Format(importer.RaidSettingsToRequest(settings)))
+		}
+		return nil
 	}
 
 	fmt.Println(protojson.Format(goproto.Message(settings)))
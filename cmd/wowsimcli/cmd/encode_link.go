@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/importer"
+)
+
+var encodeLinkCmd = &cobra.Command{
+	Use:   "encodelink",
+	Short: "encode the first player of a RaidSimRequest as wowsims link/url",
+	Long:  "encode the first player of a RaidSimRequest as wowsims link/url",
+	RunE: func(cmd *cobra.Command, args []string) error {
+		link, err := importer.EncodeSettingsLink(loadRaidSimRequest(infile))
+		if err != nil {
+			return err
+		}
+		fmt.Println(link)
+		return nil
+	},
+}
+
+func init() {
+	encodeLinkCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	encodeLinkCmd.MarkFlagRequired("infile")
+}
@@ -0,0 +1,77 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/assets/database"
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/importer"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+var importOptions importer.RequestOptions
+
+var importCmd = &cobra.Command{
+	Use:   "import [addon export file|gear planner link|wowsims link]",
+	Short: "create a RaidSimRequest from an external character format",
+	Long: `create a RaidSimRequest from an external character format
+
+Accepts the JSON export of the WowSimsExporter addon, a wowhead classic gear
+planner link or a wowsims share link. Characters from the addon and gear
+planner get the default APL of their spec, full buffs and debuffs for the
+phase of their level and a single target encounter.
+Gear planner links do not contain runes, so those have to be added afterwards.`,
+	Args: cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		request, err := importCharacter(args[0])
+		if err != nil {
+			return err
+		}
+		writeOutput(protojson.Format(request) + "\n")
+		return nil
+	},
+}
+
+func init() {
+	importCmd.Flags().StringVar(&importOptions.Spec, "spec", "", "spec of the character, e.g. 'warrior' or 'balance_druid', defaults to the main dps spec of the class")
+	importCmd.Flags().StringVar(&importOptions.UIDir, "ui-dir", "ui", "directory of the sim UIs, used to find the default APL of the spec")
+	importCmd.Flags().StringVar(&importOptions.APLFile, "apl", "", "APL file to use instead of the default APL")
+	importCmd.Flags().Float64Var(&importOptions.Duration, "duration", 0, "fight length in seconds")
+	importCmd.Flags().Int32Var(&importOptions.Iterations, "iterations", importer.DefaultIterations, "number of iterations")
+	importCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	importCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+}
+
+func importCharacter(source string) (*proto.RaidSimRequest, error) {
+	if strings.Contains(source, "#") && strings.HasPrefix(source, "http") {
+		settings, err := importer.DecodeSettingsLink(source)
+		if err != nil {
+			return nil, err
+		}
+		switch settings := settings.(type) {
+		case *proto.IndividualSimSettings:
+			return importer.IndividualSettingsToRequest(settings), nil
+		case *proto.RaidSimSettings:
+			return importer.RaidSettingsToRequest(settings), nil
+		}
+	}
+
+	var player *proto.Player
+	var err error
+	if strings.Contains(source, "gear-planner") {
+		player, err = importer.ParseGearPlannerLink(source, importer.NewEnchantSpellLookup(database.Load()))
+	} else {
+		data, readErr := os.ReadFile(source)
+		if readErr != nil {
+			return nil, fmt.Errorf("cannot read addon export: %w", readErr)
+		}
+		player, err = importer.ParseAddonExport(data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return importer.NewRaidSimRequest(player, importOptions)
+}
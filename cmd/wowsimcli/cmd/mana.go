@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/assets/database"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+var (
+	manaBinSeconds float64
+	manaFormat     string
+)
+
+var manaCmd = &cobra.Command{
+	Use:   "mana",
+	Short: "report mana spent per action, regen by source and the mana curve",
+	Long: `report mana spent per action, regen by source and the mana curve
+
+Sims the first player and lists the mana spent and damage per mana of each
+action, the mana gained from each source of regen, and the average mana at
+the start of each time bin. If the player goes OOM, the report suggests how
+many casts of the least efficient spender to drop, and before when.`,
+	Run: manaMain,
+}
+
+func init() {
+	manaCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	manaCmd.Flags().Float64Var(&manaBinSeconds, "bin", 10, "width of the bins of the mana curve, in seconds")
+	manaCmd.Flags().StringVar(&manaFormat, "format", "table", "output format, 'table', 'csv' or 'json'")
+	manaCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	manaCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	manaCmd.MarkFlagRequired("infile")
+}
+
+func manaMain(cmd *cobra.Command, args []string) {
+	request := loadRaidSimRequest(infile)
+	if request.SimOptions == nil {
+		request.SimOptions = &proto.SimOptions{}
+	}
+	request.SimOptions.TimeSeriesBinSeconds = manaBinSeconds
+
+	reporter := make(chan *proto.ProgressMetrics, 10)
+	core.RunRaidSimConcurrentAsync(request, reporter, "cmd-mana")
+
+	var result *proto.RaidSimResult
+	for v := range reporter {
+		if v.FinalRaidResult != nil {
+			result = v.FinalRaidResult
+			break
+		}
+		if verbose {
+			fmt.Printf("Sim Progress: %d / %d\n", v.CompletedIterations, v.TotalIterations)
+		}
+	}
+	if result.Error != nil {
+		log.Fatalf("sim failed: %s", result.Error.Message)
+	}
+
+	player := core.FirstPlayerMetrics(result)
+	if player == nil || player.Mana == nil {
+		log.Fatalf("the first player has no mana")
+	}
+
+	writeOutput(formatResult(manaFormat, manaRows(player, newActionNameLookup(database.Load())), player.Mana))
+}
+
+// manaRows returns tables of the spenders, the sources and the mana curve, followed by the
+// suggested filler casts to drop, if any.
+func manaRows(player *proto.UnitMetrics, actionName func(*proto.ActionID) string) [][]string {
+	mana := player.Mana
+	rows := [][]string{{"action", "casts", "mana_spent", "damage", "damage_per_mana"}}
+	for _, action := range mana.Actions {
+		rows = append(rows, []string{
+			actionName(action.Id),
+			fmt.Sprintf("%0.1f", action.CastsAvg),
+			fmt.Sprintf("%0.0f", action.ManaSpentAvg),
+			fmt.Sprintf("%0.0f", action.DamageAvg),
+			fmt.Sprintf("%0.2f", action.DamagePerMana),
+		})
+	}
+	rows = append(rows, []string{"[TOTAL]", "", fmt.Sprintf("%0.0f", mana.SpentAvg), "", ""})
+
+	rows = append(rows, []string{}, []string{"source", "type", "mana_gained", "overflow"})
+	for _, source := range mana.Sources {
+		rows = append(rows, []string{
+			actionName(source.Id),
+			strings.TrimPrefix(source.Source.String(), "Source"),
+			fmt.Sprintf("%0.0f", source.GainAvg),
+			fmt.Sprintf("%0.0f", source.OverflowAvg),
+		})
+	}
+	rows = append(rows, []string{"[TOTAL]", "", fmt.Sprintf("%0.0f", mana.GainedAvg), ""})
+
+	if timeSeries := player.TimeSeries; timeSeries != nil {
+		for _, resource := range timeSeries.Resources {
+			if resource.Type != proto.ResourceType_ResourceTypeMana {
+				continue
+			}
+			rows = append(rows, []string{}, []string{"time", "mana"})
+			for i, level := range resource.Levels {
+				rows = append(rows, []string{
+					fmt.Sprintf("%0.0fs", float64(i)*timeSeries.BinSeconds),
+					fmt.Sprintf("%0.0f", level),
+				})
+			}
+		}
+	}
+
+	rows = append(rows, []string{}, []string{"[OOM]", fmt.Sprintf("%0.1fs per iteration", player.SecondsOomAvg)})
+	if suggestion := mana.FillerSuggestion; suggestion != nil {
+		rows = append(rows, []string{
+			"[SUGGESTION]",
+			fmt.Sprintf("drop %0.0f casts of %s before %0.0fs", suggestion.DropCastsAvg, actionName(suggestion.Id), suggestion.BeforeSeconds),
+			fmt.Sprintf("saves %0.0f mana for %0.0f damage", suggestion.ManaSavedAvg, suggestion.DamageLostAvg),
+		})
+	}
+	return rows
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"google.golang.org/protobuf/encoding/protojson"
+	goproto "google.golang.org/protobuf/proto"
+)
+
+var migrateDryRun bool
+
+var migrateCmd = &cobra.Command{
+	Use:   "migrate [files...]",
+	Short: "upgrade saved settings to the current settings version",
+	Long: `upgrade saved settings to the current settings version
+
+Rewrites each file (RaidSimRequest or IndividualSimSettings in protojson
+format) in place, carrying over settings which were renamed or replaced and
+warning about settings which could not be carried over. Files which are
+already at the current version are left as they are.`,
+	Args: cobra.MinimumNArgs(1),
+	Run:  migrateMain,
+}
+
+func init() {
+	migrateCmd.Flags().BoolVar(&migrateDryRun, "dry-run", false, "only print the warnings, without rewriting the files")
+}
+
+func migrateMain(cmd *cobra.Command, args []string) {
+	for _, path := range args {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Fatalf("failed to read %q: %v", path, err)
+		}
+
+		var header struct {
+			APIVersion          int32           `json:"apiVersion"`
+			APIVersionProtoName int32           `json:"api_version"`
+			Player              json.RawMessage `json:"player"`
+		}
+		if err := json.Unmarshal(data, &header); err != nil {
+			log.Fatalf("failed to parse %q: %v", path, err)
+		}
+		if max(header.APIVersion, header.APIVersionProtoName) == core.CurrentAPIVersion {
+			fmt.Printf("%s: already at version %d\n", path, core.CurrentAPIVersion)
+			continue
+		}
+
+		var migrated goproto.Message
+		var warnings []string
+		// Individual sim settings have a single player, sim requests have a raid.
+		if header.Player != nil {
+			migrated, warnings, err = core.MigrateIndividualSimSettings(data)
+		} else {
+			migrated, warnings, err = core.MigrateRaidSimRequest(data)
+		}
+		if err != nil {
+			log.Fatalf("failed to migrate %q: %v", path, err)
+		}
+		for _, warning := range warnings {
+			fmt.Printf("%s: warning: %s\n", path, warning)
+		}
+		if migrateDryRun {
+			continue
+		}
+
+		output, err := protojson.MarshalOptions{Multiline: true, Indent: "  "}.Marshal(migrated)
+		if err != nil {
+			log.Fatalf("failed to marshal %q: %v", path, err)
+		}
+		if err := os.WriteFile(path, append(output, '\n'), 0666); err != nil {
+			log.Fatalf("failed to write %q: %v", path, err)
+		}
+		fmt.Printf("%s: migrated to version %d\n", path, core.CurrentAPIVersion)
+	}
+}
@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+	goproto "google.golang.org/protobuf/proto"
+)
+
+// formatResult renders the rows as an aligned table or CSV, or the full message as protojson.
+func formatResult(format string, rows [][]string, msg goproto.Message) string {
+	switch format {
+	case "table":
+		sb := &strings.Builder{}
+		w := tabwriter.NewWriter(sb, 0, 0, 2, ' ', tabwriter.AlignRight)
+		for _, row := range rows {
+			fmt.Fprintln(w, strings.Join(row, "\t")+"\t")
+		}
+		w.Flush()
+		return sb.String()
+	case "csv":
+		sb := &strings.Builder{}
+		w := csv.NewWriter(sb)
+		w.WriteAll(rows)
+		return sb.String()
+	case "json":
+		out, err := protojson.MarshalOptions{EmitUnpopulated: true}.Marshal(msg)
+		if err != nil {
+			log.Fatalf("failed to marshal results: %s", err)
+		}
+		return string(out)
+	default:
+		log.Fatalf("unknown output format %q", format)
+		return ""
+	}
+}
+
+// writeOutput writes the output to outfile, or stdout if no outfile is set.
+func writeOutput(output string) {
+	if outfile == "" {
+		fmt.Print(output)
+		return
+	}
+	if err := os.WriteFile(outfile, []byte(output), 0666); err != nil {
+		log.Fatalf("failed to write output file:: %s", err)
+	}
+	if verbose {
+		fmt.Printf("Wrote output file: `%s` successfully.\n", outfile)
+	}
+}
+
+// loadRaidSimRequest reads a RaidSimRequest in protojson format, migrating it from older versions.
+func loadRaidSimRequest(path string) *proto.RaidSimRequest {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		log.Fatalf("failed to load input json file %q: %v", path, err)
+	}
+	input, warnings, err := core.MigrateRaidSimRequest(data)
+	if err != nil {
+		log.Fatalf("failed to load input json file: %s", err)
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "warning: %s: %s\n", path, warning)
+	}
+	return input
+}
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"math"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/encoding/prototext"
+)
+
+var (
+	regressExpectedFile string
+	regressUpdate       bool
+	regressSeed         int64
+	regressTolerance    float64
+	regressAbsTolerance float64
+	regressFormat       string
+)
+
+var regressCmd = &cobra.Command{
+	Use:   "regress [dir]",
+	Short: "compare saved sim configurations against their expected results",
+	Long: `compare saved sim configurations against their expected results
+
+Runs every *.json file (RaidSimRequest in protojson format) in the directory
+and its subdirectories with a fixed seed, and prints the metrics which differ
+from the expected results. Requests without a random seed use --seed.
+The expected results are stored in the same format as the sim's own .results
+files; run with --update to accept the current results.
+Exits with status 1 if any metric changed.`,
+	Args: cobra.ExactArgs(1),
+	Run:  regressMain,
+}
+
+func init() {
+	regressCmd.Flags().StringVar(&regressExpectedFile, "expected", "", "location of the expected results, defaults to expected.results in the directory")
+	regressCmd.Flags().BoolVar(&regressUpdate, "update", false, "write the current results as the expected results")
+	regressCmd.Flags().Int64Var(&regressSeed, "seed", 1, "random seed for requests which do not set one")
+	regressCmd.Flags().Float64Var(&regressTolerance, "tolerance", 0.0001, "allowed relative difference of a metric")
+	regressCmd.Flags().Float64Var(&regressAbsTolerance, "abs-tolerance", 0.001, "allowed absolute difference of a metric")
+	regressCmd.Flags().StringVar(&regressFormat, "format", "table", "output format, 'table', 'csv' or 'json'")
+	regressCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	regressCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+}
+
+type regressSetup struct {
+	name    string
+	request *proto.RaidSimRequest
+}
+
+type regressDiff struct {
+	setup    string
+	metric   string
+	expected string
+	actual   string
+	change   string
+}
+
+func regressMain(cmd *cobra.Command, args []string) {
+	dir := args[0]
+	if regressExpectedFile == "" {
+		regressExpectedFile = filepath.Join(dir, "expected.results")
+	}
+
+	setups := loadRegressSetups(dir)
+	if len(setups) == 0 {
+		log.Fatalf("no sim configurations found in %s", dir)
+	}
+	actual := runRegressSetups(setups)
+
+	if regressUpdate {
+		str := prototext.Format(actual)
+		// Same as the sim's own results files, for consistent output.
+		str = strings.ReplaceAll(str, "  ", " ")
+		if err := os.WriteFile(regressExpectedFile, []byte(str), 0644); err != nil {
+			log.Fatalf("failed to write expected results: %s", err)
+		}
+		fmt.Printf("Updated expected results of %d setups in %s\n", len(setups), regressExpectedFile)
+		return
+	}
+
+	expected := &proto.TestSuiteResult{}
+	data, err := os.ReadFile(regressExpectedFile)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Fatalf("failed to read expected results: %s", err)
+	}
+	if err := prototext.Unmarshal(data, expected); err != nil {
+		log.Fatalf("failed to parse expected results: %s", err)
+	}
+
+	diffs := compareRegressResults(expected, actual, regressTolerance, regressAbsTolerance)
+	rows := [][]string{{"setup", "metric", "expected", "actual", "change"}}
+	for _, diff := range diffs {
+		rows = append(rows, []string{diff.setup, diff.metric, diff.expected, diff.actual, diff.change})
+	}
+	writeOutput(formatResult(regressFormat, rows, actual))
+	fmt.Fprintf(os.Stderr, "%d setups, %d changed metrics\n", len(setups), len(diffs))
+	if len(diffs) > 0 {
+		os.Exit(1)
+	}
+}
+
+// loadRegressSetups loads all requests in the directory, named by their path without extension.
+func loadRegressSetups(dir string) []regressSetup {
+	var setups []regressSetup
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || filepath.Ext(path) != ".json" {
+			return err
+		}
+		name, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		setups = append(setups, regressSetup{
+			name:    filepath.ToSlash(strings.TrimSuffix(name, ".json")),
+			request: loadRaidSimRequest(path),
+		})
+		return nil
+	})
+	if err != nil {
+		log.Fatalf("failed to read sim configurations: %s", err)
+	}
+	return setups
+}
+
+// runRegressSetups runs the setups in parallel. Each setup runs single threaded, so the results
+// do not depend on the number of CPUs.
+func runRegressSetups(setups []regressSetup) *proto.TestSuiteResult {
+	results := make([]*proto.RaidSimResult, len(setups))
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, runtime.NumCPU())
+	for i, setup := range setups {
+		if setup.request.SimOptions == nil {
+			setup.request.SimOptions = &proto.SimOptions{}
+		}
+		if setup.request.SimOptions.RandomSeed == 0 {
+			setup.request.SimOptions.RandomSeed = regressSeed
+		}
+		wg.Add(1)
+		go func(i int, setup regressSetup) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = core.RunRaidSim(setup.request)
+			if verbose {
+				fmt.Printf("Finished %s\n", setup.name)
+			}
+		}(i, setup)
+	}
+	wg.Wait()
+
+	suite := &proto.TestSuiteResult{
+		DpsResults:   make(map[string]*proto.DpsTestResult),
+		CastsResults: make(map[string]*proto.CastsTestResult),
+	}
+	for i, setup := range setups {
+		if results[i].Error != nil {
+			log.Fatalf("sim of %s failed: %s", setup.name, results[i].Error.Message)
+		}
+		addRegressResult(suite, setup.name, results[i])
+	}
+	return suite
+}
+
+// addRegressResult stores the raid metrics under the setup name, and the metrics and casts per
+// iteration of each player under '<setup>-<player>'.
+func addRegressResult(suite *proto.TestSuiteResult, name string, result *proto.RaidSimResult) {
+	suite.DpsResults[name] = &proto.DpsTestResult{
+		Dps: regressRound(result.RaidMetrics.Dps.Avg),
+		Hps: regressRound(result.RaidMetrics.Hps.Avg),
+	}
+
+	iterations := float64(max(result.IterationsDone, 1))
+	for partyIdx, party := range result.RaidMetrics.Parties {
+		for playerIdx, player := range party.Players {
+			if player.Name == "" {
+				continue
+			}
+			key := name + "-" + player.Name
+			if _, ok := suite.DpsResults[key]; ok {
+				key = fmt.Sprintf("%s-%d", key, partyIdx*5+playerIdx)
+			}
+			suite.DpsResults[key] = &proto.DpsTestResult{
+				Dps:  regressRound(player.Dps.Avg),
+				Tps:  regressRound(player.Threat.Avg),
+				Dtps: regressRound(player.Dtps.Avg),
+				Hps:  regressRound(player.Hps.Avg),
+				Tmi:  regressRound(player.Tmi.Avg),
+			}
+
+			casts := make(map[string]float64, len(player.Actions))
+			for _, action := range player.Actions {
+				name := core.ProtoToActionID(action.Id).String()
+				for _, target := range action.Targets {
+					casts[name] += float64(target.Casts)
+				}
+				casts[name] = regressRound(casts[name] / iterations)
+			}
+			suite.CastsResults[key] = &proto.CastsTestResult{Casts: casts}
+		}
+	}
+}
+
+func regressRound(value float64) float64 {
+	return math.Round(value*1000) / 1000
+}
+
+// compareRegressResults returns the metrics which differ by more than both tolerances, sorted by setup.
+func compareRegressResults(expected, actual *proto.TestSuiteResult, tolerance, absTolerance float64) []regressDiff {
+	var diffs []regressDiff
+	addDiff := func(setup, metric string, expectedValue, actualValue float64, hasExpected, hasActual bool) {
+		switch {
+		case !hasExpected:
+			diffs = append(diffs, regressDiff{setup, metric, "-", fmt.Sprintf("%0.3f", actualValue), "new"})
+		case !hasActual:
+			diffs = append(diffs, regressDiff{setup, metric, fmt.Sprintf("%0.3f", expectedValue), "-", "removed"})
+		default:
+			delta := actualValue - expectedValue
+			if math.Abs(delta) <= absTolerance || math.Abs(delta) <= tolerance*math.Abs(expectedValue) {
+				return
+			}
+			change := fmt.Sprintf("%+0.3f", delta)
+			if expectedValue != 0 {
+				change += fmt.Sprintf(" (%+0.2f%%)", delta/expectedValue*100)
+			}
+			diffs = append(diffs, regressDiff{setup, metric, fmt.Sprintf("%0.3f", expectedValue), fmt.Sprintf("%0.3f", actualValue), change})
+		}
+	}
+
+	dpsMetrics := func(result *proto.DpsTestResult) map[string]float64 {
+		if result == nil {
+			return nil
+		}
+		return map[string]float64{"dps": result.Dps, "tps": result.Tps, "dtps": result.Dtps, "hps": result.Hps, "tmi": result.Tmi}
+	}
+	for _, setup := range unionKeys(expected.DpsResults, actual.DpsResults) {
+		expectedMetrics, actualMetrics := dpsMetrics(expected.DpsResults[setup]), dpsMetrics(actual.DpsResults[setup])
+		for _, metric := range []string{"dps", "tps", "dtps", "hps", "tmi"} {
+			expectedValue, hasExpected := expectedMetrics[metric]
+			actualValue, hasActual := actualMetrics[metric]
+			if expectedValue == 0 && actualValue == 0 {
+				continue
+			}
+			addDiff(setup, metric, expectedValue, actualValue, hasExpected, hasActual)
+		}
+	}
+	for _, setup := range unionKeys(expected.CastsResults, actual.CastsResults) {
+		expectedCasts, actualCasts := expected.CastsResults[setup].GetCasts(), actual.CastsResults[setup].GetCasts()
+		for _, action := range unionKeys(expectedCasts, actualCasts) {
+			expectedValue, hasExpected := expectedCasts[action]
+			actualValue, hasActual := actualCasts[action]
+			addDiff(setup, "casts "+action, expectedValue, actualValue, hasExpected, hasActual)
+		}
+	}
+
+	slices.SortStableFunc(diffs, func(a, b regressDiff) int { return strings.Compare(a.setup, b.setup) })
+	return diffs
+}
+
+func unionKeys[V any](a, b map[string]V) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
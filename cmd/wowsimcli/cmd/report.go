@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	_ "embed"
+	"fmt"
+	"html/template"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/assets/database"
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+//go:embed report.html.tmpl
+var reportTemplateSource string
+
+var reportTitle string
+
+var reportCmd = &cobra.Command{
+	Use:   "report",
+	Short: "render a RaidSimResult as a self-contained HTML report",
+	Long: `render a RaidSimResult as a self-contained HTML report
+
+The report contains the DPS distribution, damage per action, aura uptimes
+and resource gains of every player, with their pets listed separately.
+It does not load anything from the network, so it can be shared as a file.`,
+	Run: reportMain,
+}
+
+func init() {
+	reportCmd.Flags().StringVar(&infile, "infile", "result.json", "location of input file (RaidSimResult in protojson format, e.g. the output of 'sim')")
+	reportCmd.Flags().StringVar(&reportTitle, "title", "Sim Report", "title of the report")
+	reportCmd.Flags().StringVar(&outfile, "outfile", "report.html", "location of output file")
+	reportCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	reportCmd.MarkFlagRequired("infile")
+}
+
+func reportMain(cmd *cobra.Command, args []string) {
+	data, err := os.ReadFile(infile)
+	if err != nil {
+		log.Fatalf("failed to load input json file %q: %v", infile, err)
+	}
+	result := &proto.RaidSimResult{}
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(data, result); err != nil {
+		log.Fatalf("failed to load input json file: %s", err)
+	}
+	tmpl := template.Must(template.New("report").Funcs(template.FuncMap{
+		"f1":  func(v float64) string { return strconv.FormatFloat(v, 'f', 1, 64) },
+		"f2":  func(v float64) string { return strconv.FormatFloat(v, 'f', 2, 64) },
+		"pct": func(v float64) string { return strconv.FormatFloat(v*100, 'f', 1, 64) + "%" },
+	}).Parse(reportTemplateSource))
+
+	report, err := newReportData(result, reportTitle, newActionNameLookup(database.Load()))
+	if err != nil {
+		log.Fatalf("failed to create report: %s", err)
+	}
+	sb := &strings.Builder{}
+	if err := tmpl.Execute(sb, report); err != nil {
+		log.Fatalf("failed to render report: %s", err)
+	}
+	writeOutput(sb.String())
+}
+
+type reportData struct {
+	Title      string
+	Generated  string
+	Iterations int32
+	Duration   float64
+	RaidDps    *proto.DistributionMetrics
+	RaidChart  template.HTML
+	Players    []*reportUnit
+	Targets    []*reportUnit
+}
+
+type reportUnit struct {
+	Name      string
+	IsPet     bool
+	Dps       *proto.DistributionMetrics
+	Hps       *proto.DistributionMetrics
+	Histogram template.HTML
+	Actions   []reportAction
+	Auras     []reportAura
+	Resources []reportResource
+	Pets      []*reportUnit
+}
+
+type reportAction struct {
+	Name    string
+	Casts   float64
+	Damage  float64
+	Dps     float64
+	Share   float64
+	AvgHit  float64
+	CritPct float64
+	MissPct float64
+}
+
+type reportAura struct {
+	Name   string
+	Uptime float64
+	Procs  float64
+}
+
+type reportResource struct {
+	Name   string
+	Type   string
+	Events float64
+	Gain   float64
+	PerSec float64
+	Wasted float64
+}
+
+func newReportData(result *proto.RaidSimResult, title string, actionName func(*proto.ActionID) string) (*reportData, error) {
+	if result.Error != nil {
+		return nil, fmt.Errorf("result has an error: %s", result.Error.Message)
+	}
+	if result.RaidMetrics == nil {
+		return nil, fmt.Errorf("result has no raid metrics")
+	}
+
+	iterations := float64(max(result.IterationsDone, 1))
+	duration := max(result.AvgIterationDuration, 1)
+	report := &reportData{
+		Title:      title,
+		Generated:  time.Now().Format(time.RFC1123),
+		Iterations: result.IterationsDone,
+		Duration:   result.AvgIterationDuration,
+		RaidDps:    result.RaidMetrics.Dps,
+	}
+
+	var newUnit func(metrics *proto.UnitMetrics) *reportUnit
+	newUnit = func(metrics *proto.UnitMetrics) *reportUnit {
+		unit := &reportUnit{
+			Name:      metrics.Name,
+			Dps:       metrics.Dps,
+			Hps:       metrics.Hps,
+			Histogram: histogramSVG(metrics.Dps),
+		}
+
+		totalDamage := 0.0
+		for _, action := range metrics.Actions {
+			var casts, hits, crits, misses int32
+			var damage, healing float64
+			for _, target := range action.Targets {
+				casts += target.Casts
+				hits += target.Hits + target.Ticks
+				crits += target.Crits + target.CritTicks
+				misses += target.Misses + target.Dodges + target.Parries
+				damage += target.Damage
+				healing += target.Healing
+			}
+			if casts == 0 && hits == 0 && damage == 0 && healing == 0 {
+				continue
+			}
+			totalDamage += damage
+			unit.Actions = append(unit.Actions, reportAction{
+				Name:    actionName(action.Id),
+				Casts:   float64(casts) / iterations,
+				Damage:  damage / iterations,
+				Dps:     damage / iterations / duration,
+				AvgHit:  damage / float64(max(hits, 1)),
+				CritPct: float64(crits) / float64(max(hits, 1)),
+				MissPct: float64(misses) / float64(max(hits+misses, 1)),
+			})
+		}
+		for i := range unit.Actions {
+			unit.Actions[i].Share = unit.Actions[i].Damage / max(totalDamage/iterations, 1)
+		}
+		slices.SortStableFunc(unit.Actions, func(a, b reportAction) int { return compareDesc(a.Damage, b.Damage) })
+
+		for _, aura := range metrics.Auras {
+			unit.Auras = append(unit.Auras, reportAura{
+				Name:   actionName(aura.Id),
+				Uptime: min(aura.UptimeSecondsAvg/duration, 1),
+				Procs:  aura.ProcsAvg,
+			})
+		}
+		slices.SortStableFunc(unit.Auras, func(a, b reportAura) int { return compareDesc(a.Uptime, b.Uptime) })
+
+		for _, resource := range metrics.Resources {
+			unit.Resources = append(unit.Resources, reportResource{
+				Name:   actionName(resource.Id),
+				Type:   strings.TrimPrefix(resource.Type.String(), "ResourceType"),
+				Events: float64(resource.Events) / iterations,
+				Gain:   resource.Gain / iterations,
+				PerSec: resource.Gain / iterations / duration,
+				Wasted: (resource.Gain - resource.ActualGain) / iterations,
+			})
+		}
+		slices.SortStableFunc(unit.Resources, func(a, b reportResource) int {
+			if c := strings.Compare(a.Type, b.Type); c != 0 {
+				return c
+			}
+			return compareDesc(a.Gain, b.Gain)
+		})
+
+		for _, pet := range metrics.Pets {
+			if pet.Dps.GetAvg() > 0 || len(pet.Actions) > 0 {
+				petUnit := newUnit(pet)
+				petUnit.IsPet = true
+				unit.Pets = append(unit.Pets, petUnit)
+			}
+		}
+		return unit
+	}
+
+	var bars []svgBar
+	for _, party := range result.RaidMetrics.Parties {
+		for _, player := range party.Players {
+			if player.Name == "" {
+				continue
+			}
+			report.Players = append(report.Players, newUnit(player))
+			bars = append(bars, svgBar{Label: player.Name, Value: player.Dps.GetAvg()})
+		}
+	}
+	if len(report.Players) > 1 {
+		report.RaidChart = barChartSVG(bars)
+	}
+	for _, target := range result.EncounterMetrics.GetTargets() {
+		// Targets which did nothing are only noise in the report.
+		if len(target.Actions) > 0 || len(target.Auras) > 0 {
+			report.Targets = append(report.Targets, newUnit(target))
+		}
+	}
+	return report, nil
+}
+
+func compareDesc(a, b float64) int {
+	switch {
+	case a > b:
+		return -1
+	case a < b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// Returns a function for the display name of actions, using the names in the database if available.
+func newActionNameLookup(db *proto.UIDatabase) func(*proto.ActionID) string {
+	spellNames := make(map[int32]string, len(db.SpellIcons))
+	for _, spell := range db.SpellIcons {
+		spellNames[spell.Id] = spell.Name
+	}
+	itemNames := make(map[int32]string, len(db.ItemIcons)+len(db.Items))
+	for _, item := range db.ItemIcons {
+		itemNames[item.Id] = item.Name
+	}
+	for _, item := range db.Items {
+		itemNames[item.Id] = item.Name
+	}
+
+	return func(id *proto.ActionID) string {
+		var name string
+		switch rawID := id.RawId.(type) {
+		case *proto.ActionID_SpellId:
+			if name = spellNames[rawID.SpellId]; name == "" {
+				name = fmt.Sprintf("Spell %d", rawID.SpellId)
+			}
+		case *proto.ActionID_ItemId:
+			if name = itemNames[rawID.ItemId]; name == "" {
+				name = fmt.Sprintf("Item %d", rawID.ItemId)
+			}
+		case *proto.ActionID_OtherId:
+			name = strings.TrimPrefix(rawID.OtherId.String(), "OtherAction")
+		default:
+			name = "Unknown"
+		}
+		if id.Tag != 0 {
+			name += fmt.Sprintf(" (%d)", id.Tag)
+		}
+		return name
+	}
+}
+
+type svgBar struct {
+	Label string
+	Value float64
+}
+
+// Renders the DPS histogram as an inline SVG.
+func histogramSVG(dist *proto.DistributionMetrics) template.HTML {
+	if len(dist.GetHist()) == 0 {
+		return ""
+	}
+	keys := make([]int32, 0, len(dist.Hist))
+	maxCount := int32(1)
+	for key, count := range dist.Hist {
+		keys = append(keys, key)
+		maxCount = max(maxCount, count)
+	}
+	slices.Sort(keys)
+
+	const width, height, labelHeight = 600.0, 160.0, 16.0
+	barWidth := width / float64(len(keys))
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, `<svg class="chart" viewBox="0 0 %g %g" xmlns="http://www.w3.org/2000/svg">`, width, height+labelHeight)
+	for i, key := range keys {
+		barHeight := height * float64(dist.Hist[key]) / float64(maxCount)
+		fmt.Fprintf(sb, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f"><title>%d DPS: %d iterations</title></rect>`,
+			float64(i)*barWidth+1, height-barHeight, max(barWidth-2, 1), barHeight, key, dist.Hist[key])
+	}
+	fmt.Fprintf(sb, `<text x="0" y="%g">%d</text>`, height+labelHeight-2, keys[0])
+	fmt.Fprintf(sb, `<text x="%g" y="%g" text-anchor="end">%d</text>`, width, height+labelHeight-2, keys[len(keys)-1])
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
+
+// Renders horizontal bars with labels as an inline SVG.
+func barChartSVG(bars []svgBar) template.HTML {
+	slices.SortStableFunc(bars, func(a, b svgBar) int { return compareDesc(a.Value, b.Value) })
+	maxValue := 1.0
+	for _, bar := range bars {
+		maxValue = max(maxValue, bar.Value)
+	}
+
+	const width, rowHeight, labelWidth = 600.0, 20.0, 150.0
+	sb := &strings.Builder{}
+	fmt.Fprintf(sb, `<svg class="chart" viewBox="0 0 %g %g" xmlns="http://www.w3.org/2000/svg">`, width, rowHeight*float64(len(bars)))
+	for i, bar := range bars {
+		y := float64(i) * rowHeight
+		fmt.Fprintf(sb, `<text x="0" y="%g">%s</text>`, y+rowHeight-6, template.HTMLEscapeString(bar.Label))
+		fmt.Fprintf(sb, `<rect x="%g" y="%g" width="%.1f" height="%g"/>`, labelWidth, y+2, (width-labelWidth-60)*bar.Value/maxValue, rowHeight-4)
+		fmt.Fprintf(sb, `<text x="%g" y="%g" text-anchor="end">%.1f</text>`, width, y+rowHeight-6, bar.Value)
+	}
+	sb.WriteString(`</svg>`)
+	return template.HTML(sb.String())
+}
@@ -18,6 +18,18 @@ func Execute(version string) {
 	rootCmd.AddCommand(simCmd)
 	rootCmd.AddCommand(bulkCmd)
 	rootCmd.AddCommand(decodeLinkCmd)
+	rootCmd.AddCommand(encodeLinkCmd)
+	rootCmd.AddCommand(importCmd)
+	rootCmd.AddCommand(sweepCmd)
+	rootCmd.AddCommand(buffValueCmd)
+	rootCmd.AddCommand(cooldownOptimizerCmd)
+	rootCmd.AddCommand(trinketRankingCmd)
+	rootCmd.AddCommand(manaCmd)
+	rootCmd.AddCommand(reportCmd)
+	rootCmd.AddCommand(regressCmd)
+	rootCmd.AddCommand(validateCmd)
+	rootCmd.AddCommand(migrateCmd)
+	rootCmd.AddCommand(dbCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
@@ -0,0 +1,104 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+var (
+	sweepAxes   []string
+	sweepFormat string
+)
+
+var sweepCmd = &cobra.Command{
+	Use:   "sweep",
+	Short: "simulate a grid of settings, e.g. fight lengths and target counts",
+	Long: `simulate a grid of settings, e.g. fight lengths and target counts
+
+Each --axis has the form path=value1,value2,... where path is a field path
+relative to the RaidSimRequest. Examples:
+  --axis encounter.duration=60,120,300
+  --axis encounter.targets=1,3,5
+  --axis encounter.execute_proportion_20=0.1,0.2
+  --axis raid.parties.0.players.*.distance_from_target=5,25`,
+	Run: sweepMain,
+}
+
+func init() {
+	sweepCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	sweepCmd.Flags().StringArrayVar(&sweepAxes, "axis", nil, "sweep axis in the form path=value1,value2,... (repeatable)")
+	sweepCmd.Flags().StringVar(&sweepFormat, "format", "table", "output format, 'table', 'csv' or 'json'")
+	sweepCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	sweepCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	sweepCmd.MarkFlagRequired("infile")
+	sweepCmd.MarkFlagRequired("axis")
+}
+
+func sweepMain(cmd *cobra.Command, args []string) {
+	request := &proto.SweepRequest{BaseSettings: loadRaidSimRequest(infile)}
+	for _, axis := range sweepAxes {
+		path, values, ok := strings.Cut(axis, "=")
+		if !ok || path == "" || values == "" {
+			log.Fatalf("invalid axis %q, expected path=value1,value2,...", axis)
+		}
+		request.Axes = append(request.Axes, &proto.SweepAxis{Path: path, Values: strings.Split(values, ",")})
+	}
+
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunSweepAsync(request, reporter, "cmd-sweep")
+
+	var result *proto.SweepResult
+	for v := range reporter {
+		if v.FinalSweepResult != nil {
+			result = v.FinalSweepResult
+			break
+		}
+		if verbose && v.TotalSims > 0 {
+			fmt.Printf("Sweep Progress: %d / %d iterations (completed %d / %d sims)\n", v.CompletedIterations, v.TotalIterations, v.CompletedSims, v.TotalSims)
+		}
+	}
+	if result.Error != nil {
+		log.Fatalf("sweep failed: %s", result.Error.Message)
+	}
+
+	writeOutput(formatResult(sweepFormat, sweepRows(result), result))
+}
+
+// sweepRows returns the header and one row per grid point.
+func sweepRows(result *proto.SweepResult) [][]string {
+	if len(result.Points) == 0 {
+		return nil
+	}
+
+	header := []string{}
+	for _, pv := range result.Points[0].Values {
+		header = append(header, pv.Path)
+	}
+	header = append(header, "raid_dps_avg", "raid_dps_stdev", "raid_dps_min", "raid_dps_max")
+	for _, player := range result.Points[0].Players {
+		header = append(header, player.Name+"_dps_avg", player.Name+"_dps_stdev")
+	}
+
+	rows := [][]string{header}
+	for _, point := range result.Points {
+		row := []string{}
+		for _, pv := range point.Values {
+			row = append(row, pv.Value)
+		}
+		row = append(row,
+			fmt.Sprintf("%0.1f", point.RaidDps.Avg),
+			fmt.Sprintf("%0.1f", point.RaidDps.Stdev),
+			fmt.Sprintf("%0.1f", point.RaidDps.Min),
+			fmt.Sprintf("%0.1f", point.RaidDps.Max))
+		for _, player := range point.Players {
+			row = append(row, fmt.Sprintf("%0.1f", player.Dps.Avg), fmt.Sprintf("%0.1f", player.Dps.Stdev))
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,124 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+var (
+	trinketRankingIDs        []int32
+	trinketRankingPhase      int32
+	trinketRankingDurations  []float64
+	trinketRankingIterations int32
+	trinketRankingFormat     string
+)
+
+var trinketRankingCmd = &cobra.Command{
+	Use:   "trinkets",
+	Short: "rank pairs of trinkets and on-use items",
+	Long: `rank pairs of trinkets and on-use items
+
+Sims every legal pair of the given trinkets, or of all trinkets in the
+database the player can equip in the phase, at each fight length. All pairs
+are simmed with the same seed, and the output has a matrix of the DPS of
+each pair plus the best pair for each fight length, e.g.
+  --trinkets 19950,19949,19379 --durations 60,180,300`,
+	Run: trinketRankingMain,
+}
+
+func init() {
+	trinketRankingCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	trinketRankingCmd.Flags().Int32SliceVar(&trinketRankingIDs, "trinkets", nil, "comma separated item ids of the trinkets to rank, defaults to all trinkets in the database")
+	trinketRankingCmd.Flags().Int32Var(&trinketRankingPhase, "phase", 0, "latest phase of the trinkets taken from the database, 0 for all")
+	trinketRankingCmd.Flags().Float64SliceVar(&trinketRankingDurations, "durations", nil, "comma separated fight lengths in seconds, defaults to the encounter duration")
+	trinketRankingCmd.Flags().Int32Var(&trinketRankingIterations, "iterations", 0, "iterations per pair and fight length, defaults to the iterations of the input")
+	trinketRankingCmd.Flags().StringVar(&trinketRankingFormat, "format", "table", "output format, 'table', 'csv' or 'json'")
+	trinketRankingCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	trinketRankingCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	trinketRankingCmd.MarkFlagRequired("infile")
+}
+
+func trinketRankingMain(cmd *cobra.Command, args []string) {
+	request := &proto.TrinketRankingRequest{
+		BaseSettings:      loadRaidSimRequest(infile),
+		TrinketIds:        trinketRankingIDs,
+		Phase:             trinketRankingPhase,
+		Durations:         trinketRankingDurations,
+		IterationsPerPair: trinketRankingIterations,
+	}
+
+	reporter := make(chan *proto.ProgressMetrics, 100)
+	core.RunTrinketRankingAsync(request, reporter, "cmd-trinket-ranking")
+
+	var result *proto.TrinketRankingResult
+	for v := range reporter {
+		if v.FinalTrinketRankingResult != nil {
+			result = v.FinalTrinketRankingResult
+			break
+		}
+		if verbose && v.TotalSims > 0 {
+			fmt.Printf("Trinket Ranking Progress: %d / %d iterations (completed %d / %d sims)\n", v.CompletedIterations, v.TotalIterations, v.CompletedSims, v.TotalSims)
+		}
+	}
+	if result.Error != nil {
+		log.Fatalf("trinket ranking failed: %s", result.Error.Message)
+	}
+
+	writeOutput(formatResult(trinketRankingFormat, trinketRankingRows(result), result))
+}
+
+// trinketRankingRows returns a matrix of pair DPS for each duration, with the trinkets in ranked
+// order, followed by the best pair of the duration.
+func trinketRankingRows(result *proto.TrinketRankingResult) [][]string {
+	var rows [][]string
+	for i, duration := range result.Durations {
+		if i > 0 {
+			rows = append(rows, []string{})
+		}
+
+		pairDps := make(map[[2]int32]float64, len(duration.Pairs))
+		for _, pair := range duration.Pairs {
+			pairDps[[2]int32{pair.Trinket1Id, pair.Trinket2Id}] = pair.Dps
+			pairDps[[2]int32{pair.Trinket2Id, pair.Trinket1Id}] = pair.Dps
+		}
+
+		header := []string{fmt.Sprintf("[%0.0fs]", duration.Duration)}
+		for _, id := range result.TrinketIds {
+			header = append(header, trinketName(id))
+		}
+		rows = append(rows, header)
+		for _, id1 := range result.TrinketIds {
+			row := []string{trinketName(id1)}
+			for _, id2 := range result.TrinketIds {
+				if dps, ok := pairDps[[2]int32{id1, id2}]; ok {
+					row = append(row, fmt.Sprintf("%0.1f", dps))
+				} else {
+					row = append(row, "-")
+				}
+			}
+			rows = append(rows, row)
+		}
+
+		if len(duration.Pairs) > 0 {
+			best := duration.Pairs[0]
+			rows = append(rows, []string{
+				"[BEST PAIR]",
+				trinketName(best.Trinket1Id) + " + " + trinketName(best.Trinket2Id),
+				fmt.Sprintf("%0.1f +/- %0.1f", best.Dps, best.DpsStdev),
+			})
+		}
+	}
+	return rows
+}
+
+func trinketName(id int32) string {
+	if item, ok := core.ItemsByID[id]; ok && item.Name != "" {
+		return item.Name
+	}
+	return strconv.Itoa(int(id))
+}
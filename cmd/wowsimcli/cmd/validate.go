@@ -0,0 +1,55 @@
+package cmd
+
+import (
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+var validateFormat string
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "check a sim request for invalid gear, runes and talents",
+	Long: `check a sim request for invalid gear, runes and talents
+
+Prints the problems the sim would reject the request for, like items of the
+wrong class or level, two-handers with an off-hand and impossible talent
+strings, along with warnings for problems it would ignore.
+Exits with status 1 if there are any errors.`,
+	Run: validateMain,
+}
+
+func init() {
+	validateCmd.Flags().StringVar(&infile, "infile", "input.json", "location of input file (RaidSimRequest in protojson format)")
+	validateCmd.Flags().StringVar(&validateFormat, "format", "table", "output format, 'table', 'csv' or 'json'")
+	validateCmd.Flags().StringVar(&outfile, "outfile", "", "location of output file, defaults to stdout")
+	validateCmd.Flags().BoolVar(&verbose, "verbose", false, "print information during runtime")
+	validateCmd.MarkFlagRequired("infile")
+}
+
+func validateMain(cmd *cobra.Command, args []string) {
+	issues := core.ValidateRaidSimRequest(loadRaidSimRequest(infile))
+
+	rows := [][]string{{"Severity", "Path", "Message"}}
+	for _, issue := range issues {
+		rows = append(rows, []string{
+			strings.TrimPrefix(issue.Severity.String(), "ValidationSeverity"),
+			issue.Path,
+			issue.Message,
+		})
+	}
+
+	output := formatResult(validateFormat, rows, &proto.ErrorOutcome{Issues: issues})
+	if validateFormat == "table" && len(issues) == 0 {
+		output = "No issues found.\n"
+	}
+	writeOutput(output)
+
+	if core.HasValidationErrors(issues) {
+		os.Exit(1)
+	}
+}
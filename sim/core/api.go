@@ -131,6 +131,115 @@ func RunBulkSimAsync(request *proto.BulkSimRequest, progress chan *proto.Progres
 	}()
 }
 
+/**
+ * Runs a raid sim for every point of the grid spanned by the request's sweep axes.
+ */
+func RunSweep(request *proto.SweepRequest) *proto.SweepResult {
+	return runSweep(request, nil, simsignals.CreateSignals())
+}
+
+func RunSweepAsync(request *proto.SweepRequest, progress chan *proto.ProgressMetrics, requestId string) {
+	signals, err := simsignals.RegisterWithId(requestId)
+	if err != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalSweepResult: &proto.SweepResult{
+				Error: &proto.ErrorOutcome{
+					Message: "Couldn't register for signal API: " + err.Error(),
+				},
+			},
+		}
+		return
+	}
+	go func() {
+		defer simsignals.UnregisterId(requestId)
+		result := runSweep(request, progress, signals)
+		progress <- &proto.ProgressMetrics{
+			FinalSweepResult: result,
+		}
+	}()
+}
+
+/**
+ * Runs a sim per enabled buff, debuff and consumable with it removed (or added) to find its DPS value.
+ */
+func RunBuffValue(request *proto.BuffValueRequest) *proto.BuffValueResult {
+	return runBuffValue(request, nil, simsignals.CreateSignals())
+}
+
+func RunBuffValueAsync(request *proto.BuffValueRequest, progress chan *proto.ProgressMetrics, requestId string) {
+	signals, err := simsignals.RegisterWithId(requestId)
+	if err != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalBuffValueResult: &proto.BuffValueResult{
+				Error: &proto.ErrorOutcome{
+					Message: "Couldn't register for signal API: " + err.Error(),
+				},
+			},
+		}
+		return
+	}
+	go func() {
+		defer simsignals.UnregisterId(requestId)
+		result := runBuffValue(request, progress, signals)
+		progress <- &proto.ProgressMetrics{
+			FinalBuffValueResult: result,
+		}
+	}()
+}
+
+/**
+ * Searches for the major cooldown timings of the first player which result in the highest DPS.
+ */
+func RunCooldownOptimizer(request *proto.CooldownOptimizerRequest) *proto.CooldownOptimizerResult {
+	return runCooldownOptimizer(request, nil, simsignals.CreateSignals())
+}
+
+func RunCooldownOptimizerAsync(request *proto.CooldownOptimizerRequest, progress chan *proto.ProgressMetrics, requestId string) {
+	signals, err := simsignals.RegisterWithId(requestId)
+	if err != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalCooldownOptimizerResult: &proto.CooldownOptimizerResult{
+				Error: &proto.ErrorOutcome{
+					Message: "Couldn't register for signal API: " + err.Error(),
+				},
+			},
+		}
+		return
+	}
+	go func() {
+		defer simsignals.UnregisterId(requestId)
+		result := runCooldownOptimizer(request, progress, signals)
+		progress <- &proto.ProgressMetrics{
+			FinalCooldownOptimizerResult: result,
+		}
+	}()
+}
+
+func RunTrinketRanking(request *proto.TrinketRankingRequest) *proto.TrinketRankingResult {
+	return runTrinketRanking(request, nil, simsignals.CreateSignals())
+}
+
+func RunTrinketRankingAsync(request *proto.TrinketRankingRequest, progress chan *proto.ProgressMetrics, requestId string) {
+	signals, err := simsignals.RegisterWithId(requestId)
+	if err != nil {
+		progress <- &proto.ProgressMetrics{
+			FinalTrinketRankingResult: &proto.TrinketRankingResult{
+				Error: &proto.ErrorOutcome{
+					Message: "Couldn't register for signal API: " + err.Error(),
+				},
+			},
+		}
+		return
+	}
+	go func() {
+		defer simsignals.UnregisterId(requestId)
+		result := runTrinketRanking(request, progress, signals)
+		progress <- &proto.ProgressMetrics{
+			FinalTrinketRankingResult: result,
+		}
+	}()
+}
+
 var runningInWasm = false
 
 func SetRunningInWasm() {
@@ -190,6 +190,11 @@ func (apl *APLRotation) DoNextAction(sim *Simulation) {
 		return
 	}
 
+	// Dead or despawned units do not act.
+	if !apl.unit.IsEnabled() {
+		return
+	}
+
 	if apl.unit.IsChanneling(sim) && !apl.allowCastWhileChanneling {
 		return
 	}
@@ -202,12 +207,21 @@ func (apl *APLRotation) DoNextAction(sim *Simulation) {
 			panic(fmt.Sprintf("[USER_ERROR] Infinite loop detected, current action:\n%s", nextAction))
 		}
 
+		// Human execution delays the first action of each decision, the rotation decides again afterwards.
+		if i == 0 && apl.unit.delayForExecution(sim) {
+			apl.inLoop = false
+			return
+		}
+
 		nextAction.Execute(sim)
 	}
 	apl.inLoop = false
 
-	if sim.Log != nil && i == 0 {
-		apl.unit.Log(sim, "No available actions!")
+	if i == 0 {
+		apl.unit.idleForExecution()
+		if sim.Log != nil {
+			apl.unit.Log(sim, "No available actions!")
+		}
 	}
 
 	gcdReady := apl.unit.GCD.IsReady(sim)
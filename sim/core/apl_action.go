@@ -57,6 +57,8 @@ func (action *APLAction) GetAllSpells() []*Spell {
 	for _, a := range action.GetAllActions() {
 		if impl, ok := a.impl.(*APLActionCastSpell); ok {
 			spells = append(spells, impl.spell)
+		} else if impl, ok := a.impl.(*APLActionCastSpellRank); ok {
+			spells = append(spells, impl.ranks...)
 		} else if impl, ok := a.impl.(*APLActionMultidot); ok {
 			spells = append(spells, impl.spell)
 		} else if impl, ok := a.impl.(*APLActionMultishield); ok {
@@ -141,6 +143,8 @@ func (rot *APLRotation) newAPLActionImpl(config *proto.APLAction) APLActionImpl
 	// Casting
 	case *proto.APLAction_CastSpell:
 		return rot.newActionCastSpell(config.GetCastSpell())
+	case *proto.APLAction_CastSpellRank:
+		return rot.newActionCastSpellRank(config.GetCastSpellRank())
 	case *proto.APLAction_ChannelSpell:
 		return rot.newActionChannelSpell(config.GetChannelSpell())
 	case *proto.APLAction_Multidot:
@@ -169,6 +173,8 @@ func (rot *APLRotation) newAPLActionImpl(config *proto.APLAction) APLActionImpl
 	// Misc
 	case *proto.APLAction_ChangeTarget:
 		return rot.newActionChangeTarget(config.GetChangeTarget())
+	case *proto.APLAction_BattleRes:
+		return rot.newActionBattleRes(config.GetBattleRes())
 	case *proto.APLAction_ActivateAura:
 		return rot.newActionActivateAura(config.GetActivateAura())
 	case *proto.APLAction_ActivateAuraWithStacks:
@@ -179,6 +185,8 @@ func (rot *APLRotation) newAPLActionImpl(config *proto.APLAction) APLActionImpl
 		return rot.newActionTriggerICD(config.GetTriggerIcd())
 	case *proto.APLAction_ItemSwap:
 		return rot.newActionItemSwap(config.GetItemSwap())
+	case *proto.APLAction_SwapToItemSet:
+		return rot.newActionSwapToItemSet(config.GetSwapToItemSet())
 	case *proto.APLAction_Move:
 		return rot.newActionMove(config.GetMove())
 	case *proto.APLAction_CustomRotation:
@@ -2,6 +2,8 @@ package core
 
 import (
 	"fmt"
+	"math"
+	"time"
 
 	"github.com/wowsims/classic/sim/core/proto"
 )
@@ -36,6 +38,139 @@ func (action *APLActionCastSpell) String() string {
 	return fmt.Sprintf("Cast Spell(%s)", action.spell.ActionID)
 }
 
+type APLActionCastSpellRank struct {
+	defaultAPLActionImpl
+	ranks    []*Spell // Highest rank first.
+	target   UnitReference
+	policy   proto.APLActionCastSpellRank_RankPolicy
+	deadline APLValue
+}
+
+func (rot *APLRotation) newActionCastSpellRank(config *proto.APLActionCastSpellRank) APLActionImpl {
+	var ranks []*Spell
+	if len(config.RankIds) > 0 {
+		// Lower level characters don't know the highest ranks, so unknown ranks are skipped.
+		for _, rankID := range config.RankIds {
+			if spell := rot.unit.GetSpell(ProtoToActionID(rankID)); spell != nil {
+				ranks = append(ranks, spell)
+			}
+		}
+		if len(ranks) == 0 {
+			rot.ValidationWarning("%s does not know any of the ranks", rot.unit.Label)
+			return nil
+		}
+	} else {
+		spell := rot.GetAPLSpell(config.SpellId)
+		if spell == nil {
+			return nil
+		}
+		ranks = rot.unit.SpellRanks(spell)
+	}
+
+	target := rot.GetTargetUnit(config.Target)
+	if target.Get() == nil {
+		return nil
+	}
+
+	deadline := rot.coerceTo(rot.newAPLValue(config.Deadline), proto.APLValueType_ValueTypeDuration)
+	if config.Deadline != nil && deadline == nil {
+		return nil
+	}
+
+	return &APLActionCastSpellRank{
+		ranks:    ranks,
+		target:   target,
+		policy:   config.Policy,
+		deadline: deadline,
+	}
+}
+func (action *APLActionCastSpellRank) GetAPLValues() []APLValue {
+	return []APLValue{action.deadline}
+}
+func (action *APLActionCastSpellRank) IsReady(sim *Simulation) bool {
+	return action.chooseRank(sim) != nil
+}
+func (action *APLActionCastSpellRank) Execute(sim *Simulation) {
+	if rank := action.chooseRank(sim); rank != nil {
+		rank.Cast(sim, action.target.Get())
+	}
+}
+func (action *APLActionCastSpellRank) String() string {
+	return fmt.Sprintf("Cast Spell Rank(%s, %s)", action.ranks[0].ActionID, action.policy)
+}
+
+// Returns the rank to cast according to the policy, or nil if none can be cast.
+func (action *APLActionCastSpellRank) chooseRank(sim *Simulation) *Spell {
+	target := action.target.Get()
+	canCast := func(rank *Spell) bool {
+		return rank.canAffordMana() && rank.CanCast(sim, target)
+	}
+
+	candidates := action.ranks
+	switch action.policy {
+	case proto.APLActionCastSpellRank_RankPolicyDamagePerMana:
+		var best *Spell
+		bestValue := 0.0
+		for _, rank := range candidates {
+			if !canCast(rank) {
+				continue
+			}
+			value, ok := rank.valuePerCast()
+			if !ok {
+				// Every rank is cast once, to find out how much it does.
+				return rank
+			}
+			if cost := rank.manaCost(); cost > 0 {
+				value /= cost
+			} else {
+				value = math.Inf(1)
+			}
+			if best == nil || value > bestValue {
+				best, bestValue = rank, value
+			}
+		}
+		if best != nil {
+			return best
+		}
+	case proto.APLActionCastSpellRank_RankPolicyManaBudget:
+		// Assumes the rank is cast as often as possible for the rest of the fight.
+		remaining := sim.GetRemainingDuration()
+		mana := action.ranks[0].Unit.CurrentMana() + action.ranks[0].Unit.ManaRegenPerSecondWhileCasting()*remaining.Seconds()
+		for _, rank := range candidates {
+			interval := max(rank.EffectiveCastTime(), rank.CD.Duration, time.Millisecond)
+			casts := math.Ceil(remaining.Seconds() / interval.Seconds())
+			if rank.manaCost()*casts <= mana && canCast(rank) {
+				return rank
+			}
+		}
+	case proto.APLActionCastSpellRank_RankPolicyDeadline:
+		deadline := sim.GetRemainingDuration()
+		if action.deadline != nil {
+			deadline = action.deadline.GetDuration(sim)
+		}
+		candidates = FilterSlice(candidates, func(rank *Spell) bool {
+			return rank.CastTime() <= deadline
+		})
+		if len(candidates) == 0 {
+			return nil
+		}
+		fallthrough
+	default:
+		for _, rank := range candidates {
+			if canCast(rank) {
+				return rank
+			}
+		}
+	}
+
+	// Checks the lowest rank the usual way, so waiting for mana to cast it counts as time spent OOM.
+	lowest := candidates[len(candidates)-1]
+	if lowest.CanCast(sim, target) {
+		return lowest
+	}
+	return nil
+}
+
 type APLActionChannelSpell struct {
 	defaultAPLActionImpl
 	spell            *Spell
@@ -40,6 +40,51 @@ func (action *APLActionChangeTarget) String() string {
 	return fmt.Sprintf("Change Target(%s)", action.newTarget.Get().Label)
 }
 
+type APLActionBattleRes struct {
+	defaultAPLActionImpl
+	unit   *Unit
+	target *Character
+	delay  time.Duration
+}
+
+func (rot *APLRotation) newActionBattleRes(config *proto.APLActionBattleRes) APLActionImpl {
+	if !rot.unit.Env.Raid.deathModel.GetEnabled() {
+		rot.ValidationWarning("Battle Res needs deaths to be enabled")
+		return nil
+	}
+	targetUnit := rot.GetSourceUnit(config.Target).Get()
+	if targetUnit == nil {
+		return nil
+	}
+	if targetUnit == rot.unit {
+		rot.ValidationWarning("Battle Res needs another player as target")
+		return nil
+	}
+	target := rot.unit.Env.Raid.GetPlayerFromUnit(targetUnit)
+	if target == nil || target.GetCharacter().Unit.Type != PlayerUnit {
+		rot.ValidationWarning("%s is not a player", targetUnit.Label)
+		return nil
+	}
+	return &APLActionBattleRes{
+		unit:   rot.unit,
+		target: target.GetCharacter(),
+		delay:  DurationFromSeconds(config.DelaySeconds),
+	}
+}
+func (action *APLActionBattleRes) IsReady(sim *Simulation) bool {
+	return !action.target.IsEnabled() && !action.target.resurrecting && action.unit.Env.Raid.canResurrect() && action.unit.GCD.IsReady(sim)
+}
+func (action *APLActionBattleRes) Execute(sim *Simulation) {
+	if sim.Log != nil {
+		action.unit.Log(sim, "Resurrecting %s", action.target.Label)
+	}
+	action.target.scheduleResurrection(sim, action.delay)
+	action.unit.SetGCDTimer(sim, sim.CurrentTime+GCDDefault)
+}
+func (action *APLActionBattleRes) String() string {
+	return fmt.Sprintf("Battle Res(%s)", action.target.Label)
+}
+
 type APLActionCancelAura struct {
 	defaultAPLActionImpl
 	aura *Aura
@@ -252,6 +297,42 @@ func (action *APLActionItemSwap) String() string {
 	return fmt.Sprintf("Item Swap(%s)", action.swapSet)
 }
 
+type APLActionSwapToItemSet struct {
+	defaultAPLActionImpl
+	character *Character
+	set       *ItemSwapSet
+}
+
+func (rot *APLRotation) newActionSwapToItemSet(config *proto.APLActionSwapToItemSet) APLActionImpl {
+	character := rot.unit.Env.Raid.GetPlayerFromUnit(rot.unit).GetCharacter()
+	if !character.ItemSwapSets.IsEnabled() {
+		if config.SetName != MainItemSwapSetName {
+			rot.ValidationWarning("No item swap sets configured.")
+		}
+		return nil
+	}
+
+	set := character.ItemSwapSets.GetSet(config.SetName)
+	if set == nil {
+		rot.ValidationWarning("Unknown item swap set '%s'", config.SetName)
+		return nil
+	}
+
+	return &APLActionSwapToItemSet{
+		character: character,
+		set:       set,
+	}
+}
+func (action *APLActionSwapToItemSet) IsReady(sim *Simulation) bool {
+	return action.character.ItemSwapSets.CurrentSet() != action.set
+}
+func (action *APLActionSwapToItemSet) Execute(sim *Simulation) {
+	action.character.ItemSwapSets.SwapToSet(sim, action.set)
+}
+func (action *APLActionSwapToItemSet) String() string {
+	return fmt.Sprintf("Swap to Item Set(%s)", action.set.Name)
+}
+
 type APLActionMove struct {
 	defaultAPLActionImpl
 	unit      *Unit
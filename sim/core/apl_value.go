@@ -189,6 +189,12 @@ func (rot *APLRotation) newAPLValue(config *proto.APLValue) APLValue {
 	// Properties
 	case *proto.APLValue_ChannelClipDelay:
 		return rot.newValueChannelClipDelay(config.GetChannelClipDelay())
+	case *proto.APLValue_TargetIsCastingInterruptible:
+		return rot.newValueTargetIsCastingInterruptible(config.GetTargetIsCastingInterruptible())
+	case *proto.APLValue_TargetCastRemainingTime:
+		return rot.newValueTargetCastRemainingTime(config.GetTargetCastRemainingTime())
+	case *proto.APLValue_CurrentItemSwapSet:
+		return rot.newValueCurrentItemSwapSet(config.GetCurrentItemSwapSet())
 
 	default:
 		return nil
@@ -122,3 +122,55 @@ func (value *APLValueIsExecutePhase) GetBool(sim *Simulation) bool {
 func (value *APLValueIsExecutePhase) String() string {
 	return "Is Execute Phase"
 }
+
+type APLValueTargetIsCastingInterruptible struct {
+	DefaultAPLValueImpl
+	targetUnit UnitReference
+}
+
+func (rot *APLRotation) newValueTargetIsCastingInterruptible(config *proto.APLValueTargetIsCastingInterruptible) APLValue {
+	targetUnit := rot.GetTargetUnit(config.TargetUnit)
+	if targetUnit.Get() == nil {
+		return nil
+	}
+	return &APLValueTargetIsCastingInterruptible{
+		targetUnit: targetUnit,
+	}
+}
+func (value *APLValueTargetIsCastingInterruptible) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeBool
+}
+func (value *APLValueTargetIsCastingInterruptible) GetBool(sim *Simulation) bool {
+	return value.targetUnit.Get().IsCastingInterruptible(sim)
+}
+func (value *APLValueTargetIsCastingInterruptible) String() string {
+	return "Target Is Casting Interruptible"
+}
+
+type APLValueTargetCastRemainingTime struct {
+	DefaultAPLValueImpl
+	targetUnit UnitReference
+}
+
+func (rot *APLRotation) newValueTargetCastRemainingTime(config *proto.APLValueTargetCastRemainingTime) APLValue {
+	targetUnit := rot.GetTargetUnit(config.TargetUnit)
+	if targetUnit.Get() == nil {
+		return nil
+	}
+	return &APLValueTargetCastRemainingTime{
+		targetUnit: targetUnit,
+	}
+}
+func (value *APLValueTargetCastRemainingTime) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeDuration
+}
+func (value *APLValueTargetCastRemainingTime) GetDuration(sim *Simulation) time.Duration {
+	target := value.targetUnit.Get()
+	if !target.IsCasting(sim) {
+		return 0
+	}
+	return target.Hardcast.Expires - sim.CurrentTime
+}
+func (value *APLValueTargetCastRemainingTime) String() string {
+	return "Target Cast Remaining Time"
+}
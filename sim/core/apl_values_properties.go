@@ -45,3 +45,28 @@ func (value *APLValueFrontOfTarget) GetBool(sim *Simulation) bool {
 func (value *APLValueFrontOfTarget) String() string {
 	return "Front of Target()"
 }
+
+type APLValueCurrentItemSwapSet struct {
+	DefaultAPLValueImpl
+	character *Character
+}
+
+func (rot *APLRotation) newValueCurrentItemSwapSet(config *proto.APLValueCurrentItemSwapSet) APLValue {
+	character := rot.unit.Env.Raid.GetPlayerFromUnit(rot.unit).GetCharacter()
+	if !character.ItemSwapSets.IsEnabled() {
+		rot.ValidationWarning("No item swap sets configured.")
+		return nil
+	}
+	return &APLValueCurrentItemSwapSet{
+		character: character,
+	}
+}
+func (value *APLValueCurrentItemSwapSet) Type() proto.APLValueType {
+	return proto.APLValueType_ValueTypeString
+}
+func (value *APLValueCurrentItemSwapSet) GetString(sim *Simulation) string {
+	return value.character.ItemSwapSets.CurrentSet().Name
+}
+func (value *APLValueCurrentItemSwapSet) String() string {
+	return "Current Item Swap Set()"
+}
@@ -208,6 +208,13 @@ func (aura *Aura) SetStacks(sim *Simulation, newStacks int32) {
 	if sim.Log != nil {
 		aura.Unit.Log(sim, "%s stacks: %d --> %d", aura.ActionID, oldStacks, newStacks)
 	}
+	// Stacks removed on deactivation are counted in Deactivate, up to the expiration.
+	if aura.active {
+		aura.metrics.addStackTime(aura, sim.CurrentTime)
+		if newStacks < oldStacks {
+			aura.metrics.StacksConsumed += oldStacks - newStacks
+		}
+	}
 	aura.stacks = newStacks
 	if aura.OnStacksChange != nil {
 		aura.OnStacksChange(aura, sim, oldStacks, newStacks)
@@ -666,6 +673,10 @@ func (aura *Aura) Deactivate(sim *Simulation) {
 		oldTime := sim.CurrentTime
 		sim.CurrentTime = min(sim.CurrentTime, aura.expires)
 		aura.metrics.Uptime += sim.CurrentTime - max(aura.startTime, 0)
+		aura.metrics.addStackTime(aura, sim.CurrentTime)
+		if timeSeries := aura.Unit.Metrics.timeSeries; timeSeries != nil {
+			timeSeries.addUptime(aura.ActionID, max(aura.startTime, 0), sim.CurrentTime)
+		}
 		if sim.Log != nil {
 			aura.Unit.Log(sim, "Aura faded: %s", aura.ActionID)
 		}
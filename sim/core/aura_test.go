@@ -0,0 +1,42 @@
+package core
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAuraStackMetrics(t *testing.T) {
+	sim := &Simulation{}
+
+	target := Unit{
+		Type:        EnemyUnit,
+		Index:       0,
+		Level:       63,
+		auraTracker: newAuraTracker(),
+	}
+	aura := target.RegisterAura(Aura{
+		Label:     "Charges",
+		ActionID:  ActionID{SpellID: 1},
+		Duration:  time.Second * 10,
+		MaxStacks: 3,
+	})
+
+	aura.Activate(sim)
+	aura.SetStacks(sim, 3)
+
+	sim.CurrentTime = time.Second * 4
+	aura.RemoveStack(sim)
+
+	sim.CurrentTime = time.Second * 6
+	aura.Deactivate(sim)
+	aura.metrics.doneIteration()
+
+	metrics := aura.metrics.ToProto()
+	if metrics.StackSecondsAvg != 16 || metrics.MaxStacksSecondsAvg != 4 {
+		t.Fatalf("Expected 16 stack seconds with 4s at max stacks, got %v", metrics)
+	}
+	// Stacks removed when the aura fades are not consumed.
+	if metrics.StacksConsumedAvg != 1 {
+		t.Fatalf("Expected 1 consumed stack, got %v", metrics.StacksConsumedAvg)
+	}
+}
@@ -0,0 +1,208 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/types/descriptorpb"
+)
+
+// A single buff, debuff or consumable to evaluate, and the value to assign to its field.
+type buffValueCandidate struct {
+	path    string
+	value   string // Value of the field when the entry is enabled.
+	removed bool
+	assign  string // Value assigned for the sim, i.e. the zero value for removed entries.
+}
+
+// Run one sim per enabled buff, debuff and consumable with that entry removed, and one sim per
+// requested addition with that entry added. All sims share the same seed and labeled rands,
+// so the differences to the base sim are not drowned out by run-to-run noise.
+func runBuffValue(request *proto.BuffValueRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) *proto.BuffValueResult {
+	if request.BaseSettings == nil || request.BaseSettings.Raid == nil || request.BaseSettings.SimOptions == nil {
+		return &proto.BuffValueResult{Error: &proto.ErrorOutcome{Message: "buff value: no base settings given"}}
+	}
+
+	baseRequest := googleProto.Clone(request.BaseSettings).(*proto.RaidSimRequest)
+	baseRequest.SimOptions.SaveAllValues = true
+	baseRequest.SimOptions.UseLabeledRands = true
+	if baseRequest.SimOptions.RandomSeed == 0 {
+		baseRequest.SimOptions.RandomSeed = time.Now().UnixNano()
+	}
+
+	candidates, err := collectBuffValueCandidates(baseRequest, request.IncludeAdditions)
+	if err != nil {
+		return &proto.BuffValueResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+	}
+	for _, addition := range request.Additions {
+		candidates = append(candidates, buffValueCandidate{
+			path:   addition.Path,
+			value:  addition.Value,
+			assign: addition.Value,
+		})
+	}
+
+	requests := []*proto.RaidSimRequest{baseRequest}
+	for _, candidate := range candidates {
+		req := googleProto.Clone(baseRequest).(*proto.RaidSimRequest)
+		if err := ApplySweepValue(req, candidate.path, candidate.assign); err != nil {
+			return &proto.BuffValueResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+		}
+		requests = append(requests, req)
+	}
+
+	simResults, errorOutcome := runRaidSimBatch(requests, progress, signals)
+	if errorOutcome != nil {
+		return &proto.BuffValueResult{Error: errorOutcome}
+	}
+
+	baseResult := simResults[0]
+	basePlayer := FirstPlayerMetrics(baseResult)
+	if basePlayer == nil {
+		return &proto.BuffValueResult{Error: &proto.ErrorOutcome{Message: "buff value: no player in raid"}}
+	}
+
+	result := &proto.BuffValueResult{
+		BaseDps:     basePlayer.Dps.Avg,
+		BaseRaidDps: baseResult.RaidMetrics.Dps.Avg,
+	}
+
+	for i, candidate := range candidates {
+		simResult := simResults[i+1]
+		player := FirstPlayerMetrics(simResult)
+
+		// Deltas are always "with entry" minus "without entry".
+		sign := 1.0
+		if candidate.removed {
+			sign = -1.0
+		}
+
+		var diffs aggregator
+		for j := range basePlayer.Dps.AllValues {
+			diffs.add(sign * (player.Dps.AllValues[j] - basePlayer.Dps.AllValues[j]))
+		}
+		mean, stdev := diffs.meanAndStdDev()
+
+		entry := &proto.BuffValueEntry{
+			Path:          candidate.path,
+			Value:         candidate.value,
+			Removed:       candidate.removed,
+			DpsDelta:      mean,
+			DpsDeltaStdev: stdev,
+			RaidDpsDelta:  sign * (simResult.RaidMetrics.Dps.Avg - baseResult.RaidMetrics.Dps.Avg),
+			GoldCost:      buffValueGoldCost(request.GoldCosts, candidate.path),
+		}
+		if entry.GoldCost > 0 {
+			entry.DpsPerGold = entry.DpsDelta / entry.GoldCost
+		}
+		result.Entries = append(result.Entries, entry)
+	}
+
+	sort.SliceStable(result.Entries, func(i, j int) bool {
+		return result.Entries[i].DpsDelta > result.Entries[j].DpsDelta
+	})
+
+	return result
+}
+
+// Collects the buffs, debuffs and consumables affecting the first player of the request.
+func collectBuffValueCandidates(request *proto.RaidSimRequest, includeAdditions bool) ([]buffValueCandidate, error) {
+	partyIdx, playerIdx := -1, -1
+	for i, party := range request.Raid.Parties {
+		for j, player := range party.Players {
+			if player.Name != "" && partyIdx == -1 {
+				partyIdx, playerIdx = i, j
+			}
+		}
+	}
+	if partyIdx == -1 {
+		return nil, fmt.Errorf("buff value: no player in raid")
+	}
+
+	party := request.Raid.Parties[partyIdx]
+	player := party.Players[playerIdx]
+	partyPath := fmt.Sprintf("raid.parties.%d", partyIdx)
+	playerPath := fmt.Sprintf("%s.players.%d", partyPath, playerIdx)
+
+	var candidates []buffValueCandidate
+	candidates = appendBuffValueCandidates(candidates, "raid.buffs", request.Raid.GetBuffs().ProtoReflect(), includeAdditions)
+	candidates = appendBuffValueCandidates(candidates, partyPath+".buffs", party.GetBuffs().ProtoReflect(), includeAdditions)
+	candidates = appendBuffValueCandidates(candidates, playerPath+".buffs", player.GetBuffs().ProtoReflect(), includeAdditions)
+	candidates = appendBuffValueCandidates(candidates, "raid.debuffs", request.Raid.GetDebuffs().ProtoReflect(), includeAdditions)
+	// Which consumable to add is ambiguous, so additions are left to the request.
+	candidates = appendBuffValueCandidates(candidates, playerPath+".consumes", player.GetConsumes().ProtoReflect(), false)
+	return candidates, nil
+}
+
+func appendBuffValueCandidates(candidates []buffValueCandidate, path string, msg protoreflect.Message, includeAdditions bool) []buffValueCandidate {
+	fields := msg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.IsList() || fd.IsMap() || fd.Options().(*descriptorpb.FieldOptions).GetDeprecated() {
+			continue
+		}
+
+		fieldPath := path + "." + string(fd.Name())
+		if fd.Message() != nil {
+			candidates = appendBuffValueCandidates(candidates, fieldPath, msg.Get(fd).Message(), includeAdditions)
+			continue
+		}
+
+		if msg.Has(fd) {
+			candidates = append(candidates, buffValueCandidate{
+				path:    fieldPath,
+				value:   formatScalarValue(fd, msg.Get(fd)),
+				removed: true,
+				assign:  "0",
+			})
+			continue
+		}
+
+		if !includeAdditions {
+			continue
+		}
+		switch {
+		case fd.Kind() == protoreflect.BoolKind:
+			candidates = append(candidates, buffValueCandidate{path: fieldPath, value: "true", assign: "true"})
+		case fd.Kind() == protoreflect.EnumKind && fd.Enum().FullName() == "proto.TristateEffect":
+			values := fd.Enum().Values()
+			best := string(values.Get(values.Len() - 1).Name())
+			candidates = append(candidates, buffValueCandidate{path: fieldPath, value: best, assign: best})
+		}
+	}
+	return candidates
+}
+
+func formatScalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return strconv.FormatBool(v.Bool())
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return strconv.Itoa(int(v.Enum()))
+	default:
+		return v.String()
+	}
+}
+
+// Gold costs are keyed by the full entry path or a suffix of it, e.g. 'consumes.flask'.
+func buffValueGoldCost(goldCosts map[string]float64, path string) float64 {
+	if cost, ok := goldCosts[path]; ok {
+		return cost
+	}
+	for key, cost := range goldCosts {
+		if strings.HasSuffix(path, "."+key) {
+			return cost
+		}
+	}
+	return 0
+}
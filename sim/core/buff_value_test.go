@@ -0,0 +1,78 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestCollectBuffValueCandidates(t *testing.T) {
+	request := &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{Players: []*proto.Player{{
+				Name:     "p",
+				Buffs:    &proto.IndividualBuffs{SongflowerSerenade: true},
+				Consumes: &proto.Consumes{Flask: proto.Flask_FlaskOfSupremePower, MiscConsumes: &proto.MiscConsumes{JujuFlurry: true}},
+			}}}},
+			Buffs:   &proto.RaidBuffs{BattleShout: proto.TristateEffect_TristateEffectImproved},
+			Debuffs: &proto.Debuffs{SunderArmor: true},
+		},
+	}
+
+	candidates, err := collectBuffValueCandidates(request, false)
+	if err != nil {
+		t.Fatalf("collectBuffValueCandidates() failed: %v", err)
+	}
+
+	want := map[string]string{
+		"raid.buffs.battle_shout":                                     "TristateEffectImproved",
+		"raid.parties.0.players.0.buffs.songflower_serenade":          "true",
+		"raid.debuffs.sunder_armor":                                   "true",
+		"raid.parties.0.players.0.consumes.flask":                     "FlaskOfSupremePower",
+		"raid.parties.0.players.0.consumes.misc_consumes.juju_flurry": "true",
+	}
+	if len(candidates) != len(want) {
+		t.Fatalf("collectBuffValueCandidates() returned %d candidates, want %d: %v", len(candidates), len(want), candidates)
+	}
+	for _, candidate := range candidates {
+		if value, ok := want[candidate.path]; !ok || value != candidate.value || !candidate.removed {
+			t.Fatalf("unexpected candidate %v", candidate)
+		}
+		if err := ApplySweepValue(request, candidate.path, candidate.assign); err != nil {
+			t.Fatalf("cannot remove candidate %s: %v", candidate.path, err)
+		}
+	}
+	if request.Raid.Buffs.BattleShout != proto.TristateEffect_TristateEffectMissing || request.Raid.Parties[0].Players[0].Consumes.Flask != proto.Flask_FlaskUnknown {
+		t.Fatalf("candidates were not removed: %v", request)
+	}
+
+	candidates, err = collectBuffValueCandidates(request, true)
+	if err != nil {
+		t.Fatalf("collectBuffValueCandidates() failed: %v", err)
+	}
+	foundBattleShout := false
+	for _, candidate := range candidates {
+		if candidate.removed {
+			t.Fatalf("unexpected removal candidate %v", candidate)
+		}
+		if candidate.path == "raid.buffs.battle_shout" {
+			foundBattleShout = candidate.assign == "TristateEffectImproved"
+		}
+	}
+	if !foundBattleShout {
+		t.Fatalf("improved battle shout addition missing")
+	}
+}
+
+func TestBuffValueGoldCost(t *testing.T) {
+	costs := map[string]float64{"consumes.flask": 10, "raid.debuffs.sunder_armor": 1}
+	if cost := buffValueGoldCost(costs, "raid.parties.0.players.0.consumes.flask"); cost != 10 {
+		t.Fatalf("buffValueGoldCost() for flask = %f, want 10", cost)
+	}
+	if cost := buffValueGoldCost(costs, "raid.debuffs.sunder_armor"); cost != 1 {
+		t.Fatalf("buffValueGoldCost() for sunder = %f, want 1", cost)
+	}
+	if cost := buffValueGoldCost(costs, "raid.parties.0.players.0.consumes.misc_flask"); cost != 0 {
+		t.Fatalf("buffValueGoldCost() should only match whole path segments, got %f", cost)
+	}
+}
@@ -1758,23 +1758,31 @@ func registerInnervateCD(agent Agent, numInnervates int32) {
 		numInnervates)
 }
 
+// Innervate adds 400% spirit regen, which also continues while casting.
+const InnervateSpiritRegenBonus = 4.0
+
 func InnervateAura(character *Character, actionTag int32) *Aura {
 	actionID := ActionID{SpellID: 29166, Tag: actionTag}
-	// TODO: Add metrics for increased regen from spirit (either add here and align ticks to mana tick or create mana tick hook?)
-	// manaMetrics := character.NewManaMetrics(actionID)
+	// The increased regen from spirit is credited to these metrics by ManaTick.
+	var manaMetrics *ResourceMetrics
+	if character.HasManaBar() {
+		manaMetrics = character.NewManaMetrics(actionID)
+	}
 	return character.GetOrRegisterAura(Aura{
 		Label:    "Innervate-" + actionID.String(),
 		Tag:      InnervateAuraTag,
 		ActionID: actionID,
 		Duration: InnervateDuration,
 		OnGain: func(aura *Aura, sim *Simulation) {
-			character.PseudoStats.SpiritRegenMultiplier += 4
+			character.PseudoStats.SpiritRegenMultiplier += InnervateSpiritRegenBonus
 			character.PseudoStats.ForceFullSpiritRegen = true
+			character.innervateManaMetrics = manaMetrics
 			character.UpdateManaRegenRates()
 		},
 		OnExpire: func(aura *Aura, sim *Simulation) {
-			character.PseudoStats.SpiritRegenMultiplier -= 4
+			character.PseudoStats.SpiritRegenMultiplier -= InnervateSpiritRegenBonus
 			character.PseudoStats.ForceFullSpiritRegen = false
+			character.innervateManaMetrics = nil
 			character.UpdateManaRegenRates()
 		},
 	})
@@ -36,8 +36,16 @@ func BulkSim(signals simsignals.Signals, request *proto.BulkSimRequest, progress
 		SingleRaidSimRunner: runSim,
 		Request:             request,
 	}
+	// Open the checkpoint before running, as the run modifies the request.
+	checkpoint := openRequestCheckpoint("bulk", request)
+	if checkpoint != nil {
+		bulk.SingleRaidSimRunner = checkpoint.wrapRunner(runSim)
+	}
 
 	result := bulk.Run(signals, progress)
+	if checkpoint != nil {
+		checkpoint.finish(result.Error == nil)
+	}
 
 	if progress != nil {
 		progress <- &proto.ProgressMetrics{
@@ -50,9 +58,10 @@ func BulkSim(signals simsignals.Signals, request *proto.BulkSimRequest, progress
 }
 
 type singleBulkSim struct {
-	req *proto.RaidSimRequest
-	cl  *raidSimRequestChangeLog
-	eq  *equipmentSubstitution
+	req   *proto.RaidSimRequest
+	cl    *raidSimRequestChangeLog
+	eq    *equipmentSubstitution
+	runes *runeLoadout
 }
 
 func (b *bulkSimRunner) Run(signals simsignals.Signals, progress chan *proto.ProgressMetrics) (result *proto.BulkSimResult) {
@@ -131,18 +140,31 @@ func (b *bulkSimRunner) Run(signals simsignals.Signals, progress chan *proto.Pro
 	}
 	baseItems := player.Equipment.Items
 
+	runeLoadouts, err := generateRuneLoadouts(player, b.Request.BulkSettings.GetRunesToSim())
+	if err != nil {
+		return &proto.BulkSimResult{
+			Error: &proto.ErrorOutcome{Message: err.Error()},
+		}
+	}
+
 	allCombos := generateAllEquipmentSubstitutions(signals, baseItems, b.Request.BulkSettings.Combinations, distinctItemSlotCombos)
 
 	var validCombos []singleBulkSim
 	count := 0
 	for sub := range allCombos {
-		count++
-		if count > 1000000 {
-			panic("over 1 million combos, abandoning attempt")
-		}
-		substitutedRequest, changeLog := createNewRequestWithSubstitution(b.Request.BaseSettings, sub, b.Request.BulkSettings.AutoEnchant)
-		if isValidEquipment(substitutedRequest.Raid.Parties[0].Players[0].Equipment) {
-			validCombos = append(validCombos, singleBulkSim{req: substitutedRequest, cl: changeLog, eq: sub})
+		for _, runes := range runeLoadouts {
+			count++
+			if count > 1000000 {
+				panic("over 1 million combos, abandoning attempt")
+			}
+			substitutedRequest, changeLog := createNewRequestWithSubstitution(b.Request.BaseSettings, sub, b.Request.BulkSettings.AutoEnchant)
+			substitutedPlayer := substitutedRequest.Raid.Parties[0].Players[0]
+			if runes != nil && !runes.apply(substitutedPlayer, b.Request.BulkSettings.RuneRotations) {
+				continue
+			}
+			if isValidEquipment(substitutedPlayer.Equipment) {
+				validCombos = append(validCombos, singleBulkSim{req: substitutedRequest, cl: changeLog, eq: sub, runes: runes})
+			}
 		}
 	}
 
@@ -202,9 +224,10 @@ func (b *bulkSimRunner) Run(signals simsignals.Signals, progress chan *proto.Pro
 		rankedResults = rankedResults[:newNumCombos]
 		for i, comb := range rankedResults {
 			validCombos[i] = singleBulkSim{
-				req: comb.Request,
-				cl:  comb.ChangeLog,
-				eq:  comb.Substitution,
+				req:   comb.Request,
+				cl:    comb.ChangeLog,
+				eq:    comb.Substitution,
+				runes: comb.Runes,
 			}
 		}
 	}
@@ -227,11 +250,15 @@ func (b *bulkSimRunner) Run(signals simsignals.Signals, progress chan *proto.Pro
 	bum.Resources = nil
 	bum.Pets = nil
 
+	simRunes := len(runeLoadouts) > 1 || runeLoadouts[0] != nil
 	result = &proto.BulkSimResult{
 		EquippedGearResult: &proto.BulkComboResult{
 			UnitMetrics: bum,
 		},
 	}
+	if simRunes {
+		result.EquippedGearResult.Runes = equippedRunes(baseResult.Request.Raid.Parties[0].Players[0].Equipment)
+	}
 
 	for _, r := range rankedResults {
 		um := r.Result.GetRaidMetrics().GetParties()[0].GetPlayers()[0]
@@ -240,10 +267,14 @@ func (b *bulkSimRunner) Run(signals simsignals.Signals, progress chan *proto.Pro
 		um.Resources = nil
 		um.Pets = nil
 
-		result.Results = append(result.Results, &proto.BulkComboResult{
+		comboResult := &proto.BulkComboResult{
 			ItemsAdded:  r.ChangeLog.AddedItems,
 			UnitMetrics: um,
-		})
+		}
+		if simRunes {
+			comboResult.Runes = equippedRunes(r.Request.Raid.Parties[0].Players[0].Equipment)
+		}
+		result.Results = append(result.Results, comboResult)
 	}
 
 	if progress != nil {
@@ -323,6 +354,7 @@ func (b *bulkSimRunner) getRankedResults(signals simsignals.Signals, validCombos
 					Result:       b.SingleRaidSimRunner(sub.req, singleSimProgress, false, signals),
 					Substitution: sub.eq,
 					ChangeLog:    sub.cl,
+					Runes:        sub.runes,
 				}
 				atomic.AddInt32(&totalCompletedSims, 1)
 				tickets <- struct{}{} // when done, allow for new sim to be launched.
@@ -339,7 +371,7 @@ func (b *bulkSimRunner) getRankedResults(signals simsignals.Signals, validCombos
 			reporterSignal.Abort.Trigger() // cancel reporter
 			return nil, nil, result.Result.Error
 		}
-		if !result.Substitution.HasItemReplacements() {
+		if !result.Substitution.HasItemReplacements() && result.Runes.IsBase() {
 			baseResult = result
 		}
 		rankedResults[i] = result
@@ -360,6 +392,7 @@ type itemSubstitutionSimResult struct {
 	Result       *proto.RaidSimResult
 	Substitution *equipmentSubstitution
 	ChangeLog    *raidSimRequestChangeLog
+	Runes        *runeLoadout
 }
 
 // Score used to rank results.
@@ -0,0 +1,170 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+const maxRuneLoadouts = 10000
+
+// runeLoadout specifies the runes of the rune slots with candidate runes, for one combination of a bulk sim.
+type runeLoadout struct {
+	Runes map[proto.ItemSlot]int32
+
+	// True for the runes the player has equipped.
+	isBase bool
+}
+
+func (rl *runeLoadout) IsBase() bool {
+	return rl == nil || rl.isBase
+}
+
+// generateRuneLoadouts returns all combinations of the candidate runes and the equipped runes in the
+// rune slots of their type, starting with the equipped runes. Returns a single nil loadout if there are
+// no candidates, so the equipped runes are kept as they are.
+func generateRuneLoadouts(player *proto.Player, candidates []*proto.BulkRune) ([]*runeLoadout, error) {
+	var types []proto.ItemType
+	runesByType := make(map[proto.ItemType][]int32)
+	for _, candidate := range candidates {
+		if candidate.RequiresLevel > player.Level {
+			continue
+		}
+		if len(candidate.ClassAllowlist) > 0 && !slices.Contains(candidate.ClassAllowlist, player.Class) {
+			continue
+		}
+		if _, ok := itemTypeToSlotsMap[candidate.Type]; !ok {
+			return nil, fmt.Errorf("rune %d has no rune slot for type %s", candidate.Id, candidate.Type)
+		}
+		if _, ok := runesByType[candidate.Type]; !ok {
+			types = append(types, candidate.Type)
+		}
+		if !slices.Contains(runesByType[candidate.Type], candidate.Id) {
+			runesByType[candidate.Type] = append(runesByType[candidate.Type], candidate.Id)
+		}
+	}
+	if len(types) == 0 {
+		return []*runeLoadout{nil}, nil
+	}
+
+	loadouts := []*runeLoadout{{Runes: make(map[proto.ItemSlot]int32), isBase: true}}
+	for _, itemType := range types {
+		slots := itemTypeToSlotsMap[itemType]
+		equipped := make([]int32, len(slots))
+		for i, slot := range slots {
+			equipped[i] = player.GetEquipment().GetItems()[slot].GetRune()
+		}
+		options := runeSlotOptions(equipped, runesByType[itemType])
+
+		if len(loadouts)*len(options) > maxRuneLoadouts {
+			return nil, fmt.Errorf("too many rune combinations, more than %d", maxRuneLoadouts)
+		}
+		var newLoadouts []*runeLoadout
+		for _, loadout := range loadouts {
+			for i, option := range options {
+				newLoadout := &runeLoadout{
+					Runes:  make(map[proto.ItemSlot]int32, len(loadout.Runes)+len(slots)),
+					isBase: loadout.isBase && i == 0,
+				}
+				for slot, runeID := range loadout.Runes {
+					newLoadout.Runes[slot] = runeID
+				}
+				for j, slot := range slots {
+					newLoadout.Runes[slot] = option[j]
+				}
+				newLoadouts = append(newLoadouts, newLoadout)
+			}
+		}
+		loadouts = newLoadouts
+	}
+	return loadouts, nil
+}
+
+// runeSlotOptions returns the runes for slots of the same type, like both rings, starting with the equipped runes.
+// Each distinct set of as many runes as there are slots is used once. Equipped runes stay in their slot.
+func runeSlotOptions(equipped []int32, candidates []int32) [][]int32 {
+	for _, runeID := range equipped {
+		if runeID != 0 && !slices.Contains(candidates, runeID) {
+			candidates = append(candidates, runeID)
+		}
+	}
+
+	options := [][]int32{equipped}
+	for _, subset := range subsets(candidates, min(len(equipped), len(candidates))) {
+		option := make([]int32, len(equipped))
+		var rest []int32
+		for _, runeID := range subset {
+			if i := slices.Index(equipped, runeID); i >= 0 {
+				option[i] = runeID
+			} else {
+				rest = append(rest, runeID)
+			}
+		}
+		for i := range option {
+			if option[i] == 0 && len(rest) > 0 {
+				option[i], rest = rest[0], rest[1:]
+			}
+		}
+		if !slices.ContainsFunc(options, func(o []int32) bool { return slices.Equal(o, option) }) {
+			options = append(options, option)
+		}
+	}
+	return options
+}
+
+// Returns all subsets of the values with the given size, keeping their order.
+func subsets(values []int32, size int) [][]int32 {
+	if size == 0 {
+		return [][]int32{nil}
+	}
+	var result [][]int32
+	for i := 0; i+size <= len(values); i++ {
+		for _, rest := range subsets(values[i+1:], size-1) {
+			result = append(result, append([]int32{values[i]}, rest...))
+		}
+	}
+	return result
+}
+
+// apply sets the runes of the loadout on the items of the player, and the first rotation whose runes are all
+// equipped. Returns false if a rune would be on an empty slot.
+func (rl *runeLoadout) apply(player *proto.Player, rotations []*proto.RuneRotation) bool {
+	items := player.Equipment.Items
+	for slot, runeID := range rl.Runes {
+		if items[slot].GetRune() == runeID {
+			continue
+		}
+		if items[slot].GetId() == 0 {
+			return false
+		}
+		// Items may be shared with the bulk settings, so never change them in place.
+		items[slot] = goproto.Clone(items[slot]).(*proto.ItemSpec)
+		items[slot].Rune = runeID
+	}
+
+	equipped := make(map[int32]bool)
+	for _, r := range equippedRunes(player.Equipment) {
+		equipped[r.RuneId] = true
+	}
+	for _, rotation := range rotations {
+		if !slices.ContainsFunc(rotation.RuneIds, func(runeID int32) bool { return !equipped[runeID] }) {
+			player.Rotation = rotation.Rotation
+			break
+		}
+	}
+	return true
+}
+
+// Returns the runes of the equipment, in order of their slots.
+func equippedRunes(equipment *proto.EquipmentSpec) []*proto.RuneWithSlot {
+	var runes []*proto.RuneWithSlot
+	for slot, item := range equipment.GetItems() {
+		if item.GetRune() != 0 {
+			runes = append(runes, &proto.RuneWithSlot{RuneId: item.Rune, Slot: proto.ItemSlot(slot)})
+		}
+	}
+	return runes
+}
@@ -1,12 +1,14 @@
 package core
 
 import (
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/wowsims/classic/sim/core/proto"
 	"github.com/wowsims/classic/sim/core/simsignals"
 	"google.golang.org/protobuf/encoding/protojson"
+	goproto "google.golang.org/protobuf/proto"
 )
 
 const (
@@ -380,3 +382,52 @@ func TestGenerateAllEquipmentSubstitutions(t *testing.T) {
 		})
 	}
 }
+
+func TestGenerateRuneLoadouts(t *testing.T) {
+	player := &proto.Player{
+		Class:     proto.Class_ClassWarrior,
+		Level:     40,
+		Equipment: &proto.EquipmentSpec{Items: make([]*proto.ItemSpec, len(proto.ItemSlot_name))},
+	}
+	for i := range player.Equipment.Items {
+		player.Equipment.Items[i] = &proto.ItemSpec{Id: int32(i) + 1000}
+	}
+	player.Equipment.Items[proto.ItemSlot_ItemSlotChest].Rune = 1
+	player.Equipment.Items[proto.ItemSlot_ItemSlotFinger2].Rune = 10
+
+	loadouts, err := generateRuneLoadouts(player, []*proto.BulkRune{
+		{Id: 2, Type: proto.ItemType_ItemTypeChest},
+		{Id: 3, Type: proto.ItemType_ItemTypeChest, RequiresLevel: 50},
+		{Id: 4, Type: proto.ItemType_ItemTypeChest, ClassAllowlist: []proto.Class{proto.Class_ClassMage}},
+		{Id: 11, Type: proto.ItemType_ItemTypeFinger},
+		{Id: 12, Type: proto.ItemType_ItemTypeFinger, ClassAllowlist: []proto.Class{proto.Class_ClassWarrior}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for _, loadout := range loadouts {
+		got = append(got, fmt.Sprintf("%v %d %d %d", loadout.IsBase(),
+			loadout.Runes[proto.ItemSlot_ItemSlotChest], loadout.Runes[proto.ItemSlot_ItemSlotFinger1], loadout.Runes[proto.ItemSlot_ItemSlotFinger2]))
+	}
+	want := []string{
+		"true 1 0 10", "false 1 11 12", "false 1 11 10", "false 1 12 10",
+		"false 2 0 10", "false 2 11 12", "false 2 11 10", "false 2 12 10",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("generateRuneLoadouts() returned diff (-want +got):\n%s", diff)
+	}
+
+	rotation := &proto.APLRotation{}
+	request := &proto.Player{Equipment: goproto.Clone(player.Equipment).(*proto.EquipmentSpec)}
+	if !loadouts[4].apply(request, []*proto.RuneRotation{{RuneIds: []int32{2, 11}}, {RuneIds: []int32{2}, Rotation: rotation}}) {
+		t.Fatalf("Expected runes to apply")
+	}
+	if request.Equipment.Items[proto.ItemSlot_ItemSlotChest].Rune != 2 || player.Equipment.Items[proto.ItemSlot_ItemSlotChest].Rune != 1 {
+		t.Fatalf("Expected rune on a copy of the chest, got %v", request.Equipment.Items[proto.ItemSlot_ItemSlotChest])
+	}
+	if request.Rotation != rotation {
+		t.Fatalf("Expected the rotation for the chest rune")
+	}
+}
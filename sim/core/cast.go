@@ -15,6 +15,7 @@ type OnCastComplete func(aura *Aura, sim *Simulation, spell *Spell)
 type Hardcast struct {
 	Expires    time.Duration
 	ActionID   ActionID
+	Spell      *Spell
 	OnComplete func(*Simulation, *Unit)
 	Target     *Unit
 	Pushback   float64
@@ -211,6 +212,10 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			return spell.castFailureHelper(sim, "channeling %v for %s, curTime = %s", dot.ActionID, dot.expires-sim.CurrentTime, sim.CurrentTime)
 		}
 
+		if spell.Unit.IsSchoolLockedOut(sim, spell.SpellSchool) {
+			return spell.castFailureHelper(sim, "spell school locked out for %s, curTime = %s", spell.Unit.interruptLockoutExpires-sim.CurrentTime, sim.CurrentTime)
+		}
+
 		if effectiveTime := spell.CurCast.EffectiveTime(); effectiveTime != 0 {
 			if spell.Flags.Matches(SpellFlagCastTimeNoGCD) {
 				effectiveTime = max(effectiveTime, spell.Unit.GCD.TimeToReady(sim))
@@ -273,6 +278,7 @@ func (spell *Spell) makeCastFunc(config CastConfig) CastSuccessFunc {
 			spell.Unit.Hardcast = Hardcast{
 				Expires:  sim.CurrentTime + spell.CurCast.CastTime,
 				ActionID: spell.ActionID,
+				Spell:    spell,
 				Pushback: 1.0,
 				OnComplete: func(sim *Simulation, target *Unit) {
 					spell.LastCastAt = sim.CurrentTime
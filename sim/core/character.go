@@ -44,7 +44,8 @@ type Character struct {
 	// Current gear.
 	Equipment
 	//Item Swap Handler
-	ItemSwap ItemSwap
+	ItemSwap     ItemSwap
+	ItemSwapSets ItemSwapSets
 
 	// Consumables this Character will be using.
 	Consumes *proto.Consumes
@@ -73,6 +74,9 @@ type Character struct {
 	runesMap          map[int32]bool
 	PrimaryTalentTree uint8
 
+	// Whether a resurrection is on its way while the Character is dead.
+	resurrecting bool
+
 	// Provides major cooldown management behavior.
 	majorCooldownManager
 
@@ -200,6 +204,13 @@ func NewCharacter(party *Party, partyIndex int, player *proto.Player) Character
 	if player.EnableItemSwap && player.ItemSwap != nil {
 		character.enableItemSwap(player.ItemSwap)
 	}
+	if len(player.ItemSwapSets) > 0 {
+		character.enableItemSwapSets(player.ItemSwapSets)
+	}
+
+	if player.ExecutionModel.GetEnabled() {
+		character.executionModel = newExecutionModel(player.ExecutionModel)
+	}
 
 	return character
 }
@@ -373,9 +384,15 @@ func (character *Character) clearBuildPhaseAuras(phase CharacterBuildPhase) {
 
 // Apply effects from all equipped core.
 func (character *Character) applyItemEffects(agent Agent) {
+	character.ItemSwapSets.initialize(character)
+
 	for slot, eq := range character.Equipment {
 		if applyItemEffect, ok := itemEffects[eq.ID]; ok {
-			applyItemEffect(agent)
+			if character.ItemSwapSets.IsEnabled() && slices.Contains(character.ItemSwapSets.slots, proto.ItemSlot(slot)) {
+				character.ItemSwapSets.trackItemEffects(eq.ID, func() { applyItemEffect(agent) })
+			} else {
+				applyItemEffect(agent)
+			}
 		}
 
 		if applyEnchantEffect, ok := enchantEffects[eq.Enchant.EffectID]; ok {
@@ -399,6 +416,10 @@ func (character *Character) applyItemEffects(agent Agent) {
 			}
 		}
 	}
+
+	if character.ItemSwapSets.IsEnabled() {
+		character.ItemSwapSets.applyItemEffects(agent)
+	}
 }
 
 func (character *Character) AddPet(pet PetAgent) {
@@ -429,6 +450,7 @@ func (character *Character) AddPartyBuffs(partyBuffs *proto.PartyBuffs) {
 func (character *Character) initialize(agent Agent) {
 	character.majorCooldownManager.initialize(character)
 	character.ItemSwap.initialize(character)
+	character.ItemSwapSets.initialize(character)
 
 	character.gcdAction = &PendingAction{
 		Priority: ActionPriorityGCD,
@@ -497,10 +519,13 @@ func (character *Character) FillPlayerStats(playerStats *proto.PlayerStats) {
 }
 
 func (character *Character) reset(sim *Simulation, agent Agent) {
+	character.ItemSwapSets.reset(sim)
 	character.Unit.reset(sim, agent)
+	character.ItemSwapSets.deactivateUnequippedEffects(sim)
 	character.majorCooldownManager.reset(sim)
 	character.ItemSwap.reset(sim)
 	character.CurrentTarget = character.defaultTarget
+	character.resurrecting = false
 
 	agent.Reset(sim)
 
@@ -651,10 +676,17 @@ func (character *Character) GetMetricsProto() *proto.UnitMetrics {
 	metrics.Name = character.Name
 	metrics.UnitIndex = character.UnitIndex
 	metrics.Auras = character.auraTracker.GetMetricsProto()
+	if character.HasManaBar() {
+		metrics.Mana = character.manaMetricsToProto(metrics.SecondsOomAvg)
+	}
 
 	metrics.Pets = make([]*proto.UnitMetrics, len(character.Pets))
 	for i, pet := range character.Pets {
 		metrics.Pets[i] = pet.GetMetricsProto()
+		if metrics.TimeSeries != nil {
+			addPetTimeSeries(metrics.TimeSeries, metrics.Pets[i].TimeSeries)
+		}
+		addPetPhases(metrics.Phases, metrics.Pets[i].Phases)
 	}
 
 	return metrics
@@ -0,0 +1,195 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	goproto "google.golang.org/protobuf/proto"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+// How often completed results are written to the checkpoint file.
+const checkpointInterval = 10 * time.Second
+
+var checkpointDir string
+var resumeCheckpoints bool
+
+// Makes bulk sims and stat weights write their completed sims to a checkpoint file in dir, named after
+// the request. If resume is set, a run continues from the checkpoint of an earlier run of the same
+// request. That only gives the same results as an uninterrupted run if the request has a fixed random
+// seed, as requests without one get a new seed for every run. Checkpoints are removed once their run
+// completes. An empty dir disables checkpoints, which is the default.
+func EnableCheckpoints(dir string, resume bool) {
+	checkpointDir = dir
+	resumeCheckpoints = resume
+}
+
+// Results of the sims completed by a long running request, see EnableCheckpoints.
+type Checkpoint struct {
+	path string
+
+	mu        sync.Mutex
+	data      *proto.SimCheckpoint
+	lastSaved time.Time
+	dirty     bool
+}
+
+// Opens the checkpoint of the request in the checkpoint directory, or returns nil if checkpoints are disabled.
+func openRequestCheckpoint(kind string, request goproto.Message) *Checkpoint {
+	if checkpointDir == "" {
+		return nil
+	}
+	requestHash := hashRequest(request)
+	checkpoint, err := OpenCheckpoint(filepath.Join(checkpointDir, fmt.Sprintf("%s-%s.checkpoint", kind, requestHash[:16])), requestHash, resumeCheckpoints)
+	if err != nil {
+		// A run without checkpoints is better than none.
+		log.Printf("Failed to open checkpoint, continuing without: %s", err)
+		return nil
+	}
+	return checkpoint
+}
+
+// Opens the checkpoint file at path for the request with the given hash. If resume is set and the file
+// holds a checkpoint of the same request, its results are reused. Otherwise, the run starts over.
+func OpenCheckpoint(path string, requestHash string, resume bool) (*Checkpoint, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+		return nil, err
+	}
+
+	checkpoint := &Checkpoint{
+		path:      path,
+		data:      &proto.SimCheckpoint{RequestHash: requestHash, Results: make(map[string]*proto.RaidSimResult)},
+		lastSaved: time.Now(),
+	}
+	if !resume {
+		return checkpoint, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return checkpoint, nil
+	} else if err != nil {
+		return nil, err
+	}
+	saved := &proto.SimCheckpoint{}
+	if err := goproto.Unmarshal(data, saved); err != nil {
+		return nil, fmt.Errorf("invalid checkpoint %s: %w", path, err)
+	}
+	if saved.RequestHash == requestHash && saved.Results != nil {
+		checkpoint.data = saved
+	}
+	return checkpoint, nil
+}
+
+// Number of completed sims in the checkpoint.
+func (c *Checkpoint) NumResults() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.data.Results)
+}
+
+func (c *Checkpoint) result(requestHash string) *proto.RaidSimResult {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if result, ok := c.data.Results[requestHash]; ok {
+		return goproto.Clone(result).(*proto.RaidSimResult)
+	}
+	return nil
+}
+
+func (c *Checkpoint) addResult(requestHash string, result *proto.RaidSimResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.data.Results[requestHash] = goproto.Clone(result).(*proto.RaidSimResult)
+	c.dirty = true
+	if time.Since(c.lastSaved) >= checkpointInterval {
+		c.save()
+	}
+}
+
+// Writes the completed results to the checkpoint file, if there are any new ones.
+func (c *Checkpoint) Save() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.save()
+}
+
+func (c *Checkpoint) save() {
+	if !c.dirty {
+		return
+	}
+	data, err := goproto.MarshalOptions{Deterministic: true}.Marshal(c.data)
+	if err != nil {
+		log.Printf("Failed to marshal checkpoint: %s", err)
+		return
+	}
+	// Write to a temporary file first, so a crash while writing doesn't lose the previous checkpoint.
+	tmpPath := c.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0666); err != nil {
+		log.Printf("Failed to write checkpoint: %s", err)
+		return
+	}
+	if err := os.Rename(tmpPath, c.path); err != nil {
+		log.Printf("Failed to write checkpoint: %s", err)
+		return
+	}
+	c.lastSaved = time.Now()
+	c.dirty = false
+}
+
+// Finishes the run of the checkpoint. Completed runs have no more use for their checkpoint, so it's
+// removed, while interrupted or failed runs save it to be resumed.
+func (c *Checkpoint) finish(completed bool) {
+	if !completed {
+		c.Save()
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := os.Remove(c.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		log.Printf("Failed to remove checkpoint: %s", err)
+	}
+}
+
+// Returns a runner which reuses the results of the checkpoint, and adds new successful results to it.
+func (c *Checkpoint) wrapRunner(runner raidSimRunner) raidSimRunner {
+	return func(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool, signals simsignals.Signals) *proto.RaidSimResult {
+		requestHash := hashRequest(rsr)
+		if result := c.result(requestHash); result != nil {
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{
+					TotalIterations:     rsr.SimOptions.Iterations,
+					CompletedIterations: rsr.SimOptions.Iterations,
+					FinalRaidResult:     result,
+				}
+				close(progress)
+			}
+			return result
+		}
+
+		result := runner(rsr, progress, skipPresim, signals)
+		if result != nil && result.Error == nil {
+			c.addResult(requestHash, result)
+		}
+		return result
+	}
+}
+
+func hashRequest(request goproto.Message) string {
+	data, err := goproto.MarshalOptions{Deterministic: true}.Marshal(request)
+	if err != nil {
+		panic(err)
+	}
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
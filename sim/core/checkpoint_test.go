@@ -0,0 +1,75 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	goproto "google.golang.org/protobuf/proto"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bulk.checkpoint")
+
+	numRuns := 0
+	fakeRunSim := func(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, skipPresim bool, signals simsignals.Signals) *proto.RaidSimResult {
+		numRuns++
+		if rsr.SimOptions.Iterations == 0 {
+			return &proto.RaidSimResult{Error: &proto.ErrorOutcome{Message: "failed"}}
+		}
+		return &proto.RaidSimResult{AvgIterationDuration: float64(rsr.SimOptions.Iterations), IterationsDone: int32(numRuns)}
+	}
+	requests := make([]*proto.RaidSimRequest, 4)
+	for i := range requests {
+		requests[i] = &proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: int32(i), RandomSeed: 1}}
+	}
+	runAll := func(checkpoint *Checkpoint) []*proto.RaidSimResult {
+		runner := checkpoint.wrapRunner(fakeRunSim)
+		results := make([]*proto.RaidSimResult, len(requests))
+		for i, rsr := range requests {
+			results[i] = runner(rsr, nil, false, simsignals.CreateSignals())
+		}
+		return results
+	}
+
+	checkpoint, err := OpenCheckpoint(path, "request", true)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+	firstResults := runAll(checkpoint)
+	checkpoint.finish(false)
+
+	// Only successful results are kept, so the failed sim is run again.
+	numRuns = 0
+	checkpoint, err = OpenCheckpoint(path, "request", true)
+	if err != nil {
+		t.Fatalf("Failed to open checkpoint: %v", err)
+	}
+	if checkpoint.NumResults() != 3 {
+		t.Fatalf("Expected 3 results in the checkpoint, got %d", checkpoint.NumResults())
+	}
+	resumedResults := runAll(checkpoint)
+	if numRuns != 1 {
+		t.Fatalf("Expected 1 sim to be run after resuming, got %d", numRuns)
+	}
+	for i := 1; i < len(requests); i++ {
+		if !goproto.Equal(firstResults[i], resumedResults[i]) {
+			t.Fatalf("Expected resumed result %v, got %v", firstResults[i], resumedResults[i])
+		}
+	}
+
+	// Checkpoints of other requests aren't resumed.
+	if checkpoint, _ := OpenCheckpoint(path, "other request", true); checkpoint.NumResults() != 0 {
+		t.Fatalf("Expected checkpoint of another request to be discarded, got %d results", checkpoint.NumResults())
+	}
+	if checkpoint, _ := OpenCheckpoint(path, "request", false); checkpoint.NumResults() != 0 {
+		t.Fatalf("Expected checkpoint to be discarded without resume, got %d results", checkpoint.NumResults())
+	}
+
+	checkpoint.finish(true)
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatalf("Expected checkpoint to be removed once the run completed, got %v", err)
+	}
+}
@@ -0,0 +1,341 @@
+package core
+
+import (
+	"fmt"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const (
+	cooldownOptimizerDefaultStep   = time.Second * 5
+	cooldownOptimizerDefaultRounds = 3
+)
+
+// One or more major cooldowns whose timings are optimized together.
+type cooldownOptimizerTarget struct {
+	ids      []ActionID
+	cooldown time.Duration
+
+	// Current timings, nil if the cooldowns are used whenever they are ready.
+	strategy string
+	timings  []time.Duration
+}
+
+type cooldownTimingCandidate struct {
+	strategy string
+	timings  []time.Duration
+}
+
+// Searches the activation timings of the first player's major cooldowns, one cooldown (or
+// alignment group) at a time while keeping the others fixed, until no change improves DPS.
+// All sims share the same seed and labeled rands, so small differences are not drowned out by noise.
+func runCooldownOptimizer(request *proto.CooldownOptimizerRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) (result *proto.CooldownOptimizerResult) {
+	defer func() {
+		if err := recover(); err != nil {
+			result = &proto.CooldownOptimizerResult{
+				Error: &proto.ErrorOutcome{Message: fmt.Sprintf("%v\nStack Trace:\n%s", err, string(debug.Stack()))},
+			}
+		}
+	}()
+
+	if request.BaseSettings == nil || request.BaseSettings.Raid == nil || request.BaseSettings.SimOptions == nil || request.BaseSettings.Encounter == nil {
+		return &proto.CooldownOptimizerResult{Error: &proto.ErrorOutcome{Message: "cooldown optimizer: no base settings given"}}
+	}
+
+	baseRequest := googleProto.Clone(request.BaseSettings).(*proto.RaidSimRequest)
+	baseRequest.SimOptions.SaveAllValues = true
+	baseRequest.SimOptions.UseLabeledRands = true
+	if baseRequest.SimOptions.RandomSeed == 0 {
+		baseRequest.SimOptions.RandomSeed = time.Now().UnixNano()
+	}
+
+	player, targets, err := findCooldownOptimizerTargets(baseRequest, request)
+	if err != nil {
+		return &proto.CooldownOptimizerResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+	}
+
+	step := TernaryDuration(request.StepSeconds > 0, DurationFromSeconds(request.StepSeconds), cooldownOptimizerDefaultStep)
+	maxRounds := TernaryInt(request.MaxRounds > 0, int(request.MaxRounds), cooldownOptimizerDefaultRounds)
+	duration := DurationFromSeconds(baseRequest.Encounter.Duration)
+	executeStart := time.Duration(0)
+	if proportion := baseRequest.Encounter.ExecuteProportion_20; proportion > 0 && proportion < 1 {
+		executeStart = DurationFromSeconds(baseRequest.Encounter.Duration * (1 - proportion))
+	}
+
+	simsRun := int32(0)
+	runSims := func(requests []*proto.RaidSimRequest) ([]*proto.RaidSimResult, *proto.ErrorOutcome) {
+		simsRun += int32(len(requests))
+		return runRaidSimBatch(requests, progress, signals)
+	}
+
+	baseResults, errorOutcome := runSims([]*proto.RaidSimRequest{baseRequest})
+	if errorOutcome != nil {
+		return &proto.CooldownOptimizerResult{Error: errorOutcome}
+	}
+	baseResult := baseResults[0]
+	bestResult := baseResult
+
+	for round := 0; round < maxRounds; round++ {
+		improved := false
+		for _, target := range targets {
+			candidates := cooldownTimingCandidates(target, targets, step, duration, executeStart)
+			if len(candidates) == 0 {
+				continue
+			}
+
+			requests := make([]*proto.RaidSimRequest, len(candidates))
+			for i, candidate := range candidates {
+				requests[i] = googleProto.Clone(baseRequest).(*proto.RaidSimRequest)
+				setOptimizedCooldowns(findRequestPlayer(requests[i], player), targets, target, candidate)
+			}
+			results, errorOutcome := runSims(requests)
+			if errorOutcome != nil {
+				return &proto.CooldownOptimizerResult{Error: errorOutcome}
+			}
+
+			bestIdx := -1
+			// Alignment groups are not aligned in the base settings, so one of the candidates is always picked.
+			bestDps := TernaryFloat64(target.strategy == "", -1, FirstPlayerMetrics(bestResult).Dps.Avg)
+			for i, result := range results {
+				if dps := FirstPlayerMetrics(result).Dps.Avg; dps > bestDps {
+					bestIdx, bestDps = i, dps
+				}
+			}
+			if bestIdx == -1 {
+				continue
+			}
+
+			target.strategy = candidates[bestIdx].strategy
+			target.timings = candidates[bestIdx].timings
+			bestResult = results[bestIdx]
+			improved = true
+		}
+		if !improved {
+			break
+		}
+	}
+
+	basePlayer, bestPlayer := FirstPlayerMetrics(baseResult), FirstPlayerMetrics(bestResult)
+	var diffs aggregator
+	for i := range basePlayer.Dps.AllValues {
+		diffs.add(bestPlayer.Dps.AllValues[i] - basePlayer.Dps.AllValues[i])
+	}
+	_, gainStdev := diffs.meanAndStdDev()
+
+	bestRequest := googleProto.Clone(baseRequest).(*proto.RaidSimRequest)
+	bestPlayerProto := findRequestPlayer(bestRequest, player)
+	setOptimizedCooldowns(bestPlayerProto, targets, nil, cooldownTimingCandidate{})
+
+	result = &proto.CooldownOptimizerResult{
+		BaseDps:       basePlayer.Dps.Avg,
+		BestDps:       bestPlayer.Dps.Avg,
+		DpsGain:       bestPlayer.Dps.Avg - basePlayer.Dps.Avg,
+		DpsGainStdev:  gainStdev,
+		BestCooldowns: bestPlayerProto.Cooldowns,
+		SimsRun:       simsRun,
+	}
+	for _, target := range targets {
+		for _, id := range target.ids {
+			result.Timings = append(result.Timings, &proto.CooldownTimingResult{
+				Id:              id.ToProto(),
+				Strategy:        target.strategy,
+				Timings:         MapSlice(target.timings, time.Duration.Seconds),
+				CooldownSeconds: target.cooldown.Seconds(),
+			})
+		}
+	}
+	return result
+}
+
+// Identifies the first player of a request by its position in the raid.
+type requestPlayerIndex struct {
+	party  int
+	player int
+}
+
+func findRequestPlayer(request *proto.RaidSimRequest, index requestPlayerIndex) *proto.Player {
+	return request.Raid.Parties[index.party].Players[index.player]
+}
+
+// Constructs the environment of the request, to find the major cooldowns the first player actually has.
+func findCooldownOptimizerTargets(baseRequest *proto.RaidSimRequest, request *proto.CooldownOptimizerRequest) (requestPlayerIndex, []*cooldownOptimizerTarget, error) {
+	env, _, _ := NewEnvironment(googleProto.Clone(baseRequest.Raid).(*proto.Raid), googleProto.Clone(baseRequest.Encounter).(*proto.Encounter), false)
+
+	var character *Character
+	for _, party := range env.Raid.Parties {
+		if len(party.Players) > 0 && character == nil {
+			character = party.Players[0].GetCharacter()
+		}
+	}
+	if character == nil {
+		return requestPlayerIndex{}, nil, fmt.Errorf("cooldown optimizer: no player in raid")
+	}
+	index := requestPlayerIndex{party: character.Party.Index, player: character.PartyIndex}
+	playerProto := findRequestPlayer(baseRequest, index)
+
+	findMCD := func(id ActionID) *MajorCooldown {
+		for i := range character.initialMajorCooldowns {
+			if mcd := &character.initialMajorCooldowns[i]; mcd.Spell.SameAction(id) {
+				return mcd
+			}
+		}
+		return nil
+	}
+	cooldownOf := func(mcd *MajorCooldown) time.Duration {
+		return max(mcd.Spell.CD.Duration, mcd.Spell.SharedCD.Duration)
+	}
+
+	var ids []ActionID
+	if len(request.Cooldowns) > 0 {
+		ids = MapSlice(request.Cooldowns, ProtoToActionID)
+	} else {
+		for _, mcd := range character.initialMajorCooldowns {
+			if !mcd.Type.Matches(CooldownTypeSurvival) && cooldownOf(&mcd) > 0 {
+				ids = append(ids, mcd.Spell.ActionID)
+			}
+		}
+	}
+
+	var targets []*cooldownOptimizerTarget
+	grouped := make(map[ActionID]bool)
+	for _, group := range request.AlignmentGroups {
+		target := &cooldownOptimizerTarget{}
+		for _, protoID := range group.Cooldowns {
+			id := ProtoToActionID(protoID)
+			mcd := findMCD(id)
+			if mcd == nil {
+				return index, nil, fmt.Errorf("cooldown optimizer: %s is not a major cooldown of %s", id, character.Name)
+			}
+			if cooldownOf(mcd) <= 0 {
+				return index, nil, fmt.Errorf("cooldown optimizer: %s has no cooldown to optimize", id)
+			}
+			target.ids = append(target.ids, id)
+			target.cooldown = max(target.cooldown, cooldownOf(mcd))
+			grouped[id] = true
+		}
+		if len(target.ids) > 0 {
+			targets = append(targets, target)
+		}
+	}
+	for _, id := range ids {
+		if grouped[id] {
+			continue
+		}
+		mcd := findMCD(id)
+		if mcd == nil {
+			return index, nil, fmt.Errorf("cooldown optimizer: %s is not a major cooldown of %s", id, character.Name)
+		}
+		if cooldownOf(mcd) <= 0 {
+			return index, nil, fmt.Errorf("cooldown optimizer: %s has no cooldown to optimize", id)
+		}
+
+		target := &cooldownOptimizerTarget{
+			ids:      []ActionID{id},
+			cooldown: cooldownOf(mcd),
+			strategy: "when ready",
+		}
+		for _, config := range playerProto.GetCooldowns().GetCooldowns() {
+			if config.Id != nil && ProtoToActionID(config.Id).SameAction(id) && len(config.Timings) > 0 {
+				target.strategy = "configured"
+				target.timings = MapSlice(config.Timings, DurationFromSeconds)
+			}
+		}
+		targets = append(targets, target)
+	}
+
+	if len(targets) == 0 {
+		return index, nil, fmt.Errorf("cooldown optimizer: %s has no major cooldowns to optimize", character.Name)
+	}
+	return index, targets, nil
+}
+
+// Returns the timings to try for the target, other than its current ones.
+func cooldownTimingCandidates(target *cooldownOptimizerTarget, targets []*cooldownOptimizerTarget, step time.Duration, duration time.Duration, executeStart time.Duration) []cooldownTimingCandidate {
+	var candidates []cooldownTimingCandidate
+	add := func(strategy string, timings []time.Duration) {
+		if target.strategy != "" && slices.Equal(timings, target.timings) {
+			return
+		}
+		for _, candidate := range candidates {
+			if slices.Equal(candidate.timings, timings) {
+				return
+			}
+		}
+		candidates = append(candidates, cooldownTimingCandidate{strategy: strategy, timings: timings})
+	}
+
+	// Cooldowns of an alignment group are only used together, so they are always scheduled.
+	if len(target.ids) == 1 {
+		add("when ready", nil)
+	} else {
+		add("together from the pull", cooldownSchedule(0, target.cooldown, duration))
+	}
+
+	for delay := step; delay < min(target.cooldown, duration); delay += step {
+		add(fmt.Sprintf("first use at %s", delay), cooldownSchedule(delay, target.cooldown, duration))
+	}
+
+	if executeStart > 0 && target.cooldown > 0 {
+		var timings []time.Duration
+		for timing := executeStart; timing >= 0; timing -= target.cooldown {
+			timings = append(timings, timing)
+		}
+		slices.Reverse(timings)
+		add("aligned to execute", timings)
+	}
+
+	for _, other := range targets {
+		if other != target && other.timings != nil {
+			add(fmt.Sprintf("aligned with %s", other.ids[0]), other.timings)
+		}
+	}
+
+	return candidates
+}
+
+// Returns the timings of every use, starting at the given time.
+func cooldownSchedule(start time.Duration, cooldown time.Duration, duration time.Duration) []time.Duration {
+	timings := []time.Duration{start}
+	for timing := start + cooldown; cooldown > 0 && timing < duration; timing += cooldown {
+		timings = append(timings, timing)
+	}
+	return timings
+}
+
+// Replaces the player's settings for all optimized cooldowns, using the candidate for the changed target.
+func setOptimizedCooldowns(player *proto.Player, targets []*cooldownOptimizerTarget, changed *cooldownOptimizerTarget, candidate cooldownTimingCandidate) {
+	cooldowns := &proto.Cooldowns{}
+	if player.Cooldowns != nil {
+		cooldowns.HpPercentForDefensives = player.Cooldowns.HpPercentForDefensives
+		for _, config := range player.Cooldowns.Cooldowns {
+			isTarget := slices.ContainsFunc(targets, func(target *cooldownOptimizerTarget) bool {
+				return config.Id != nil && slices.ContainsFunc(target.ids, ProtoToActionID(config.Id).SameAction)
+			})
+			if !isTarget {
+				cooldowns.Cooldowns = append(cooldowns.Cooldowns, config)
+			}
+		}
+	}
+
+	for _, target := range targets {
+		timings := target.timings
+		if target == changed {
+			timings = candidate.timings
+		}
+		if len(timings) == 0 {
+			continue
+		}
+		for _, id := range target.ids {
+			cooldowns.Cooldowns = append(cooldowns.Cooldowns, &proto.Cooldown{
+				Id:      id.ToProto(),
+				Timings: MapSlice(timings, time.Duration.Seconds),
+			})
+		}
+	}
+	player.Cooldowns = cooldowns
+}
@@ -0,0 +1,81 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestCooldownSchedule(t *testing.T) {
+	timings := cooldownSchedule(time.Second*10, time.Minute, time.Minute*3)
+	want := []time.Duration{time.Second * 10, time.Second * 70, time.Second * 130}
+	if !slices.Equal(timings, want) {
+		t.Fatalf("cooldownSchedule() = %v, want %v", timings, want)
+	}
+}
+
+func TestCooldownTimingCandidates(t *testing.T) {
+	trinket := &cooldownOptimizerTarget{
+		ids:      []ActionID{{ItemID: 1}},
+		cooldown: time.Second * 20,
+		strategy: "when ready",
+	}
+	other := &cooldownOptimizerTarget{
+		ids:      []ActionID{{SpellID: 2}},
+		cooldown: time.Minute,
+		strategy: "first use at 10s",
+		timings:  []time.Duration{time.Second * 10, time.Second * 70},
+	}
+	targets := []*cooldownOptimizerTarget{trinket, other}
+
+	candidates := cooldownTimingCandidates(trinket, targets, time.Second*5, time.Second*90, time.Second*72)
+	strategies := MapSlice(candidates, func(candidate cooldownTimingCandidate) string { return candidate.strategy })
+	// 'when ready' is the current strategy, so it is not a candidate.
+	want := []string{"first use at 5s", "first use at 10s", "first use at 15s", "aligned to execute", "aligned with {SpellID: 2}"}
+	if !slices.Equal(strategies, want) {
+		t.Fatalf("cooldownTimingCandidates() = %v, want %v", strategies, want)
+	}
+	if execute := candidates[3].timings; execute[0] != time.Second*12 || execute[len(execute)-1] != time.Second*72 || len(execute) != 4 {
+		t.Fatalf("Expected uses every 20s up to the execute phase, got %v", execute)
+	}
+
+	group := &cooldownOptimizerTarget{
+		ids:      []ActionID{{ItemID: 1}, {SpellID: 3}},
+		cooldown: time.Minute * 3,
+	}
+	candidates = cooldownTimingCandidates(group, []*cooldownOptimizerTarget{group}, time.Minute, time.Minute*2, 0)
+	if len(candidates) != 2 || candidates[0].timings == nil {
+		t.Fatalf("Alignment groups should always be scheduled, got %v", candidates)
+	}
+
+	// Without a cooldown there is nothing to align to the execute phase.
+	noCooldown := &cooldownOptimizerTarget{ids: []ActionID{{SpellID: 4}}}
+	candidates = cooldownTimingCandidates(noCooldown, []*cooldownOptimizerTarget{noCooldown}, time.Second*5, time.Second*90, time.Second*72)
+	if len(candidates) != 1 || candidates[0].strategy != "when ready" {
+		t.Fatalf("Expected only 'when ready' without a cooldown, got %v", candidates)
+	}
+}
+
+func TestSetOptimizedCooldowns(t *testing.T) {
+	trinket := &cooldownOptimizerTarget{ids: []ActionID{{ItemID: 1}}, timings: []time.Duration{time.Second}}
+	racial := &cooldownOptimizerTarget{ids: []ActionID{{SpellID: 2}}}
+	player := &proto.Player{Cooldowns: &proto.Cooldowns{
+		HpPercentForDefensives: 0.3,
+		Cooldowns: []*proto.Cooldown{
+			{Id: ActionID{ItemID: 1}.ToProto(), Timings: []float64{5}},
+			{Id: ActionID{SpellID: 4}.ToProto(), Timings: []float64{20}},
+		},
+	}}
+
+	setOptimizedCooldowns(player, []*cooldownOptimizerTarget{trinket, racial}, racial, cooldownTimingCandidate{timings: []time.Duration{time.Second * 30}})
+
+	cooldowns := player.Cooldowns.Cooldowns
+	if player.Cooldowns.HpPercentForDefensives != 0.3 || len(cooldowns) != 3 {
+		t.Fatalf("Unexpected cooldowns %v", player.Cooldowns)
+	}
+	if cooldowns[0].Timings[0] != 20 || cooldowns[1].Timings[0] != 1 || cooldowns[2].Timings[0] != 30 {
+		t.Fatalf("Expected untouched, current and candidate timings, got %v", cooldowns)
+	}
+}
@@ -16,6 +16,7 @@ var rwMutex = sync.RWMutex{}
 var ItemsByID = map[int32]Item{}
 var RandomSuffixesByID = map[int32]RandomSuffix{}
 var EnchantsByEffectID = map[int32]Enchant{}
+var RunesByID = map[int32]Rune{}
 
 func addToDatabase(newDB *proto.SimDatabase) {
 	for _, v := range newDB.Items {
@@ -41,6 +42,14 @@ func addToDatabase(newDB *proto.SimDatabase) {
 		}
 		rwMutex.Unlock()
 	}
+
+	for _, v := range newDB.Runes {
+		rwMutex.Lock()
+		if _, ok := RunesByID[v.Id]; !ok {
+			RunesByID[v.Id] = RuneFromProto(v)
+		}
+		rwMutex.Unlock()
+	}
 }
 
 type Item struct {
@@ -67,6 +76,8 @@ type Item struct {
 	WeaponSkills        stats.WeaponSkills
 
 	Timeworn bool
+	Unique   bool
+	Phase    int32
 
 	// Modified for each instance of the item.
 	RandomSuffix RandomSuffix
@@ -97,6 +108,8 @@ func ItemFromProto(pData *proto.SimItem) Item {
 		SetID:               pData.SetId,
 		WeaponSkills:        stats.WeaponSkillsFloatArray(pData.WeaponSkills),
 		Timeworn:            pData.Timeworn,
+		Unique:              pData.Unique,
+		Phase:               pData.Phase,
 	}
 }
 
@@ -141,12 +154,18 @@ func EnchantFromProto(pData *proto.SimEnchant) Enchant {
 }
 
 type Rune struct {
-	ID int32
+	ID             int32
+	Type           proto.ItemType
+	RequiresLevel  int32
+	ClassAllowlist []proto.Class
 }
 
 func RuneFromProto(pData *proto.SimRune) Rune {
 	return Rune{
-		ID: pData.Id,
+		ID:             pData.Id,
+		Type:           pData.Type,
+		RequiresLevel:  pData.RequiresLevel,
+		ClassAllowlist: pData.ClassAllowlist,
 	}
 }
 
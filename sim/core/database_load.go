@@ -17,6 +17,7 @@ func init() {
 		Items:          make([]*proto.SimItem, len(db.Items)),
 		Enchants:       make([]*proto.SimEnchant, len(db.Enchants)),
 		RandomSuffixes: make([]*proto.ItemRandomSuffix, len(db.RandomSuffixes)),
+		Runes:          make([]*proto.SimRune, len(db.Runes)),
 	}
 
 	for i, item := range db.Items {
@@ -39,6 +40,8 @@ func init() {
 			SetId:               item.SetId,
 			WeaponSkills:        item.WeaponSkills,
 			Timeworn:            item.Timeworn,
+			Unique:              item.Unique,
+			Phase:               item.Phase,
 		}
 	}
 
@@ -57,5 +60,14 @@ func init() {
 		}
 	}
 
+	for i, rune := range db.Runes {
+		simDB.Runes[i] = &proto.SimRune{
+			Id:             rune.Id,
+			Type:           rune.Type,
+			RequiresLevel:  rune.RequiresLevel,
+			ClassAllowlist: rune.ClassAllowlist,
+		}
+	}
+
 	addToDatabase(simDB)
 }
@@ -0,0 +1,132 @@
+package core
+
+import (
+	"slices"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+const defaultResHealthPercent = 0.2
+
+func (character *Character) enableDeaths(deathModel *proto.DeathModel) {
+	character.Metrics.canDie = true
+	character.Env.Raid.deathModel = deathModel
+}
+
+// Kills the character. Dead characters stop acting, lose all temporary auras and pets, and
+// targets attacking them switch to another player. If the raid's death model allows it,
+// a resurrection is scheduled.
+func (character *Character) die(sim *Simulation) {
+	unit := &character.Unit
+	if !unit.enabled {
+		return
+	}
+
+	if sim.Log != nil {
+		character.Log(sim, "Died")
+	}
+
+	unit.enabled = false
+	unit.Metrics.Deaths++
+	unit.Metrics.DiedAt = sim.CurrentTime
+
+	unit.CancelGCDTimer(sim)
+	unit.AutoAttacks.CancelAutoSwing(sim)
+	if unit.IsChanneling(sim) {
+		unit.ChanneledDot.Cancel(sim)
+	}
+	unit.Hardcast = Hardcast{Expires: startingCDTime}
+	if unit.hardcastAction != nil {
+		unit.hardcastAction.Cancel(sim)
+	}
+
+	// Permanent auras are talents, passives and buffs which would be reapplied after a resurrection.
+	// Deactivating an aura reorders the active auras, so search again after each one.
+	for {
+		idx := slices.IndexFunc(unit.activeAuras, func(aura *Aura) bool {
+			return aura.Duration != NeverExpires
+		})
+		if idx == -1 {
+			break
+		}
+		unit.activeAuras[idx].Deactivate(sim)
+	}
+
+	// Pets don't outlive their owner, and have to be summoned again after a resurrection.
+	for _, pet := range character.Pets {
+		if pet.IsEnabled() {
+			pet.Disable(sim)
+		}
+	}
+
+	for _, target := range character.Env.Encounter.TargetUnits {
+		if newTarget := character.Env.Raid.firstLivingPlayer(); target.CurrentTarget == unit && newTarget != nil {
+			target.CurrentTarget = newTarget
+		}
+	}
+
+	deathModel := character.Env.Raid.deathModel
+	if deathModel.GetResDelay() <= 0 || !character.Env.Raid.canResurrect() {
+		return
+	}
+	character.scheduleResurrection(sim, DurationFromSeconds(deathModel.ResDelay))
+}
+
+// Returns whether the raid has a resurrection left in the current iteration.
+func (raid *Raid) canResurrect() bool {
+	maxResurrections := raid.deathModel.GetMaxResurrections()
+	return maxResurrections <= 0 || raid.resurrections < maxResurrections
+}
+
+// Resurrects the dead character after the delay, using up one of the raid's resurrections.
+func (character *Character) scheduleResurrection(sim *Simulation, delay time.Duration) {
+	raid := character.Env.Raid
+	raid.resurrections++
+	character.resurrecting = true
+
+	resHealthPercent := raid.deathModel.GetResHealthPercent()
+	if resHealthPercent <= 0 {
+		resHealthPercent = defaultResHealthPercent
+	}
+	StartDelayedAction(sim, DelayedActionOptions{
+		DoAt: sim.CurrentTime + delay,
+		OnAction: func(sim *Simulation) {
+			character.resurrect(sim, resHealthPercent)
+		},
+	})
+}
+
+// Brings a dead character back to life with the given fraction of its max health and mana.
+func (character *Character) resurrect(sim *Simulation, healthPercent float64) {
+	unit := &character.Unit
+	character.resurrecting = false
+	if unit.enabled {
+		return
+	}
+
+	if sim.Log != nil {
+		character.Log(sim, "Resurrected")
+	}
+
+	unit.enabled = true
+	unit.Metrics.TimeDead += sim.CurrentTime - unit.Metrics.DiedAt
+
+	unit.healthBar.currentHealth = unit.MaxHealth() * healthPercent
+	if unit.HasManaBar() {
+		unit.currentMana = unit.MaxMana() * healthPercent
+	}
+
+	unit.SetGCDTimer(sim, sim.CurrentTime)
+	unit.AutoAttacks.EnableAutoSwing(sim)
+}
+
+// Returns the first player which is alive, or nil if the whole raid is dead.
+func (raid *Raid) firstLivingPlayer() *Unit {
+	for _, unit := range raid.AllPlayerUnits {
+		if unit.IsEnabled() {
+			return unit
+		}
+	}
+	return nil
+}
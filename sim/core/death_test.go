@@ -0,0 +1,148 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func deathTestRequest() *proto.RaidSimRequest {
+	return &proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Victim",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			DeathModel: &proto.DeathModel{
+				Enabled:          true,
+				ResDelay:         10,
+				MaxResurrections: 1,
+				ResHealthPercent: 0.5,
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{
+					Name:  "target",
+					Level: 63,
+					Spells: []*proto.TargetSpell{{
+						SpellId:      686,
+						SpellSchool:  proto.SpellSchool_SpellSchoolShadow,
+						Cooldown:     30,
+						InitialDelay: 1,
+						MinDamage:    100000,
+						MaxDamage:    100000,
+					}},
+				},
+			},
+			Duration: 180,
+		},
+	}
+}
+
+func TestDeathAndResurrection(t *testing.T) {
+	sim := NewSim(deathTestRequest(), simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	unit := &fa.Unit
+
+	stepUntil := func(condition func() bool) {
+		for i := 0; i < 1000 && !condition(); i++ {
+			if sim.Step() {
+				break
+			}
+		}
+	}
+
+	stepUntil(func() bool { return !unit.IsEnabled() })
+	if sim.CurrentTime != time.Second || unit.Metrics.Deaths != 1 {
+		t.Fatalf("Expected death at 1s, got %d deaths at %s", unit.Metrics.Deaths, sim.CurrentTime)
+	}
+
+	stepUntil(func() bool { return unit.IsEnabled() })
+	if sim.CurrentTime != time.Second*11 {
+		t.Fatalf("Expected resurrection at 11s, got %s", sim.CurrentTime)
+	}
+	if unit.CurrentHealth() != unit.MaxHealth()*0.5 || unit.Metrics.TimeDead != time.Second*10 {
+		t.Fatalf("Unexpected state after resurrection: %0.1f health, %s dead", unit.CurrentHealth(), unit.Metrics.TimeDead)
+	}
+
+	// The raid only has a single resurrection available.
+	stepUntil(func() bool { return !unit.IsEnabled() })
+	stepUntil(func() bool { return sim.CurrentTime >= time.Second*60 })
+	if unit.IsEnabled() || unit.Metrics.Deaths != 2 {
+		t.Fatalf("Expected unit to stay dead after 2 deaths, got enabled = %t, %d deaths", unit.IsEnabled(), unit.Metrics.Deaths)
+	}
+}
+
+func TestBattleRes(t *testing.T) {
+	request := deathTestRequest()
+	request.Raid.DeathModel.ResDelay = 0
+	request.Raid.Parties[0].Players = append(request.Raid.Parties[0].Players, &proto.Player{
+		Name:      "Druid",
+		Class:     proto.Class_ClassShaman,
+		Consumes:  &proto.Consumes{},
+		Buffs:     &proto.IndividualBuffs{},
+		Spec:      &proto.Player_ElementalShaman{},
+		Equipment: &proto.EquipmentSpec{},
+		Rotation: &proto.APLRotation{
+			Type: proto.APLRotation_TypeAPL,
+			PriorityList: []*proto.APLListItem{{
+				Action: &proto.APLAction{Action: &proto.APLAction_BattleRes{BattleRes: &proto.APLActionBattleRes{
+					Target:       &proto.UnitReference{Type: proto.UnitReference_Player, Index: 0},
+					DelaySeconds: 5,
+				}}},
+			}},
+		},
+	})
+
+	sim := NewSim(request, simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+
+	unit := &sim.Raid.Parties[0].Players[0].(*FakeAgent).Unit
+	for i := 0; i < 1000 && sim.CurrentTime < time.Second*6; i++ {
+		if sim.Step() {
+			break
+		}
+	}
+	if !unit.IsEnabled() || unit.Metrics.Deaths != 1 || unit.Metrics.TimeDead != time.Second*5 {
+		t.Fatalf("Expected a battle res 5s after the death at 1s, got enabled = %t, %d deaths, %s dead", unit.IsEnabled(), unit.Metrics.Deaths, unit.Metrics.TimeDead)
+	}
+}
+
+func TestTimeAliveConcurrent(t *testing.T) {
+	request := deathTestRequest()
+	request.SimOptions.Iterations = 6
+	request.SimOptions.IsTest = true
+
+	result := runSimConcurrent(request, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+	// Splits continue the seeds of each other, so the combined result matches a single run.
+	timeAlive := result.RaidMetrics.Parties[0].Players[0].TimeAlive
+	expected := RunRaidSim(request).RaidMetrics.Parties[0].Players[0].TimeAlive
+	if timeAlive == nil || timeAlive.AggregatorData.N != 6 || math.Abs(timeAlive.Avg-expected.Avg) > 1e-9 {
+		t.Fatalf("Expected time alive of %0.2fs combined over 6 iterations, got %v", expected.Avg, timeAlive)
+	}
+}
@@ -1,12 +1,15 @@
 package core
 
 import (
+	"fmt"
 	"math"
+	"slices"
 	"strconv"
 	"time"
 
 	"github.com/wowsims/classic/sim/core/proto"
 	"github.com/wowsims/classic/sim/core/stats"
+	"google.golang.org/protobuf/reflect/protoreflect"
 )
 
 type DebuffName int32
@@ -27,18 +30,34 @@ var LevelToDebuffRank = map[DebuffName]map[int32]int32{
 
 func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, raid *proto.Raid) {
 	level := raid.Parties[0].Players[0].Level
+
+	validateDebuffProfiles(debuffs)
+	permanent := func(name string, aura *Aura) {
+		if profile := debuffs.Profiles[name]; profile != nil && aura != nil {
+			ApplyDebuffProfile(aura, profile)
+		} else {
+			MakePermanent(aura)
+		}
+	}
+	scheduled := func(name string, aura *Aura, options PeriodicActionOptions) {
+		if profile := debuffs.Profiles[name]; profile != nil {
+			ApplyDebuffProfile(aura, profile)
+		} else {
+			SchedulePeriodicDebuffApplication(aura, options, raid)
+		}
+	}
 	if debuffs.JudgementOfWisdom && targetIdx == 0 {
 		jowAura := JudgementOfWisdomAura(target, level)
 		if jowAura != nil {
-			MakePermanent(jowAura)
+			permanent("judgement_of_wisdom", jowAura)
 		}
 	}
 
 	if targetIdx == 0 {
 		if debuffs.JudgementOfTheCrusader == proto.TristateEffect_TristateEffectRegular {
-			MakePermanent(JudgementOfTheCrusaderAura(nil, target, level, 1, 0))
+			permanent("judgement_of_the_crusader", JudgementOfTheCrusaderAura(nil, target, level, 1, 0))
 		} else if debuffs.JudgementOfTheCrusader == proto.TristateEffect_TristateEffectImproved {
-			MakePermanent(JudgementOfTheCrusaderAura(nil, target, level, 1.15, 0))
+			permanent("judgement_of_the_crusader", JudgementOfTheCrusaderAura(nil, target, level, 1.15, 0))
 		}
 	}
 
@@ -48,7 +67,7 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 
 	if debuffs.ShadowWeaving {
 		aura := ShadowWeavingAura(target, 5)
-		SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+		scheduled("shadow_weaving", aura, PeriodicActionOptions{
 			Period:          time.Millisecond * 1500,
 			NumTicks:        5,
 			TickImmediately: true,
@@ -59,12 +78,12 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 					aura.AddStack(sim)
 				}
 			},
-		}, raid)
+		})
 	}
 
 	if debuffs.OccultPoison {
 		aura := OccultPoisonDebuffAura(target, level)
-		SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+		scheduled("occult_poison", aura, PeriodicActionOptions{
 			Period:          time.Millisecond * 1500,
 			NumTicks:        5,
 			TickImmediately: true,
@@ -75,32 +94,32 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 					aura.AddStack(sim)
 				}
 			},
-		}, raid)
+		})
 	}
 
 	if debuffs.MekkatorqueFistDebuff {
-		MakePermanent(MekkatorqueFistDebuffAura(target, level))
+		permanent("mekkatorque_fist_debuff", MekkatorqueFistDebuffAura(target, level))
 	}
 
 	if debuffs.SerpentsStrikerFistDebuff {
-		MakePermanent(SerpentsStrikerFistDebuffAura(target, level))
+		permanent("serpents_striker_fist_debuff", SerpentsStrikerFistDebuffAura(target, level))
 	}
 
 	if debuffs.MarkOfChaos {
-		MakePermanent(MarkOfChaosDebuffAura(target))
+		permanent("mark_of_chaos", MarkOfChaosDebuffAura(target))
 	} else {
 		if debuffs.CurseOfElements {
-			MakePermanent(CurseOfElementsAura(target, level))
+			permanent("curse_of_elements", CurseOfElementsAura(target, level))
 		}
 
 		if debuffs.CurseOfShadow {
-			MakePermanent(CurseOfShadowAura(target, level))
+			permanent("curse_of_shadow", CurseOfShadowAura(target, level))
 		}
 	}
 
 	if debuffs.ImprovedScorch && targetIdx == 0 {
 		aura := ImprovedScorchAura(target)
-		SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+		scheduled("improved_scorch", aura, PeriodicActionOptions{
 			Period:          time.Millisecond * 1500,
 			NumTicks:        5,
 			TickImmediately: true,
@@ -111,12 +130,12 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 					aura.AddStack(sim)
 				}
 			},
-		}, raid)
+		})
 	}
 
 	if debuffs.WintersChill && targetIdx == 0 {
 		aura := WintersChillAura(target)
-		SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+		scheduled("winters_chill", aura, PeriodicActionOptions{
 			Period:          time.Millisecond * 1500,
 			NumTicks:        5,
 			TickImmediately: true,
@@ -127,29 +146,29 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 					aura.AddStack(sim)
 				}
 			},
-		}, raid)
+		})
 	}
 
 	if debuffs.Stormstrike {
-		MakePermanent(StormstrikeAura(target))
+		permanent("stormstrike", StormstrikeAura(target))
 	} else if debuffs.Dreamstate {
-		MakePermanent(DreamstateAura(target))
+		permanent("dreamstate", DreamstateAura(target))
 	}
 
 	if debuffs.GiftOfArthas {
-		MakePermanent(GiftOfArthasAura(target))
+		permanent("gift_of_arthas", GiftOfArthasAura(target))
 	}
 
 	if debuffs.CurseOfVulnerability {
-		MakePermanent(CurseOfVulnerabilityAura(target))
+		permanent("curse_of_vulnerability", CurseOfVulnerabilityAura(target))
 	}
 
 	if debuffs.Mangle {
-		MakePermanent(MangleAura(target, level))
+		permanent("mangle", MangleAura(target, level))
 	}
 
 	if debuffs.CrystalYield {
-		MakePermanent(CrystalYieldAura(target))
+		permanent("crystal_yield", CrystalYieldAura(target))
 	}
 
 	if debuffs.AncientCorrosivePoison > 0 {
@@ -160,30 +179,30 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 	if targetIdx == 0 {
 		if debuffs.ExposeArmor != proto.TristateEffect_TristateEffectMissing {
 			aura := ExposeArmorAura(target, TernaryInt32(debuffs.ExposeArmor == proto.TristateEffect_TristateEffectRegular, 0, 2), level)
-			SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+			scheduled("expose_armor", aura, PeriodicActionOptions{
 				Period:   time.Second * 3,
 				NumTicks: 1,
 				OnAction: func(sim *Simulation) {
 					aura.Activate(sim)
 				},
-			}, raid)
+			})
 		}
 
 		if debuffs.SebaciousPoison != proto.TristateEffect_TristateEffectMissing {
 			aura := SebaciousPoisonAura(target, TernaryInt32(debuffs.SebaciousPoison == proto.TristateEffect_TristateEffectRegular, 0, 2), level)
-			SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+			scheduled("sebacious_poison", aura, PeriodicActionOptions{
 				Period:   time.Second * 3,
 				NumTicks: 1,
 				OnAction: func(sim *Simulation) {
 					aura.Activate(sim)
 				},
-			}, raid)
+			})
 		}
 
 		if debuffs.SunderArmor {
 			// Sunder Armor
 			aura := SunderArmorAura(target, level)
-			SchedulePeriodicDebuffApplication(aura, PeriodicActionOptions{
+			scheduled("sunder_armor", aura, PeriodicActionOptions{
 				Period:          time.Millisecond * 1500,
 				NumTicks:        5,
 				TickImmediately: true,
@@ -194,7 +213,7 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 						aura.AddStack(sim)
 					}
 				},
-			}, raid)
+			})
 		}
 
 		if debuffs.Homunculi > 0 {
@@ -208,52 +227,52 @@ func applyDebuffEffects(target *Unit, targetIdx int, debuffs *proto.Debuffs, rai
 	}
 
 	if debuffs.CurseOfRecklessness {
-		MakePermanent(CurseOfRecklessnessAura(target, level))
+		permanent("curse_of_recklessness", CurseOfRecklessnessAura(target, level))
 	}
 
 	if debuffs.FaerieFire || debuffs.ImprovedFaerieFire {
-		MakePermanent(FaerieFireAura(target, level))
+		permanent("faerie_fire", FaerieFireAura(target, level))
 	}
 
 	if debuffs.ImprovedFaerieFire {
-		MakePermanent(ImprovedFaerieFireAura(target))
+		permanent("improved_faerie_fire", ImprovedFaerieFireAura(target))
 	}
 
 	if debuffs.MeleeHunterDodgeDebuff {
-		MakePermanent(MeleeHunterDodgeReductionAura(target, level))
+		permanent("melee_hunter_dodge_debuff", MeleeHunterDodgeReductionAura(target, level))
 	}
 
 	if debuffs.CurseOfWeakness != proto.TristateEffect_TristateEffectMissing {
-		MakePermanent(CurseOfWeaknessAura(target, GetTristateValueInt32(debuffs.CurseOfWeakness, 0, 3), level))
+		permanent("curse_of_weakness", CurseOfWeaknessAura(target, GetTristateValueInt32(debuffs.CurseOfWeakness, 0, 3), level))
 	}
 
 	if debuffs.DemoralizingRoar != proto.TristateEffect_TristateEffectMissing {
-		MakePermanent(DemoralizingRoarAura(target, GetTristateValueInt32(debuffs.DemoralizingRoar, 0, 5), level))
+		permanent("demoralizing_roar", DemoralizingRoarAura(target, GetTristateValueInt32(debuffs.DemoralizingRoar, 0, 5), level))
 	}
 	if debuffs.DemoralizingShout != proto.TristateEffect_TristateEffectMissing {
-		MakePermanent(DemoralizingShoutAura(target, 0, GetTristateValueInt32(debuffs.DemoralizingShout, 0, 5), level))
+		permanent("demoralizing_shout", DemoralizingShoutAura(target, 0, GetTristateValueInt32(debuffs.DemoralizingShout, 0, 5), level))
 	}
 	if debuffs.HuntersMark != proto.TristateEffect_TristateEffectMissing {
-		MakePermanent(HuntersMarkAura(target, GetTristateValueInt32(debuffs.HuntersMark, 0, 5), level))
+		permanent("hunters_mark", HuntersMarkAura(target, GetTristateValueInt32(debuffs.HuntersMark, 0, 5), level))
 	}
 
 	// Atk spd reduction
 	if debuffs.ThunderClap != proto.TristateEffect_TristateEffectMissing {
-		MakePermanent(ThunderClapAura(target, 8205, time.Second*22, GetTristateValueInt32(debuffs.ThunderClap, 10, 16)))
+		permanent("thunder_clap", ThunderClapAura(target, 8205, time.Second*22, GetTristateValueInt32(debuffs.ThunderClap, 10, 16)))
 	}
 	if debuffs.Waylay {
-		MakePermanent(WaylayAura(target))
+		permanent("waylay", WaylayAura(target))
 	}
 	if debuffs.Thunderfury {
-		MakePermanent(ThunderfuryASAura(target, level))
+		permanent("thunderfury", ThunderfuryASAura(target, level))
 	}
 
 	// Miss
 	if debuffs.InsectSwarm && targetIdx == 0 {
-		MakePermanent(InsectSwarmAura(target, level))
+		permanent("insect_swarm", InsectSwarmAura(target, level))
 	}
 	if debuffs.ScorpidSting && targetIdx == 0 {
-		MakePermanent(ScorpidStingAura(target))
+		permanent("scorpid_sting", ScorpidStingAura(target))
 	}
 }
 
@@ -439,6 +458,140 @@ func SchedulePeriodicDebuffApplication(aura *Aura, options PeriodicActionOptions
 	}
 }
 
+// Period at which debuffs with an uptime below 100% are rolled to be up or down.
+const debuffProfileUptimePeriod = time.Second * 3
+
+// Debuffs which can't be given a profile, as they have their own uptime setting or aren't applied
+// as a plain aura.
+var debuffsWithoutProfiles = []string{
+	"ancient_corrosive_poison",
+	"homunculi",
+	"improved_shadow_bolt",
+	"judgement_of_light",
+	"vampiric_embrace",
+}
+
+// Debuffs which are only applied to the first target, along with their profiles.
+var firstTargetOnlyDebuffs = []string{
+	"expose_armor",
+	"improved_scorch",
+	"insect_swarm",
+	"judgement_of_the_crusader",
+	"judgement_of_wisdom",
+	"scorpid_sting",
+	"sebacious_poison",
+	"sunder_armor",
+	"winters_chill",
+}
+
+// Panics if a profile does not belong to a debuff which supports profiles.
+func validateDebuffProfiles(debuffs *proto.Debuffs) {
+	fields := debuffs.ProtoReflect().Descriptor().Fields()
+	for name := range debuffs.Profiles {
+		if field := fields.ByName(protoreflect.Name(name)); field == nil || field.IsMap() {
+			panic(fmt.Sprintf("Debuff profile for unknown debuff '%s'", name))
+		}
+		if slices.Contains(debuffsWithoutProfiles, name) {
+			panic(fmt.Sprintf("Debuff '%s' doesn't support profiles", name))
+		}
+	}
+}
+
+// Applies an external debuff following its profile, instead of keeping it up for the whole fight.
+// The debuff is first applied at the start time and ramps up one stack per stack interval. Once
+// applied it is rolled to be up or down every few seconds according to its uptime, and it is
+// removed during the falloff windows. Every reapplication ramps up from a single stack again.
+func ApplyDebuffProfile(aura *Aura, profile *proto.DebuffProfile) {
+	startTime := DurationFromSeconds(profile.StartTime)
+	stackInterval := DurationFromSeconds(profile.StackInterval)
+	uptime := TernaryFloat64(profile.Uptime <= 0, 1, min(profile.Uptime, 1))
+
+	var rampAction *PendingAction
+	falloffs := 0 // Number of falloff windows currently in progress.
+
+	apply := func(sim *Simulation) {
+		aura.Activate(sim)
+		if aura.MaxStacks == 0 {
+			return
+		}
+		if stackInterval <= 0 {
+			aura.SetStacks(sim, aura.MaxStacks)
+			return
+		}
+
+		aura.SetStacks(sim, 1)
+		rampAction = StartPeriodicAction(sim, PeriodicActionOptions{
+			Period:   stackInterval,
+			NumTicks: int(aura.MaxStacks - 1),
+			Priority: ActionPriorityDOT,
+			OnAction: func(sim *Simulation) {
+				if aura.IsActive() {
+					aura.AddStack(sim)
+				}
+			},
+		})
+	}
+	remove := func(sim *Simulation) {
+		if rampAction != nil {
+			rampAction.Cancel(sim)
+			rampAction = nil
+		}
+		aura.Deactivate(sim)
+	}
+	rollUptime := func(sim *Simulation) {
+		if falloffs > 0 {
+			return
+		}
+		if up := uptime == 1 || sim.Proc(uptime, "Debuff Profile Uptime"); up && !aura.IsActive() {
+			apply(sim)
+		} else if !up && aura.IsActive() {
+			remove(sim)
+		}
+	}
+
+	aura.Unit.RegisterResetEffect(func(sim *Simulation) {
+		aura.Duration = NeverExpires
+		rampAction = nil
+		falloffs = 0
+
+		StartDelayedAction(sim, DelayedActionOptions{
+			DoAt:     startTime,
+			Priority: ActionPriorityDOT,
+			OnAction: func(sim *Simulation) {
+				rollUptime(sim)
+				if uptime < 1 {
+					StartPeriodicAction(sim, PeriodicActionOptions{
+						Period:   debuffProfileUptimePeriod,
+						Priority: ActionPriorityDOT,
+						OnAction: rollUptime,
+					})
+				}
+			},
+		})
+
+		for _, falloff := range profile.Falloffs {
+			StartDelayedAction(sim, DelayedActionOptions{
+				DoAt:     DurationFromSeconds(falloff.StartTime),
+				Priority: ActionPriorityDOT,
+				OnAction: func(sim *Simulation) {
+					falloffs++
+					remove(sim)
+				},
+			})
+			StartDelayedAction(sim, DelayedActionOptions{
+				DoAt:     DurationFromSeconds(falloff.StartTime + falloff.Duration),
+				Priority: ActionPriorityDOT,
+				OnAction: func(sim *Simulation) {
+					falloffs--
+					if sim.CurrentTime >= startTime {
+						rollUptime(sim)
+					}
+				},
+			})
+		}
+	})
+}
+
 const JudgementAuraTag = "Judgement"
 
 // TODO: Classic verify logic
@@ -0,0 +1,133 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestDebuffProfile(t *testing.T) {
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Player",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			Debuffs: &proto.Debuffs{
+				SunderArmor:         true,
+				CurseOfRecklessness: true,
+				Profiles: map[string]*proto.DebuffProfile{
+					"sunder_armor": {
+						StartTime:     5,
+						StackInterval: 2,
+						Falloffs:      []*proto.DebuffFalloff{{StartTime: 30, Duration: 10}},
+					},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Name: "target", Level: 63}},
+			Duration: 180,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+
+	target := sim.Encounter.Targets[0]
+	sunder := target.GetAura("Sunder Armor")
+	curse := target.GetAura("Curse of Recklessness")
+
+	// Executes all pending actions up to and including the given time.
+	stepUntil := func(time time.Duration) {
+		for i := 0; i < 10000 && sim.pendingActions[len(sim.pendingActions)-1].NextActionAt <= time; i++ {
+			if sim.Step() {
+				break
+			}
+		}
+	}
+
+	stepUntil(time.Second * 4)
+	if sunder.IsActive() || !curse.IsActive() {
+		t.Fatalf("Only debuffs without a profile should be up at the start")
+	}
+
+	stepUntil(time.Second * 8)
+	if sunder.GetStacks() != 2 {
+		t.Fatalf("Expected 2 stacks of Sunder Armor at %s, got %d", sim.CurrentTime, sunder.GetStacks())
+	}
+
+	stepUntil(time.Second * 20)
+	if sunder.GetStacks() != 5 {
+		t.Fatalf("Expected 5 stacks of Sunder Armor at %s, got %d", sim.CurrentTime, sunder.GetStacks())
+	}
+
+	stepUntil(time.Second * 35)
+	if sunder.IsActive() {
+		t.Fatalf("Sunder Armor should have fallen off at %s", sim.CurrentTime)
+	}
+
+	stepUntil(time.Second * 41)
+	if sunder.GetStacks() != 1 {
+		t.Fatalf("Expected Sunder Armor to ramp up again at %s, got %d stacks", sim.CurrentTime, sunder.GetStacks())
+	}
+}
+
+func TestDebuffProfileUnknownDebuff(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Expected a panic for a profile of an unknown debuff")
+		}
+	}()
+	validateDebuffProfiles(&proto.Debuffs{Profiles: map[string]*proto.DebuffProfile{"sunder_armour": {}}})
+}
+
+func TestValidateDebuffProfiles(t *testing.T) {
+	rsr := &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Debuffs: &proto.Debuffs{
+				SunderArmor:        true,
+				ImprovedFaerieFire: true,
+				Homunculi:          70,
+				Profiles: map[string]*proto.DebuffProfile{
+					"sunder_armor":          {StartTime: 5},
+					"faerie_fire":           {StartTime: 5},
+					"curse_of_recklessness": {StartTime: 5},
+					"homunculi":             {StartTime: 5},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{Targets: []*proto.Target{{}, {}}},
+	}
+
+	const profiles = "raid.debuffs.profiles"
+	expected := []*proto.ValidationIssue{
+		{Path: profiles + `["curse_of_recklessness"]`, Severity: proto.ValidationSeverity_ValidationSeverityWarning},
+		{Path: profiles + `["homunculi"]`, Severity: proto.ValidationSeverity_ValidationSeverityError},
+		{Path: profiles + `["sunder_armor"]`, Severity: proto.ValidationSeverity_ValidationSeverityWarning},
+	}
+	issues := ValidateRaidSimRequest(rsr)
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %v", len(expected), issues)
+	}
+	for i, issue := range issues {
+		if issue.Path != expected[i].Path || issue.Severity != expected[i].Severity {
+			t.Fatalf("Expected %s issue for %s, got %v", expected[i].Severity, expected[i].Path, issue)
+		}
+	}
+}
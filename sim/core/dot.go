@@ -216,7 +216,7 @@ func (dot *Dot) TickOnce(sim *Simulation) {
 			if dot.Spell.Unit.GCD.IsReady(sim) {
 				dot.Spell.Unit.WaitUntil(sim, sim.CurrentTime+dot.Spell.Unit.ChannelClipDelay)
 			}
-		} else if dot.Spell.Unit.Rotation.shouldInterruptChannel(sim) {
+		} else if dot.Spell.Unit.Rotation.shouldInterruptChannel(sim) || (dot.MaxTicksRemaining() == 1 && dot.Spell.Unit.rollChannelClip(sim)) {
 			dot.Cancel(sim)
 			if dot.Spell.Unit.GCD.IsReady(sim) {
 				dot.Spell.Unit.WaitUntil(sim, sim.CurrentTime+dot.Spell.Unit.ChannelClipDelay)
@@ -149,7 +149,7 @@ func (env *Environment) finalize(raidProto *proto.Raid, _ *proto.Encounter, raid
 
 	for _, target := range env.Encounter.Targets {
 		target.finalize()
-		if target.AI != nil {
+		if target.HasRotation() {
 			target.Rotation = target.newCustomRotation()
 		}
 	}
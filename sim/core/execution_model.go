@@ -0,0 +1,131 @@
+package core
+
+import (
+	"math"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Imperfect human execution of the rotation, configured through proto.ExecutionModel.
+type executionModel struct {
+	latency  *proto.DelayDistribution
+	reaction *proto.DelayDistribution
+
+	missedGCDChance   float64
+	swingClipChance   float64
+	channelClipChance float64
+
+	// Whether the current decision is waiting for its delay, and until when.
+	delayed      bool
+	delayedUntil time.Duration
+	// Whether the unit had nothing to do at its previous decision.
+	idle bool
+}
+
+func newExecutionModel(config *proto.ExecutionModel) *executionModel {
+	return &executionModel{
+		latency:           config.Latency,
+		reaction:          config.Reaction,
+		missedGCDChance:   config.MissedGcdChance,
+		swingClipChance:   config.SwingClipChance,
+		channelClipChance: config.ChannelClipChance,
+	}
+}
+
+func (em *executionModel) reset() {
+	em.delayed = false
+	em.delayedUntil = 0
+	em.idle = false
+}
+
+// Draws a delay from the distribution. A nil distribution never delays.
+func drawDelay(sim *Simulation, dist *proto.DelayDistribution, label string) time.Duration {
+	if dist == nil || dist.MeanMs <= 0 {
+		return 0
+	}
+
+	var ms float64
+	switch dist.Type {
+	case proto.DelayDistribution_Normal:
+		ms = dist.MeanMs + dist.StdevMs*sim.RandomNormFloat(label)
+	case proto.DelayDistribution_LogNormal:
+		// Parameters of the underlying normal distribution, so that the draws have the configured mean and stdev.
+		sigmaSq := math.Log(1 + (dist.StdevMs*dist.StdevMs)/(dist.MeanMs*dist.MeanMs))
+		mu := math.Log(dist.MeanMs) - sigmaSq/2
+		ms = math.Exp(mu + math.Sqrt(sigmaSq)*sim.RandomNormFloat(label))
+	case proto.DelayDistribution_Uniform:
+		halfWidth := dist.StdevMs * math.Sqrt(3)
+		ms = sim.RollWithLabel(dist.MeanMs-halfWidth, dist.MeanMs+halfWidth, label)
+	case proto.DelayDistribution_Exponential:
+		ms = dist.MeanMs * sim.RandomExpFloat(label)
+	}
+	return DurationFromSeconds(max(0, ms) / 1000)
+}
+
+// Called by the rotation once it has picked an action. Returns true if the action should not be
+// executed yet, in which case the next decision is delayed and the rotation decides again afterwards.
+// A running GCD is never shortened, so off-GCD actions picked mid-GCD wait for the end of the GCD.
+func (unit *Unit) delayForExecution(sim *Simulation) bool {
+	em := unit.executionModel
+	if em == nil {
+		return false
+	}
+
+	if em.delayed && sim.CurrentTime < em.delayedUntil {
+		// Decisions triggered by other events, e.g. resource gains, don't cut the delay short.
+		unit.WaitUntil(sim, max(unit.GCD.ReadyAt(), em.delayedUntil))
+		return true
+	}
+
+	if em.delayed {
+		em.delayed = false
+		em.idle = false
+		if em.swingClipChance > 0 && sim.Proc(em.swingClipChance, "Swing Clip") {
+			clip := drawDelay(sim, em.latency, "Swing Clip Delay")
+			if sim.Log != nil {
+				unit.Log(sim, "Clipping auto attacks by %s", clip)
+			}
+			if unit.AutoAttacks.AutoSwingMelee {
+				unit.AutoAttacks.DelayMeleeBy(sim, clip)
+			}
+			if unit.AutoAttacks.AutoSwingRanged {
+				unit.AutoAttacks.DelayRangedUntil(sim, unit.AutoAttacks.NextRangedAttackAt()+clip)
+			}
+		}
+		return false
+	}
+
+	delay := drawDelay(sim, em.latency, "Execution Latency")
+	if em.idle {
+		delay += drawDelay(sim, em.reaction, "Execution Reaction")
+	}
+	if em.missedGCDChance > 0 && sim.Proc(em.missedGCDChance, "Missed GCD") {
+		if sim.Log != nil {
+			unit.Log(sim, "Missed a GCD")
+		}
+		delay += GCDDefault
+	}
+	if delay <= 0 {
+		em.idle = false
+		return false
+	}
+
+	em.delayed = true
+	em.delayedUntil = sim.CurrentTime + delay
+	unit.WaitUntil(sim, max(unit.GCD.ReadyAt(), em.delayedUntil))
+	return true
+}
+
+// Called by the rotation when it had nothing to do.
+func (unit *Unit) idleForExecution() {
+	if em := unit.executionModel; em != nil {
+		em.idle = true
+	}
+}
+
+// Returns whether a channel, which has one tick remaining, is cut short.
+func (unit *Unit) rollChannelClip(sim *Simulation) bool {
+	em := unit.executionModel
+	return em != nil && em.channelClipChance > 0 && sim.Proc(em.channelClipChance, "Channel Clip")
+}
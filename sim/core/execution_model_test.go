@@ -0,0 +1,111 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func newExecutionModelTestSim(config *proto.ExecutionModel) *Simulation {
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:           "Human",
+							Class:          proto.Class_ClassShaman,
+							Consumes:       &proto.Consumes{},
+							Buffs:          &proto.IndividualBuffs{},
+							Spec:           &proto.Player_ElementalShaman{},
+							Equipment:      &proto.EquipmentSpec{},
+							ExecutionModel: config,
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Name: "target", Level: 63}},
+			Duration: 180,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+	return sim
+}
+
+func TestDrawDelay(t *testing.T) {
+	sim := newExecutionModelTestSim(nil)
+
+	for _, distType := range []proto.DelayDistribution_Type{
+		proto.DelayDistribution_Normal,
+		proto.DelayDistribution_LogNormal,
+		proto.DelayDistribution_Uniform,
+		proto.DelayDistribution_Exponential,
+	} {
+		dist := &proto.DelayDistribution{Type: distType, MeanMs: 200, StdevMs: 50}
+
+		const n = 20000
+		total := time.Duration(0)
+		for i := 0; i < n; i++ {
+			delay := drawDelay(sim, dist, "test")
+			if delay < 0 {
+				t.Fatalf("%s: negative delay %s", distType, delay)
+			}
+			total += delay
+		}
+
+		if mean := total.Seconds() * 1000 / n; math.Abs(mean-200) > 10 {
+			t.Fatalf("%s: expected mean of 200ms, got %0.1fms", distType, mean)
+		}
+	}
+
+	if delay := drawDelay(sim, nil, "test"); delay != 0 {
+		t.Fatalf("Missing distribution should not delay, got %s", delay)
+	}
+}
+
+func TestDelayForExecution(t *testing.T) {
+	sim := newExecutionModelTestSim(&proto.ExecutionModel{
+		Enabled:  true,
+		Latency:  &proto.DelayDistribution{MeanMs: 100},
+		Reaction: &proto.DelayDistribution{MeanMs: 300},
+	})
+	unit := &sim.Raid.Parties[0].Players[0].GetCharacter().Unit
+
+	if !unit.delayForExecution(sim) || unit.NextGCDAt() != sim.CurrentTime+time.Millisecond*100 {
+		t.Fatalf("Expected action to be delayed by the latency, GCD at %s", unit.NextGCDAt())
+	}
+	// Decisions before the delay is over keep waiting.
+	sim.CurrentTime += time.Millisecond * 50
+	if !unit.delayForExecution(sim) || unit.NextGCDAt() != sim.CurrentTime+time.Millisecond*50 {
+		t.Fatalf("Expected early decision to keep waiting for the delay, GCD at %s", unit.NextGCDAt())
+	}
+	sim.CurrentTime = unit.NextGCDAt()
+	if unit.delayForExecution(sim) {
+		t.Fatalf("Delayed action should be executed on the next decision")
+	}
+
+	// After being idle, the reaction time is added.
+	unit.idleForExecution()
+	if !unit.delayForExecution(sim) || unit.NextGCDAt() != sim.CurrentTime+time.Millisecond*400 {
+		t.Fatalf("Expected action to be delayed by latency and reaction, GCD at %s", unit.NextGCDAt())
+	}
+
+	sim.CurrentTime = unit.NextGCDAt()
+	unit.delayForExecution(sim)
+
+	// A decision mid-GCD, e.g. for an off-GCD action, doesn't shorten the running GCD.
+	unit.SetGCDTimer(sim, sim.CurrentTime+time.Second)
+	if !unit.delayForExecution(sim) || unit.NextGCDAt() != sim.CurrentTime+time.Second {
+		t.Fatalf("Expected the running GCD to be kept, GCD at %s", unit.NextGCDAt())
+	}
+}
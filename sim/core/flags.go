@@ -194,6 +194,7 @@ const (
 	SpellFlagSuppressEquipProcs                            // Indicates this spell cannot proc Equip procs
 	SpellFlagBatchStopAttackMacro                          // Indicates this spell is being cast in a Macro with a stopattack following it
 	SpellFlagNotAProc                                      // Indicates the proc is not treated as a proc (Seal of Command)
+	SpellFlagInterruptible                                 // Indicates casts of this spell can be interrupted (e.g. by Kick or Counterspell)
 
 	// Used to let agents categorize their spells.
 	SpellFlagAgentReserved1
@@ -89,22 +89,46 @@ func (hb *healthBar) RemoveHealth(sim *Simulation, amount float64) {
 
 var ChanceOfDeathAuraLabel = "Chance of Death"
 
-func (character *Character) trackChanceOfDeath(healingModel *proto.HealingModel) {
+func (character *Character) trackChanceOfDeath(healingModel *proto.HealingModel, deathModel *proto.DeathModel) {
 	character.Unit.Metrics.isTanking = false
 	for _, target := range character.Env.Encounter.TargetUnits {
 		if target.CurrentTarget == &character.Unit {
 			character.Unit.Metrics.isTanking = true
 		}
 	}
-	if !character.Unit.Metrics.isTanking {
+
+	// With deaths enabled every player tracks its health, otherwise only tanks with a healing model do.
+	canDie := deathModel.GetEnabled()
+	if !canDie && (!character.Unit.Metrics.isTanking || healingModel == nil) {
 		return
 	}
 
-	if healingModel == nil {
-		return
+	if healingModel != nil {
+		character.Unit.Metrics.tmiBin = healingModel.BurstWindow
 	}
+	if canDie {
+		character.enableDeaths(deathModel)
+	}
+
+	onDamageTaken := func(aura *Aura, sim *Simulation, result *SpellResult) {
+		if result.Damage <= 0 || !aura.Unit.IsEnabled() {
+			return
+		}
+
+		aura.Unit.RemoveHealth(sim, result.Damage)
 
-	character.Unit.Metrics.tmiBin = healingModel.BurstWindow
+		if aura.Unit.CurrentHealth() <= 0 {
+			if !aura.Unit.Metrics.Died {
+				aura.Unit.Metrics.Died = true
+				if sim.Log != nil {
+					character.Log(sim, "Dead")
+				}
+			}
+			if canDie {
+				character.die(sim)
+			}
+		}
+	}
 
 	character.RegisterAura(Aura{
 		Label:    ChanceOfDeathAuraLabel,
@@ -113,32 +137,14 @@ func (character *Character) trackChanceOfDeath(healingModel *proto.HealingModel)
 			aura.Activate(sim)
 		},
 		OnSpellHitTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
-			if result.Damage > 0 {
-				aura.Unit.RemoveHealth(sim, result.Damage)
-
-				if aura.Unit.CurrentHealth() <= 0 && !aura.Unit.Metrics.Died {
-					aura.Unit.Metrics.Died = true
-					if sim.Log != nil {
-						character.Log(sim, "Dead")
-					}
-				}
-			}
+			onDamageTaken(aura, sim, result)
 		},
 		OnPeriodicDamageTaken: func(aura *Aura, sim *Simulation, spell *Spell, result *SpellResult) {
-			if result.Damage > 0 {
-				aura.Unit.RemoveHealth(sim, result.Damage)
-
-				if aura.Unit.CurrentHealth() <= 0 && !aura.Unit.Metrics.Died {
-					aura.Unit.Metrics.Died = true
-					if sim.Log != nil {
-						character.Log(sim, "Dead")
-					}
-				}
-			}
+			onDamageTaken(aura, sim, result)
 		},
 	})
 
-	if healingModel.Hps != 0 {
+	if healingModel != nil && healingModel.Hps != 0 {
 		character.applyHealingModel(healingModel)
 	}
 }
@@ -171,17 +177,20 @@ func (character *Character) applyHealingModel(healingModel *proto.HealingModel)
 		}
 
 		pa.OnAction = func(sim *Simulation) {
-			// Use modeled HPS to scale heal per tick based on random cadence
-			healPerTick = healingModel.Hps * (float64(timeToNextHeal) / float64(time.Second))
-			totalHeal := healPerTick * character.PseudoStats.HealingTakenMultiplier
-			// Execute the heal
-			character.GainHealth(sim, totalHeal, healthMetrics)
-
-			// Callback that can be used by tank specs
-			result := healingModelSpell.NewResult(&character.Unit)
-			result.Damage = totalHeal
-			character.OnHealTaken(sim, healingModelSpell, result)
-			healingModelSpell.DisposeResult(result)
+			// Dead players can not be healed
+			if character.IsEnabled() {
+				// Use modeled HPS to scale heal per tick based on random cadence
+				healPerTick = healingModel.Hps * (float64(timeToNextHeal) / float64(time.Second))
+				totalHeal := healPerTick * character.PseudoStats.HealingTakenMultiplier
+				// Execute the heal
+				character.GainHealth(sim, totalHeal, healthMetrics)
+
+				// Callback that can be used by tank specs
+				result := healingModelSpell.NewResult(&character.Unit)
+				result.Damage = totalHeal
+				character.OnHealTaken(sim, healingModelSpell, result)
+				healingModelSpell.DisposeResult(result)
+			}
 
 			// Random roll for time to next heal. In the case where CadenceVariation exceeds CadenceSeconds, then
 			// CadenceSeconds is treated as the median, with two separate uniform distributions to the left and right
@@ -0,0 +1,59 @@
+package core
+
+import (
+	"time"
+)
+
+// Returns whether the unit is hard casting a spell which can be interrupted.
+func (unit *Unit) IsCastingInterruptible(sim *Simulation) bool {
+	return unit.IsCasting(sim) && unit.Hardcast.Spell != nil && unit.Hardcast.Spell.Flags.Matches(SpellFlagInterruptible)
+}
+
+// Returns whether spells of the given school are locked out by an interrupt.
+func (unit *Unit) IsSchoolLockedOut(sim *Simulation, school SpellSchool) bool {
+	return unit.interruptLockoutExpires > sim.CurrentTime && school.Matches(unit.interruptLockoutSchool)
+}
+
+// Interrupts the current cast of the unit, if it can be interrupted, and locks out
+// the school of the interrupted spell for the given duration. The interrupted spell
+// does not go on cooldown.
+// Successful interrupts and the expected damage of the interrupted cast are recorded
+// in the metrics of the interrupting spell. Returns whether a cast was interrupted.
+func (unit *Unit) Interrupt(sim *Simulation, interrupter *Spell, lockout time.Duration) bool {
+	if !unit.IsCastingInterruptible(sim) {
+		return false
+	}
+
+	hc := unit.Hardcast
+	interrupted := hc.Spell
+
+	damageAvoided := 0.0
+	if interrupted.expectedInitialDamageInternal != nil && hc.Target != nil {
+		damageAvoided = interrupted.ExpectedInitialDamage(sim, hc.Target)
+	}
+
+	if sim.Log != nil {
+		interrupter.Unit.Log(sim, "Interrupted %s of %s, locked out for %s", interrupted.ActionID, unit.Label, lockout)
+	}
+
+	unit.Hardcast = Hardcast{Expires: startingCDTime}
+	if unit.hardcastAction != nil {
+		unit.hardcastAction.Cancel(sim)
+	}
+	if interrupted.CD.Timer != nil {
+		interrupted.CD.Reset()
+	}
+
+	unit.interruptLockoutSchool = interrupted.SpellSchool
+	unit.interruptLockoutExpires = sim.CurrentTime + lockout
+
+	unit.SetGCDTimer(sim, sim.CurrentTime)
+
+	if !interrupter.Flags.Matches(SpellFlagNoMetrics) {
+		metrics := &interrupter.SpellMetrics[unit.UnitIndex]
+		metrics.Interrupts++
+		metrics.TotalDamageAvoided += damageAvoided
+	}
+
+	return true
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestInterruptTargetSpell(t *testing.T) {
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Caster",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{
+					Name:  "target",
+					Level: 63,
+					Spells: []*proto.TargetSpell{{
+						SpellId:       686,
+						SpellSchool:   proto.SpellSchool_SpellSchoolShadow,
+						CastTime:      3,
+						InitialDelay:  1,
+						MinDamage:     1000,
+						MaxDamage:     1000,
+						Interruptible: true,
+					}},
+				},
+			},
+			Duration: 180,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+
+	target := sim.Encounter.Targets[0]
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+
+	stepUntil := func(condition func() bool) {
+		for i := 0; i < 1000 && !condition(); i++ {
+			if sim.Step() {
+				break
+			}
+		}
+	}
+
+	stepUntil(func() bool { return target.IsCasting(sim) })
+	if sim.CurrentTime != time.Second || !target.IsCastingInterruptible(sim) {
+		t.Fatalf("Expected interruptible cast at 1s, got casting = %t at %s", target.IsCastingInterruptible(sim), sim.CurrentTime)
+	}
+
+	if !target.Interrupt(sim, fa.Spell, time.Second*4) {
+		t.Fatalf("Interrupt failed")
+	}
+	if target.IsCasting(sim) || target.Interrupt(sim, fa.Spell, time.Second*4) {
+		t.Fatalf("Target should no longer be casting")
+	}
+	if !target.IsSchoolLockedOut(sim, SpellSchoolShadow) || target.IsSchoolLockedOut(sim, SpellSchoolFire) {
+		t.Fatalf("Only the shadow school should be locked out")
+	}
+
+	metrics := fa.Spell.SpellMetrics[target.UnitIndex]
+	if metrics.Interrupts != 1 || metrics.TotalDamageAvoided <= 0 {
+		t.Fatalf("Unexpected interrupt metrics: %d interrupts, %0.1f damage avoided", metrics.Interrupts, metrics.TotalDamageAvoided)
+	}
+
+	// The next cast starts once the lockout has expired.
+	stepUntil(func() bool { return target.IsCasting(sim) })
+	if sim.CurrentTime != time.Second*5 {
+		t.Fatalf("Expected next cast at 5s, got %s", sim.CurrentTime)
+	}
+	if damage := target.spells[0].SpellMetrics[fa.UnitIndex].TotalDamage; damage != 0 {
+		t.Fatalf("Interrupted cast should not deal damage, got %0.1f", damage)
+	}
+}
@@ -0,0 +1,320 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+// Name of the implicit swap set holding the gear the character starts with.
+const MainItemSwapSetName = "Main"
+
+// Equipping an item with an on-use effect puts the effect on cooldown for at least this long.
+const ItemSwapOnUseCooldown = time.Second * 30
+
+type ItemSwapSet struct {
+	Name string
+
+	// Items of this set, slots without an item keep the item from the main set.
+	items Equipment
+}
+
+// Effects registered by an item, which are only active while the item is equipped.
+type itemSwapEffects struct {
+	auras  []*Aura  // Permanent auras, e.g. proc triggers.
+	spells []*Spell // On-use spells.
+}
+
+// Multiple named item sets, covering any slot, which can be swapped between during the rotation.
+// Unlike ItemSwap this also handles item effects: permanent auras of unequipped items are
+// deactivated and their on-use spells can not be cast.
+type ItemSwapSets struct {
+	character *Character
+
+	// The first set is always the main set.
+	sets  []*ItemSwapSet
+	slots []proto.ItemSlot // Slots which differ between sets.
+
+	current *ItemSwapSet
+	effects map[int32]*itemSwapEffects
+}
+
+func (character *Character) enableItemSwapSets(configs []*proto.ItemSwapSet) {
+	mainSet := &ItemSwapSet{
+		Name:  MainItemSwapSetName,
+		items: character.Equipment,
+	}
+	swap := ItemSwapSets{
+		sets:    []*ItemSwapSet{mainSet},
+		current: mainSet,
+		effects: make(map[int32]*itemSwapEffects),
+	}
+
+	for _, config := range configs {
+		if config.Name == "" || swap.GetSet(config.Name) != nil {
+			panic(fmt.Sprintf("Item swap set names must be unique and non-empty, got '%s'", config.Name))
+		}
+
+		set := &ItemSwapSet{Name: config.Name}
+		for i, itemSpec := range config.Items {
+			if i >= len(set.items) {
+				break
+			}
+			if itemSpec == nil || itemSpec.Id == 0 {
+				continue
+			}
+			set.items[i] = NewItem(ItemSpec{
+				ID:           itemSpec.Id,
+				RandomSuffix: itemSpec.RandomSuffix,
+				Enchant:      itemSpec.Enchant,
+				Rune:         itemSpec.Rune,
+			})
+		}
+		swap.sets = append(swap.sets, set)
+
+		for i := range set.items {
+			slot := proto.ItemSlot(i)
+			if !slices.Contains(swap.slots, slot) && !isSameItem(swap.itemFor(set, slot), mainSet.items[slot]) {
+				swap.slots = append(swap.slots, slot)
+			}
+		}
+	}
+	slices.Sort(swap.slots)
+
+	if len(swap.slots) == 0 {
+		return
+	}
+	character.ItemSwapSets = swap
+}
+
+// The character is copied after construction, so this needs to be called before the sets are used.
+func (swap *ItemSwapSets) initialize(character *Character) {
+	swap.character = character
+}
+
+func (swap *ItemSwapSets) IsEnabled() bool {
+	return len(swap.slots) > 0
+}
+
+// Returns the set with the given name, or nil if there is none.
+func (swap *ItemSwapSets) GetSet(name string) *ItemSwapSet {
+	for _, set := range swap.sets {
+		if set.Name == name {
+			return set
+		}
+	}
+	return nil
+}
+
+// Returns the currently equipped set.
+func (swap *ItemSwapSets) CurrentSet() *ItemSwapSet {
+	return swap.current
+}
+
+// Returns the item the set equips in the given slot.
+func (swap *ItemSwapSets) itemFor(set *ItemSwapSet, slot proto.ItemSlot) Item {
+	// Two-handers unequip the off hand.
+	if slot == proto.ItemSlot_ItemSlotOffHand && swap.itemFor(set, proto.ItemSlot_ItemSlotMainHand).HandType == proto.HandType_HandTypeTwoHand {
+		return Item{}
+	}
+	if set.items[slot].ID != 0 {
+		return set.items[slot]
+	}
+	return swap.sets[0].items[slot]
+}
+
+func (swap *ItemSwapSets) isEquipped(itemID int32) bool {
+	return slices.ContainsFunc(swap.character.Equipment[:], func(item Item) bool {
+		return item.ID == itemID
+	})
+}
+
+// Applies item effects of all items which are only part of swap sets. Each set is temporarily
+// equipped while doing so, because many effects check the slot their item is equipped in.
+func (swap *ItemSwapSets) applyItemEffects(agent Agent) {
+	character := swap.character
+	mainItems := character.Equipment
+	defer func() { character.Equipment = mainItems }()
+
+	appliedEnchants := make(map[proto.ItemSlot][]int32)
+	for _, slot := range swap.slots {
+		appliedEnchants[slot] = []int32{mainItems[slot].Enchant.EffectID}
+	}
+
+	for _, set := range swap.sets[1:] {
+		for _, slot := range swap.slots {
+			character.Equipment[slot] = swap.itemFor(set, slot)
+		}
+
+		for _, slot := range swap.slots {
+			item := character.Equipment[slot]
+			if _, ok := swap.effects[item.ID]; !ok && item.ID != 0 {
+				swap.trackItemEffects(item.ID, func() {
+					if applyItemEffect, ok := itemEffects[item.ID]; ok {
+						applyItemEffect(agent)
+					}
+				})
+			}
+
+			// Like ItemSwap, only weapon enchants are applied. They handle swaps through RegisterOnItemSwap.
+			effectID := item.Enchant.EffectID
+			if !isWeaponSlot(slot) || effectID == 0 || slices.Contains(appliedEnchants[slot], effectID) {
+				continue
+			}
+			appliedEnchants[slot] = append(appliedEnchants[slot], effectID)
+			if applyEnchantEffect, ok := enchantEffects[effectID]; ok {
+				applyEnchantEffect(agent)
+			}
+			if applyWeaponEffect, ok := weaponEffects[effectID]; ok {
+				applyWeaponEffect(agent, slot)
+			}
+		}
+
+		character.Equipment = mainItems
+	}
+}
+
+// Runs apply and records which permanent auras and on-use spells it registered for the item.
+func (swap *ItemSwapSets) trackItemEffects(itemID int32, apply func()) {
+	unit := &swap.character.Unit
+	numAuras, numSpells := len(unit.auras), len(unit.Spellbook)
+
+	apply()
+
+	effects := &itemSwapEffects{}
+	for _, aura := range unit.auras[numAuras:] {
+		if aura.Duration == NeverExpires {
+			effects.auras = append(effects.auras, aura)
+		}
+	}
+	for _, spell := range unit.Spellbook[numSpells:] {
+		if spell.ActionID.ItemID != itemID {
+			continue
+		}
+		effects.spells = append(effects.spells, spell)
+
+		extraCastCondition := spell.ExtraCastCondition
+		spell.ExtraCastCondition = func(sim *Simulation, target *Unit) bool {
+			return swap.isEquipped(itemID) && (extraCastCondition == nil || extraCastCondition(sim, target))
+		}
+	}
+	swap.effects[itemID] = effects
+}
+
+// Swaps to the given set, adjusting stats, weapons and item effects.
+func (swap *ItemSwapSets) SwapToSet(sim *Simulation, set *ItemSwapSet) {
+	if !swap.IsEnabled() || set == swap.current {
+		return
+	}
+
+	character := swap.character
+	if sim.Log != nil {
+		character.Log(sim, "Item Swap to set %s", set.Name)
+	}
+
+	var swappedOut, swappedIn []int32
+	newStats := stats.Stats{}
+	meleeWeaponSwapped := false
+	for _, slot := range swap.slots {
+		oldItem := character.Equipment[slot]
+		newItem := swap.itemFor(set, slot)
+		if isSameItem(oldItem, newItem) {
+			continue
+		}
+
+		character.Equipment[slot] = newItem
+		newStats = newStats.Add(itemSwapStats(newItem).Subtract(itemSwapStats(oldItem)))
+		swappedOut = append(swappedOut, oldItem.ID)
+		swappedIn = append(swappedIn, newItem.ID)
+
+		character.reequipWeapon(slot)
+		meleeWeaponSwapped = slot == proto.ItemSlot_ItemSlotMainHand || slot == proto.ItemSlot_ItemSlotOffHand || meleeWeaponSwapped
+	}
+	swap.current = set
+
+	character.AddStatsDynamic(sim, newStats)
+	if sim.Log != nil {
+		sim.Log("Item Swap Stats: %v", newStats)
+	}
+
+	for _, itemID := range swappedOut {
+		if effects, ok := swap.effects[itemID]; ok && !swap.isEquipped(itemID) {
+			for _, aura := range effects.auras {
+				aura.Deactivate(sim)
+			}
+		}
+	}
+	for _, itemID := range swappedIn {
+		if effects, ok := swap.effects[itemID]; ok {
+			for _, aura := range effects.auras {
+				aura.Activate(sim)
+			}
+			// The shared cooldown, e.g. of offensive trinkets, is triggered along with the item's own.
+			for _, spell := range effects.spells {
+				if spell.CD.Timer != nil {
+					spell.CD.Set(max(spell.CD.ReadyAt(), sim.CurrentTime+ItemSwapOnUseCooldown))
+				}
+				if spell.SharedCD.Timer != nil {
+					spell.SharedCD.Set(max(spell.SharedCD.ReadyAt(), sim.CurrentTime+ItemSwapOnUseCooldown))
+				}
+			}
+		}
+	}
+
+	for _, onSwap := range character.ItemSwap.onSwapCallbacks {
+		onSwap(sim)
+	}
+
+	if character.AutoAttacks.AutoSwingMelee && meleeWeaponSwapped && sim.CurrentTime > 0 {
+		character.AutoAttacks.StopMeleeUntil(sim, sim.CurrentTime, false)
+	}
+
+	// Same as ItemSwap, swapping uses the GCD unless it is done alongside a spell.
+	if character.GCD.IsReady(sim) {
+		character.SetGCDTimer(sim, sim.CurrentTime+1500*time.Millisecond)
+	}
+}
+
+// Re-equips the main set before the unit is reset. Stats are reset along with the unit.
+func (swap *ItemSwapSets) reset(_ *Simulation) {
+	if !swap.IsEnabled() || swap.current == swap.sets[0] {
+		return
+	}
+
+	for _, slot := range swap.slots {
+		swap.character.Equipment[slot] = swap.sets[0].items[slot]
+		swap.character.reequipWeapon(slot)
+	}
+	swap.current = swap.sets[0]
+}
+
+// Deactivates the permanent auras of items which are not equipped, after they were activated on reset.
+func (swap *ItemSwapSets) deactivateUnequippedEffects(sim *Simulation) {
+	if !swap.IsEnabled() {
+		return
+	}
+
+	for itemID, effects := range swap.effects {
+		if swap.isEquipped(itemID) {
+			continue
+		}
+		for _, aura := range effects.auras {
+			aura.Deactivate(sim)
+		}
+	}
+}
+
+func isSameItem(a Item, b Item) bool {
+	return a.ID == b.ID && a.Enchant.EffectID == b.Enchant.EffectID && a.RandomSuffix.ID == b.RandomSuffix.ID
+}
+
+func isWeaponSlot(slot proto.ItemSlot) bool {
+	return slot == proto.ItemSlot_ItemSlotMainHand || slot == proto.ItemSlot_ItemSlotOffHand || slot == proto.ItemSlot_ItemSlotRanged
+}
+
+func itemSwapStats(item Item) stats.Stats {
+	return item.Stats.Add(item.RandomSuffix.Stats).Add(item.Enchant.Stats)
+}
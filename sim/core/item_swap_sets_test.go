@@ -0,0 +1,124 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+func TestItemSwapSets(t *testing.T) {
+	const passiveTrinketID = 999901
+	const onUseTrinketID = 999902
+
+	ItemsByID[passiveTrinketID] = Item{ID: passiveTrinketID, Type: proto.ItemType_ItemTypeTrinket, Stats: stats.Stats{stats.Strength: 10}}
+	ItemsByID[onUseTrinketID] = Item{ID: onUseTrinketID, Type: proto.ItemType_ItemTypeTrinket, Stats: stats.Stats{stats.AttackPower: 20}}
+	defer delete(ItemsByID, passiveTrinketID)
+	defer delete(ItemsByID, onUseTrinketID)
+
+	itemEffects[passiveTrinketID] = func(agent Agent) {
+		MakePermanent(agent.GetCharacter().RegisterAura(Aura{
+			Label: "Passive Trinket",
+		}))
+	}
+	itemEffects[onUseTrinketID] = func(agent Agent) {
+		character := agent.GetCharacter()
+		character.RegisterSpell(SpellConfig{
+			ActionID: ActionID{ItemID: onUseTrinketID},
+			Flags:    SpellFlagNoOnCastComplete,
+			Cast: CastConfig{
+				CD: Cooldown{
+					Timer:    character.NewTimer(),
+					Duration: time.Minute * 2,
+				},
+				SharedCD: Cooldown{
+					Timer:    character.GetOffensiveTrinketCD(),
+					Duration: time.Second * 20,
+				},
+			},
+			ApplyEffects: func(_ *Simulation, _ *Unit, _ *Spell) {},
+		})
+	}
+	defer delete(itemEffects, passiveTrinketID)
+	defer delete(itemEffects, onUseTrinketID)
+
+	trinketSlot := proto.ItemSlot_ItemSlotTrinket1
+	equipment := &proto.EquipmentSpec{}
+	swapItems := []*proto.ItemSpec{}
+	for slot := proto.ItemSlot(0); slot < trinketSlot; slot++ {
+		equipment.Items = append(equipment.Items, &proto.ItemSpec{})
+		swapItems = append(swapItems, &proto.ItemSpec{})
+	}
+	equipment.Items = append(equipment.Items, &proto.ItemSpec{Id: passiveTrinketID})
+	swapItems = append(swapItems, &proto.ItemSpec{Id: onUseTrinketID})
+
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:         "Swapper",
+							Class:        proto.Class_ClassShaman,
+							Consumes:     &proto.Consumes{},
+							Buffs:        &proto.IndividualBuffs{},
+							Spec:         &proto.Player_ElementalShaman{},
+							Equipment:    equipment,
+							ItemSwapSets: []*proto.ItemSwapSet{{Name: "Burst", Items: swapItems}},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Name: "target", Level: 63}},
+			Duration: 180,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	swap := &fa.ItemSwapSets
+	passiveAura := fa.GetAura("Passive Trinket")
+	onUseSpell := fa.GetSpell(ActionID{ItemID: onUseTrinketID})
+
+	if !swap.IsEnabled() || swap.CurrentSet().Name != MainItemSwapSetName {
+		t.Fatalf("Expected swap sets to be enabled and start in the main set")
+	}
+	if !passiveAura.IsActive() || onUseSpell.CanCast(sim, fa.CurrentTarget) {
+		t.Fatalf("Only effects of the equipped trinket should be active")
+	}
+
+	strength, attackPower := fa.GetStat(stats.Strength), fa.GetStat(stats.AttackPower)
+	swap.SwapToSet(sim, swap.GetSet("Burst"))
+
+	if fa.Trinket1().ID != onUseTrinketID || swap.CurrentSet().Name != "Burst" {
+		t.Fatalf("Expected the on-use trinket to be equipped")
+	}
+	if fa.GetStat(stats.Strength) != strength-10 || fa.GetStat(stats.AttackPower) <= attackPower {
+		t.Fatalf("Stats were not swapped: %0.1f strength, %0.1f attack power", fa.GetStat(stats.Strength), fa.GetStat(stats.AttackPower))
+	}
+	if passiveAura.IsActive() {
+		t.Fatalf("Passive trinket aura should be inactive after swapping it out")
+	}
+	if onUseSpell.CD.ReadyAt() != sim.CurrentTime+ItemSwapOnUseCooldown || onUseSpell.SharedCD.ReadyAt() != sim.CurrentTime+ItemSwapOnUseCooldown {
+		t.Fatalf("Expected on-use and shared cooldowns to be triggered by the swap, ready at %s and %s", onUseSpell.CD.ReadyAt(), onUseSpell.SharedCD.ReadyAt())
+	}
+
+	// Resetting the sim re-equips the main set.
+	sim.Cleanup()
+	sim.Reset()
+	if fa.Trinket1().ID != passiveTrinketID || swap.CurrentSet().Name != MainItemSwapSetName || !passiveAura.IsActive() {
+		t.Fatalf("Expected the main set to be equipped after a reset")
+	}
+	if fa.GetStat(stats.Strength) != strength {
+		t.Fatalf("Expected initial stats after a reset, got %0.1f strength", fa.GetStat(stats.Strength))
+	}
+}
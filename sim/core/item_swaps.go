@@ -75,7 +75,7 @@ func (swap *ItemSwap) initialize(character *Character) {
 }
 
 func (character *Character) RegisterOnItemSwap(callback OnSwapItem) {
-	if character == nil || !character.ItemSwap.IsEnabled() {
+	if character == nil || !character.HasItemSwap() {
 		return
 	}
 
@@ -112,6 +112,11 @@ func (swap *ItemSwap) RegisterOnSwapItemForEffect(effectID int32, aura *Aura) {
 	})
 }
 
+// Returns whether the character can swap items, either through ItemSwap or ItemSwapSets.
+func (character *Character) HasItemSwap() bool {
+	return character.ItemSwap.IsEnabled() || character.ItemSwapSets.IsEnabled()
+}
+
 func (swap *ItemSwap) IsEnabled() bool {
 	return swap.character != nil && len(swap.slots) > 0
 }
@@ -202,7 +207,7 @@ func (swap *ItemSwap) swapItem(slot proto.ItemSlot, has2H bool) (bool, stats.Sta
 	}
 
 	swap.unEquippedItems[slot-offset] = oldItem
-	swap.swapWeapon(slot)
+	swap.character.reequipWeapon(slot)
 
 	return true, newStats
 }
@@ -214,9 +219,8 @@ func (swap *ItemSwap) getItemStats(item Item) stats.Stats {
 	return itemStats
 }
 
-func (swap *ItemSwap) swapWeapon(slot proto.ItemSlot) {
-	character := swap.character
-
+// Updates the auto attack weapon of the slot after its item changed.
+func (character *Character) reequipWeapon(slot proto.ItemSlot) {
 	switch slot {
 	case proto.ItemSlot_ItemSlotMainHand:
 		if character.AutoAttacks.AutoSwingMelee {
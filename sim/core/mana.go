@@ -21,6 +21,8 @@ type manaBar struct {
 	currentMana           float64
 	manaCastingMetrics    *ResourceMetrics
 	manaNotCastingMetrics *ResourceMetrics
+	manaMP5Metrics        *ResourceMetrics
+	innervateManaMetrics  *ResourceMetrics // Only set while Innervate is active.
 	JowManaMetrics        *ResourceMetrics
 	VtManaMetrics         *ResourceMetrics
 	JowiseManaMetrics     *ResourceMetrics
@@ -55,6 +57,7 @@ func (character *Character) EnableManaBarWithModifier(modifier float64) {
 
 	character.manaCastingMetrics = character.NewManaMetrics(ActionID{OtherID: proto.OtherAction_OtherActionManaRegen, Tag: 1})
 	character.manaNotCastingMetrics = character.NewManaMetrics(ActionID{OtherID: proto.OtherAction_OtherActionManaRegen, Tag: 2})
+	character.manaMP5Metrics = character.NewManaMetrics(ActionID{OtherID: proto.OtherAction_OtherActionManaRegen, Tag: 3})
 
 	character.BaseMana = character.GetBaseStats()[stats.Mana]
 	character.Unit.manaBar.unit = &character.Unit
@@ -76,13 +79,36 @@ func (unit *Unit) CurrentManaPercent() float64 {
 }
 
 func (unit *Unit) AddMana(sim *Simulation, amount float64, metrics *ResourceMetrics) {
+	unit.addMana(sim, amount, metrics)
+}
+
+// Part of a mana gain which is credited to its own metrics, see addMana.
+type manaGainPart struct {
+	amount  float64
+	metrics *ResourceMetrics
+}
+
+// Adds the mana at once, while the parts are credited to their own metrics and the rest to metrics.
+// Any overflow is shared by the parts in proportion to their amount.
+func (unit *Unit) addMana(sim *Simulation, amount float64, metrics *ResourceMetrics, parts ...manaGainPart) {
 	if amount < 0 {
 		panic("Trying to add negative mana!")
 	}
 
 	oldMana := unit.CurrentMana()
 	newMana := min(oldMana+amount, unit.MaxMana())
-	metrics.AddEvent(amount, newMana-oldMana)
+	rest := amount
+	for _, part := range parts {
+		if part.amount > 0 {
+			part.metrics.AddEvent(part.amount, part.amount*(newMana-oldMana)/amount)
+			rest -= part.amount
+		}
+	}
+	if rest == amount {
+		metrics.AddEvent(amount, newMana-oldMana)
+	} else {
+		metrics.AddEvent(rest, rest*(newMana-oldMana)/amount)
+	}
 
 	if sim.Log != nil {
 		unit.Log(sim, "Gained %0.3f mana from %s (%0.3f --> %0.3f).", amount, metrics.ActionID, oldMana, newMana)
@@ -154,41 +180,41 @@ func (unit *Unit) SpiritManaRegenPerSecondDefault() float64 {
 // Returns the rate of mana regen per second, assuming this unit is
 // considered to be casting.
 func (unit *Unit) ManaRegenPerSecondWhileCasting() float64 {
-	regenRate := unit.MP5ManaRegenPerSecond()
-
-	spiritRegen := unit.SpiritManaRegenPerSecondDefault()
-	if unit.SpiritManaRegenPerSecond != nil {
-		spiritRegen = unit.SpiritManaRegenPerSecond()
-	}
-	spiritRegenRate := 0.0
-	if unit.PseudoStats.SpiritRegenRateCasting != 0 || unit.PseudoStats.ForceFullSpiritRegen {
-		spiritRegenRate = spiritRegen * unit.PseudoStats.SpiritRegenMultiplier
-		if !unit.PseudoStats.ForceFullSpiritRegen {
-			spiritRegenRate *= unit.PseudoStats.SpiritRegenRateCasting
-		}
-	}
-	regenRate += spiritRegenRate
-
-	return regenRate
+	return unit.MP5ManaRegenPerSecond() + unit.spiritManaRegenPerSecondWhileCasting(unit.PseudoStats.SpiritRegenMultiplier, unit.PseudoStats.ForceFullSpiritRegen)
 }
 
 // Returns the rate of mana regen per second, assuming this unit is
 // considered to be not casting.
 func (unit *Unit) ManaRegenPerSecondWhileNotCasting() float64 {
-	regenRate := unit.MP5ManaRegenPerSecond()
+	return unit.MP5ManaRegenPerSecond() + unit.spiritManaRegenPerSecondWhileNotCasting(unit.PseudoStats.SpiritRegenMultiplier)
+}
 
-	spiritRegen := unit.SpiritManaRegenPerSecondDefault()
+func (unit *Unit) spiritManaRegenPerSecond() float64 {
 	if unit.SpiritManaRegenPerSecond != nil {
-		spiritRegen = unit.SpiritManaRegenPerSecond()
+		return unit.SpiritManaRegenPerSecond()
+	}
+	return unit.SpiritManaRegenPerSecondDefault()
+}
+
+func (unit *Unit) spiritManaRegenPerSecondWhileCasting(multiplier float64, forceFullRegen bool) float64 {
+	if unit.PseudoStats.SpiritRegenRateCasting == 0 && !forceFullRegen {
+		return 0
+	}
+	spiritRegenRate := unit.spiritManaRegenPerSecond() * multiplier
+	if !forceFullRegen {
+		spiritRegenRate *= unit.PseudoStats.SpiritRegenRateCasting
 	}
-	regenRate += spiritRegen * unit.PseudoStats.SpiritRegenMultiplier
+	return spiritRegenRate
+}
 
-	return regenRate
+func (unit *Unit) spiritManaRegenPerSecondWhileNotCasting(multiplier float64) float64 {
+	return unit.spiritManaRegenPerSecond() * multiplier
 }
 
 func (unit *Unit) UpdateManaRegenRates() {
 	unit.manaTickWhileCasting = unit.ManaRegenPerSecondWhileCasting() * 2
 	unit.manaTickWhileNotCasting = unit.ManaRegenPerSecondWhileNotCasting() * 2
+	unit.manaTickMP5 = unit.MP5ManaRegenPerSecond() * 2
 }
 
 func (unit *Unit) GetManaNotCastingMetrics() *ResourceMetrics {
@@ -197,13 +223,28 @@ func (unit *Unit) GetManaNotCastingMetrics() *ResourceMetrics {
 
 // Applies 1 'tick' of mana regen, which worth 2s of regeneration based on mp5/int/spirit/etc.
 func (unit *Unit) ManaTick(sim *Simulation) {
-	if sim.CurrentTime < unit.PseudoStats.FiveSecondRuleRefreshTime {
-		regen := unit.manaTickWhileCasting
-		unit.AddMana(sim, max(0, regen), unit.manaCastingMetrics)
-	} else {
-		regen := unit.manaTickWhileNotCasting
-		unit.AddMana(sim, max(0, regen), unit.manaNotCastingMetrics)
+	casting := sim.CurrentTime < unit.PseudoStats.FiveSecondRuleRefreshTime
+	regen, metrics := unit.manaTickWhileNotCasting, unit.manaNotCastingMetrics
+	if casting {
+		regen, metrics = unit.manaTickWhileCasting, unit.manaCastingMetrics
+	}
+
+	// The tick is only split into mp5, spirit and Innervate regen for the metrics. Negative mp5 is
+	// netted against the spirit regen, so it isn't credited at all.
+	mp5 := min(max(unit.manaTickMP5, 0), max(regen, 0))
+	var innervate float64
+	if unit.innervateManaMetrics != nil {
+		// Innervate gets the regen on top of what the unit would have without it.
+		withoutInnervate := unit.spiritManaRegenPerSecondWhileNotCasting(unit.PseudoStats.SpiritRegenMultiplier-InnervateSpiritRegenBonus) * 2
+		if casting {
+			withoutInnervate = unit.spiritManaRegenPerSecondWhileCasting(unit.PseudoStats.SpiritRegenMultiplier-InnervateSpiritRegenBonus, false) * 2
+		}
+		innervate = min(max(regen-unit.manaTickMP5-withoutInnervate, 0), max(regen, 0)-mp5)
 	}
+
+	unit.addMana(sim, max(0, regen), metrics,
+		manaGainPart{amount: mp5, metrics: unit.manaMP5Metrics},
+		manaGainPart{amount: innervate, metrics: unit.innervateManaMetrics})
 }
 
 // Returns the amount of time this Unit would need to wait in order to reach
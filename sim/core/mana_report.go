@@ -0,0 +1,123 @@
+package core
+
+import (
+	"cmp"
+	"math"
+	"slices"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Breaks down the mana resource metrics of the unit into spenders and sources of regen, averaged
+// per iteration, and suggests the filler casts to drop if the unit went OOM.
+func (unit *Unit) manaMetricsToProto(secondsOomAvg float64) *proto.ManaMetrics {
+	unitMetrics := &unit.Metrics
+	n := float64(unitMetrics.dps.n)
+	if n == 0 {
+		return nil
+	}
+
+	manaMetrics := &proto.ManaMetrics{}
+	for _, resource := range unitMetrics.resources {
+		// Free casts and empty ticks don't move any mana.
+		if resource.Type != proto.ResourceType_ResourceTypeMana || resource.Gain == 0 {
+			continue
+		}
+
+		if resource.Gain < 0 {
+			actionMetrics := &proto.ManaActionMetrics{
+				Id:           resource.ActionID.ToProto(),
+				CastsAvg:     float64(resource.Events) / n,
+				ManaSpentAvg: -resource.Gain / n,
+			}
+			for actionID, action := range unitMetrics.actions {
+				// Spells often deal their damage through hit spells, tagged e.g. by hand.
+				if actionID == resource.ActionID || (resource.ActionID.Tag == 0 && actionID.SameActionIgnoreTag(resource.ActionID)) {
+					for _, tam := range action.Targets {
+						actionMetrics.DamageAvg += (tam.Damage + tam.Healing + tam.Shielding) / n
+					}
+				}
+			}
+			actionMetrics.DamagePerMana = actionMetrics.DamageAvg / actionMetrics.ManaSpentAvg
+			manaMetrics.SpentAvg += actionMetrics.ManaSpentAvg
+			manaMetrics.Actions = append(manaMetrics.Actions, actionMetrics)
+		} else {
+			manaMetrics.Sources = append(manaMetrics.Sources, &proto.ManaSourceMetrics{
+				Id:          resource.ActionID.ToProto(),
+				Source:      unit.manaSource(resource),
+				GainAvg:     resource.ActualGain / n,
+				OverflowAvg: (resource.Gain - resource.ActualGain) / n,
+			})
+			manaMetrics.GainedAvg += resource.ActualGain / n
+		}
+	}
+
+	// Resources are in registration order, so sort by amount.
+	sortManaMetrics(manaMetrics)
+
+	if secondsOomAvg > 0 {
+		ttoAvg, _ := unitMetrics.tto.meanAndStdDev()
+		manaMetrics.FillerSuggestion = manaFillerSuggestion(manaMetrics, secondsOomAvg, unitMetrics.durationSum/n, ttoAvg)
+	}
+	return manaMetrics
+}
+
+// Sorts the spenders and sources by amount, with the source as tie breaker.
+func sortManaMetrics(manaMetrics *proto.ManaMetrics) {
+	slices.SortStableFunc(manaMetrics.Actions, func(a, b *proto.ManaActionMetrics) int {
+		return cmp.Compare(b.ManaSpentAvg, a.ManaSpentAvg)
+	})
+	slices.SortStableFunc(manaMetrics.Sources, func(a, b *proto.ManaSourceMetrics) int {
+		if a.GainAvg != b.GainAvg {
+			return cmp.Compare(b.GainAvg, a.GainAvg)
+		}
+		return int(a.Source - b.Source)
+	})
+}
+
+func (unit *Unit) manaSource(resource *ResourceMetrics) proto.ManaSourceMetrics_Source {
+	switch {
+	case resource == unit.manaCastingMetrics:
+		return proto.ManaSourceMetrics_SourceSpiritInFsr
+	case resource == unit.manaNotCastingMetrics:
+		return proto.ManaSourceMetrics_SourceSpiritOutsideFsr
+	case resource == unit.manaMP5Metrics:
+		return proto.ManaSourceMetrics_SourceMp5
+	case resource == unit.JowManaMetrics:
+		return proto.ManaSourceMetrics_SourceJudgementOfWisdom
+	case resource.ActionID.SpellID == 29166:
+		return proto.ManaSourceMetrics_SourceInnervate
+	}
+	if spell := unit.GetSpell(resource.ActionID); spell != nil && spell.Flags.Matches(SpellFlagPotion) {
+		return proto.ManaSourceMetrics_SourcePotion
+	}
+	return proto.ManaSourceMetrics_SourceOther
+}
+
+// The mana missing while OOM is estimated from the rate at which mana is spent while not OOM, and
+// dropping casts of the least efficient damaging spender is the cheapest way to save it.
+func manaFillerSuggestion(manaMetrics *proto.ManaMetrics, secondsOomAvg float64, durationAvg float64, ttoAvg float64) *proto.ManaFillerSuggestion {
+	var filler *proto.ManaActionMetrics
+	for _, action := range manaMetrics.Actions {
+		// Spenders without damage are buffs and cooldowns rather than fillers.
+		if action.DamageAvg > 0 && (filler == nil || action.DamagePerMana < filler.DamagePerMana) {
+			filler = action
+		}
+	}
+	secondsNotOom := durationAvg - secondsOomAvg
+	if filler == nil || secondsNotOom <= 0 {
+		return nil
+	}
+
+	manaMissing := secondsOomAvg * manaMetrics.SpentAvg / secondsNotOom
+	manaPerCast := filler.ManaSpentAvg / filler.CastsAvg
+	dropCasts := min(math.Ceil(manaMissing/manaPerCast), filler.CastsAvg)
+
+	return &proto.ManaFillerSuggestion{
+		Id:            filler.Id,
+		DropCastsAvg:  dropCasts,
+		BeforeSeconds: min(ttoAvg, durationAvg),
+		ManaSavedAvg:  dropCasts * manaPerCast,
+		DamageLostAvg: dropCasts * filler.DamageAvg / filler.CastsAvg,
+	}
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+func TestManaReport(t *testing.T) {
+	const manaItemID = 999922
+
+	ItemsByID[manaItemID] = Item{ID: manaItemID, Type: proto.ItemType_ItemTypeTrinket, Stats: stats.Stats{stats.Intellect: 200, stats.Spirit: 100, stats.MP5: 50}}
+	defer delete(ItemsByID, manaItemID)
+	var innervate *Aura
+	itemEffects[manaItemID] = func(agent Agent) {
+		character := agent.GetCharacter()
+		character.EnableManaBar()
+		innervate = InnervateAura(character, 1)
+		// The filler does less damage per mana than the nuke.
+		for i, damage := range []float64{150, 300} {
+			damage := damage
+			character.RegisterSpell(SpellConfig{
+				ActionID:    ActionID{SpellID: int32(1000 + i)},
+				SpellSchool: SpellSchoolFire,
+				ProcMask:    ProcMaskSpellDamage,
+				Flags:       SpellFlagIgnoreResists,
+				ManaCost: ManaCostOptions{
+					FlatCost: 100,
+				},
+				DamageMultiplier: 1,
+				ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+					spell.CalcAndDealDamage(sim, target, damage, spell.OutcomeAlwaysHit)
+				},
+			})
+		}
+	}
+	defer delete(itemEffects, manaItemID)
+
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Caster",
+							Class:     proto.Class_ClassShaman,
+							Level:     60,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{Items: []*proto.ItemSpec{{}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {Id: manaItemID}}},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Name: "target", Level: 63}},
+			Duration: 60,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	// Mana is set with metrics which aren't registered, so they don't show up in the report.
+	setMana := &ResourceMetrics{}
+	fa.SpendMana(sim, fa.CurrentMana(), setMana)
+	mp5Tick := fa.MP5ManaRegenPerSecond() * 2
+	spiritTick := fa.spiritManaRegenPerSecond() * 2
+	if mp5Tick != 20 || spiritTick <= 0 {
+		t.Fatalf("Expected mp5 ticks of 20 and spirit ticks, got %0.2f and %0.2f", mp5Tick, spiritTick)
+	}
+
+	// Shamans have no spirit regen while casting, so only mp5 ticks inside the five second rule.
+	fa.PseudoStats.FiveSecondRuleRefreshTime = sim.CurrentTime + time.Second*5
+	fa.ManaTick(sim)
+	fa.PseudoStats.FiveSecondRuleRefreshTime = sim.CurrentTime - time.Second
+	fa.ManaTick(sim)
+	if fa.manaMP5Metrics.Gain != 2*mp5Tick || fa.manaNotCastingMetrics.Gain != spiritTick || fa.manaCastingMetrics.Gain != 0 {
+		t.Fatalf("Unexpected regen split: mp5 %0.2f, spirit %0.2f, spirit in FSR %0.2f",
+			fa.manaMP5Metrics.Gain, fa.manaNotCastingMetrics.Gain, fa.manaCastingMetrics.Gain)
+	}
+
+	// Innervate gets the spirit regen on top of the regular regen. Inside the five second rule that's
+	// all of the spirit regen, as there is none without Innervate.
+	innervate.Activate(sim)
+	fa.PseudoStats.FiveSecondRuleRefreshTime = sim.CurrentTime + time.Second*5
+	fa.ManaTick(sim)
+	innervate.Deactivate(sim)
+	if fa.manaCastingMetrics.Gain != 0 || fa.manaMP5Metrics.Events != 3 {
+		t.Fatalf("Expected no spirit regen inside the five second rule without Innervate")
+	}
+
+	// Ticks are added at once, so negative mp5 is netted against the spirit regen.
+	fa.PseudoStats.FiveSecondRuleRefreshTime = sim.CurrentTime - time.Second
+	startMana := fa.CurrentMana()
+	fa.AddStatDynamic(sim, stats.MP5, -100)
+	fa.UpdateManaRegenRates()
+	fa.ManaTick(sim)
+	fa.AddStatDynamic(sim, stats.MP5, 100)
+	fa.UpdateManaRegenRates()
+	if gain := fa.CurrentMana() - startMana; math.Abs(gain-(spiritTick-20)) > 1e-6 || fa.manaMP5Metrics.Events != 3 {
+		t.Fatalf("Expected %0.2f mana from spirit minus mp5, got %0.2f", spiritTick-20, gain)
+	}
+
+	filler, nuke := fa.GetSpell(ActionID{SpellID: 1000}), fa.GetSpell(ActionID{SpellID: 1001})
+	fa.AddMana(sim, 1000, setMana)
+	filler.Cast(sim, fa.CurrentTarget)
+	filler.Cast(sim, fa.CurrentTarget)
+	nuke.Cast(sim, fa.CurrentTarget)
+
+	sim.Cleanup()
+	mana := fa.GetMetricsProto().Mana
+	if mana == nil || len(mana.Actions) != 2 || mana.SpentAvg != 300 {
+		t.Fatalf("Unexpected mana metrics %v", mana)
+	}
+	if !ProtoToActionID(mana.Actions[0].Id).SameAction(filler.ActionID) || mana.Actions[0].DamagePerMana != 1.5 || mana.Actions[1].DamagePerMana != 3 {
+		t.Fatalf("Expected the filler to spend the most mana at 1.5 damage per mana, got %v", mana.Actions)
+	}
+
+	gains := make(map[proto.ManaSourceMetrics_Source]float64)
+	for _, source := range mana.Sources {
+		gains[source.Source] += source.GainAvg
+	}
+	if gains[proto.ManaSourceMetrics_SourceMp5] != 3*mp5Tick || math.Abs(gains[proto.ManaSourceMetrics_SourceInnervate]-spiritTick*(1+InnervateSpiritRegenBonus)) > 1e-6 {
+		t.Fatalf("Unexpected mana sources %v", gains)
+	}
+
+	// 6s OOM in a 60s fight means missing 300 / 54 * 6 mana, i.e. dropping 1 filler cast.
+	suggestion := manaFillerSuggestion(mana, 6, 60, 60)
+	if suggestion == nil || !ProtoToActionID(suggestion.Id).SameAction(filler.ActionID) || suggestion.DropCastsAvg != 1 || suggestion.DamageLostAvg != 150 {
+		t.Fatalf("Unexpected filler suggestion %v", suggestion)
+	}
+}
+
+func TestManaReportConcurrent(t *testing.T) {
+	const manaItemID = 999923
+
+	ItemsByID[manaItemID] = Item{ID: manaItemID, Type: proto.ItemType_ItemTypeTrinket, Stats: stats.Stats{stats.Intellect: 200, stats.MP5: 50}}
+	defer delete(ItemsByID, manaItemID)
+	itemEffects[manaItemID] = func(agent Agent) {
+		character := agent.GetCharacter()
+		character.EnableManaBar()
+		character.RegisterSpell(SpellConfig{
+			ActionID:    ActionID{SpellID: 1000},
+			SpellSchool: SpellSchoolFire,
+			ProcMask:    ProcMaskSpellDamage,
+			Flags:       SpellFlagAPL,
+			ManaCost: ManaCostOptions{
+				FlatCost: 200,
+			},
+			Cast: CastConfig{
+				DefaultCast: Cast{
+					GCD: GCDDefault,
+				},
+			},
+			DamageMultiplier: 1,
+			ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+				spell.CalcAndDealDamage(sim, target, 150, spell.OutcomeMagicHit)
+			},
+		})
+	}
+	defer delete(itemEffects, manaItemID)
+
+	request := &proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			Iterations: 6,
+			RandomSeed: 100,
+			IsTest:     true,
+		},
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Name:      "Caster",
+			Class:     proto.Class_ClassShaman,
+			Level:     60,
+			Consumes:  &proto.Consumes{},
+			Spec:      &proto.Player_ElementalShaman{},
+			Equipment: &proto.EquipmentSpec{Items: []*proto.ItemSpec{{}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {Id: manaItemID}}},
+			Rotation: &proto.APLRotation{
+				Type: proto.APLRotation_TypeAPL,
+				PriorityList: []*proto.APLListItem{{Action: &proto.APLAction{
+					Action: &proto.APLAction_CastSpell{CastSpell: &proto.APLActionCastSpell{SpellId: ActionID{SpellID: 1000}.ToProto()}},
+				}}},
+			},
+		}, &proto.PartyBuffs{}, &proto.RaidBuffs{}, &proto.Debuffs{}),
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Name: "target", Level: 63}},
+			Duration: 120,
+		},
+	}
+
+	result := runSimConcurrent(request, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Sim failed: %s", result.Error.Message)
+	}
+	// Splits continue the seeds of each other, so the combined result matches a single run.
+	mana := result.RaidMetrics.Parties[0].Players[0].Mana
+	expected := RunRaidSim(request).RaidMetrics.Parties[0].Players[0].Mana
+	if mana == nil || expected.FillerSuggestion == nil {
+		t.Fatalf("Expected the caster to go OOM, got %v and %v", mana, expected)
+	}
+	if math.Abs(mana.SpentAvg-expected.SpentAvg) > 1e-6 || math.Abs(mana.GainedAvg-expected.GainedAvg) > 1e-6 ||
+		len(mana.Actions) != len(expected.Actions) || len(mana.Sources) != len(expected.Sources) {
+		t.Fatalf("Expected combined mana metrics %v, got %v", expected, mana)
+	}
+	if math.Abs(mana.Actions[0].DamagePerMana-expected.Actions[0].DamagePerMana) > 1e-6 ||
+		mana.FillerSuggestion == nil || math.Abs(mana.FillerSuggestion.DropCastsAvg-expected.FillerSuggestion.DropCastsAvg) > 1e-6 {
+		t.Fatalf("Expected combined filler suggestion %v, got %v", expected.FillerSuggestion, mana.FillerSuggestion)
+	}
+}
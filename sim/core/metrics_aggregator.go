@@ -2,6 +2,7 @@ package core
 
 import (
 	"math"
+	"slices"
 	"time"
 
 	"github.com/wowsims/classic/sim/core/proto"
@@ -91,6 +92,9 @@ type UnitMetrics struct {
 	hps    DistributionMetrics
 	tto    DistributionMetrics
 
+	timeAlive DistributionMetrics
+	canDie    bool
+
 	tmiList   []tmiListItem
 	isTanking bool
 	tmiBin    int32
@@ -99,9 +103,21 @@ type UnitMetrics struct {
 
 	// Aggregate values. These are updated after each iteration.
 	numItersDead int32
+	numDeaths    int32
 	oomTimeSum   float64
+	durationSum  float64 // Sum of the fight lengths, in seconds.
 	actions      map[ActionID]*ActionMetrics
 	resources    []*ResourceMetrics
+
+	timeSeries *timeSeriesMetrics // Only set if time series are enabled.
+	phases     *phaseMetrics      // Only set if phase metrics are enabled.
+
+	damageTaken map[damageTakenKey]float64 // Damage taken from opponents, over all iterations.
+}
+
+type damageTakenKey struct {
+	attacker *Unit
+	school   SpellSchool
 }
 
 // Metrics for the current iteration, for 1 agent. Keep this as a separate
@@ -110,6 +126,10 @@ type CharacterIterationMetrics struct {
 	Died    bool // Whether this unit died in the current iteration.
 	WentOOM bool // Whether the agent has hit OOM at least once in this iteration.
 
+	Deaths   int32         // Number of actual deaths, only counted if deaths are enabled.
+	DiedAt   time.Duration // Timestamp of the last death.
+	TimeDead time.Duration // Time spent dead in this iteration.
+
 	ManaSpent  float64
 	ManaGained float64
 
@@ -123,6 +143,10 @@ type ActionMetrics struct {
 	IsPassive   bool // True if action is applied/cast as a result of another action
 	SpellSchool SpellSchool
 
+	// Used to group the ranks of spells, see Unit.SpellRanks.
+	SpellCode int32
+	Rank      int
+
 	// Metrics for this action, for each possible target.
 	Targets []TargetedActionMetrics
 }
@@ -184,6 +208,9 @@ type SpellMetrics struct {
 	TotalCritHealing            float64 // Healing done by all critical casts of this spell.
 	TotalShielding              float64 // Shielding done by all casts of this spell.
 	TotalCastTime               time.Duration
+
+	Interrupts         int32   // Casts of the target interrupted by this spell.
+	TotalDamageAvoided float64 // Expected damage of all casts interrupted by this spell.
 }
 
 type TargetedActionMetrics struct {
@@ -221,6 +248,8 @@ type TargetedActionMetrics struct {
 	CritHealing            float64
 	Shielding              float64
 	CastTime               time.Duration
+	Interrupts             int32
+	DamageAvoided          float64
 }
 
 func (tam *TargetedActionMetrics) ToProto(unitIndex int32) *proto.TargetedActionMetrics {
@@ -260,19 +289,22 @@ func (tam *TargetedActionMetrics) ToProto(unitIndex int32) *proto.TargetedAction
 		CritHealing:            tam.CritHealing,
 		Shielding:              tam.Shielding,
 		CastTimeMs:             float64(tam.CastTime.Milliseconds()),
+		Interrupts:             tam.Interrupts,
+		DamageAvoided:          tam.DamageAvoided,
 	}
 }
 
 func NewUnitMetrics() UnitMetrics {
 	return UnitMetrics{
-		dps:     NewDistributionMetrics(),
-		dpasp:   NewDistributionMetrics(),
-		threat:  NewDistributionMetrics(),
-		dtps:    NewDistributionMetrics(),
-		tmi:     NewDistributionMetrics(),
-		hps:     NewDistributionMetrics(),
-		tto:     NewDistributionMetrics(),
-		actions: make(map[ActionID]*ActionMetrics),
+		dps:       NewDistributionMetrics(),
+		dpasp:     NewDistributionMetrics(),
+		threat:    NewDistributionMetrics(),
+		dtps:      NewDistributionMetrics(),
+		tmi:       NewDistributionMetrics(),
+		hps:       NewDistributionMetrics(),
+		tto:       NewDistributionMetrics(),
+		timeAlive: NewDistributionMetrics(),
+		actions:   make(map[ActionID]*ActionMetrics),
 	}
 }
 
@@ -378,6 +410,8 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 			IsMelee:     spell.Flags.Matches(SpellFlagMeleeMetrics),
 			IsPassive:   spell.Flags.Matches(SpellFlagPassiveSpell),
 			SpellSchool: spell.SpellSchool,
+			SpellCode:   spell.SpellCode,
+			Rank:        spell.Rank,
 			Targets:     make([]TargetedActionMetrics, len(spellMetrics)),
 		}
 		unitMetrics.actions[actionID] = actionMetrics
@@ -422,6 +456,8 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 		if !spell.Flags.Matches(SpellFlagPassiveSpell) {
 			tam.CastTime += spellTargetMetrics.TotalCastTime
 		}
+		tam.Interrupts += spellTargetMetrics.Interrupts
+		tam.DamageAvoided += spellTargetMetrics.TotalDamageAvoided
 
 		target := spell.Unit.Env.AllUnits[i]
 		target.Metrics.dtps.Total += spellTargetMetrics.TotalDamage
@@ -429,12 +465,58 @@ func (unitMetrics *UnitMetrics) addSpellMetrics(spell *Spell, actionID ActionID,
 		if spell.Unit.IsOpponent(target) {
 			unitMetrics.dps.Total += spellTargetMetrics.TotalDamage
 			unitMetrics.threat.Total += spellTargetMetrics.TotalThreat
+			if spellTargetMetrics.TotalDamage > 0 {
+				target.Metrics.addDamageTaken(spell.Unit, spell.SpellSchool, spellTargetMetrics.TotalDamage)
+			}
 		} else {
 			unitMetrics.hps.Total += spellTargetMetrics.TotalHealing + spellTargetMetrics.TotalShielding
 		}
 	}
 }
 
+func (unitMetrics *UnitMetrics) addDamageTaken(attacker *Unit, school SpellSchool, damage float64) {
+	if unitMetrics.damageTaken == nil {
+		unitMetrics.damageTaken = make(map[damageTakenKey]float64)
+	}
+	unitMetrics.damageTaken[damageTakenKey{attacker: attacker, school: school}] += damage
+}
+
+// Returns the damage taken per iteration by school and class of the attacker, with pets
+// counting towards the class of their owner.
+func (unitMetrics *UnitMetrics) damageTakenToProto(raid *Raid) []*proto.DamageTakenMetrics {
+	type classSchool struct {
+		class  proto.Class
+		school SpellSchool
+	}
+	damageTaken := make(map[classSchool]float64)
+	for key, damage := range unitMetrics.damageTaken {
+		class := proto.Class_ClassUnknown
+		switch agent := raid.GetPlayerFromUnit(key.attacker).(type) {
+		case PetAgent:
+			class = agent.GetPet().Owner.Class
+		case Agent:
+			class = agent.GetCharacter().Class
+		}
+		damageTaken[classSchool{class: class, school: key.school}] += damage
+	}
+
+	metrics := make([]*proto.DamageTakenMetrics, 0, len(damageTaken))
+	for key, damage := range damageTaken {
+		metrics = append(metrics, &proto.DamageTakenMetrics{
+			SpellSchool: int32(key.school),
+			Class:       key.class,
+			DamageAvg:   damage / float64(unitMetrics.dps.n),
+		})
+	}
+	slices.SortFunc(metrics, func(a, b *proto.DamageTakenMetrics) int {
+		if a.Class != b.Class {
+			return int(a.Class - b.Class)
+		}
+		return int(a.SpellSchool - b.SpellSchool)
+	})
+	return metrics
+}
+
 // This should be called at the end of each iteration, to include metrics from Pets in
 // those of their owner.
 // Assumes that doneIteration() has already been called on the pet metrics.
@@ -469,6 +551,7 @@ func (unitMetrics *UnitMetrics) reset() {
 	unitMetrics.tmiList = nil
 	unitMetrics.hps.reset()
 	unitMetrics.tto.reset()
+	unitMetrics.timeAlive.reset()
 	unitMetrics.CharacterIterationMetrics = CharacterIterationMetrics{}
 
 	for _, resourceMetrics := range unitMetrics.resources {
@@ -514,10 +597,28 @@ func (unitMetrics *UnitMetrics) doneIteration(unit *Unit, sim *Simulation) {
 	unitMetrics.hps.doneIteration(sim)
 	unitMetrics.tto.doneIteration(sim)
 
+	if unitMetrics.canDie {
+		timeDead := unitMetrics.TimeDead
+		if !unit.IsEnabled() {
+			timeDead += sim.Duration - unitMetrics.DiedAt
+		}
+		unitMetrics.timeAlive.Total = (sim.Duration - timeDead).Seconds()
+
+		// Hack because of the way DistributionMetrics does its calculations.
+		unitMetrics.timeAlive.Total *= sim.Duration.Seconds()
+		unitMetrics.timeAlive.doneIteration(sim)
+	}
+
+	if unitMetrics.timeSeries != nil {
+		unitMetrics.timeSeries.doneIteration(sim)
+	}
+
 	unitMetrics.oomTimeSum += unitMetrics.OOMTime.Seconds()
+	unitMetrics.durationSum += sim.Duration.Seconds()
 	if unitMetrics.Died {
 		unitMetrics.numItersDead++
 	}
+	unitMetrics.numDeaths += unitMetrics.Deaths
 }
 
 func (unitMetrics *UnitMetrics) calculateTMI(unit *Unit, sim *Simulation) float64 {
@@ -604,12 +705,28 @@ func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 		Tto:           unitMetrics.tto.ToProto(),
 		SecondsOomAvg: unitMetrics.oomTimeSum / n,
 		ChanceOfDeath: float64(unitMetrics.numItersDead) / n,
+		DeathsAvg:     float64(unitMetrics.numDeaths) / n,
+	}
+	if unitMetrics.canDie {
+		protoMetrics.TimeAlive = unitMetrics.timeAlive.ToProto()
+	}
+	if unitMetrics.timeSeries != nil {
+		protoMetrics.TimeSeries = unitMetrics.timeSeries.ToProto()
+	}
+
+	if unitMetrics.phases != nil {
+		protoMetrics.Phases = unitMetrics.phases.ToProto()
 	}
 
 	protoMetrics.Actions = make([]*proto.ActionMetrics, 0, len(unitMetrics.actions))
 	for actionID, action := range unitMetrics.actions {
-		protoMetrics.Actions = append(protoMetrics.Actions, action.ToProto(actionID))
+		actionMetrics := action.ToProto(actionID)
+		if unitMetrics.phases != nil {
+			actionMetrics.Phases = unitMetrics.phases.actionToProto(actionID)
+		}
+		protoMetrics.Actions = append(protoMetrics.Actions, actionMetrics)
 	}
+	protoMetrics.SpellFamilies = unitMetrics.spellFamiliesToProto()
 
 	protoMetrics.Resources = make([]*proto.ResourceMetrics, 0, len(unitMetrics.resources))
 	for _, resource := range unitMetrics.resources {
@@ -621,27 +738,103 @@ func (unitMetrics *UnitMetrics) ToProto() *proto.UnitMetrics {
 	return protoMetrics
 }
 
+// Sums up the metrics of spells with ranks for each spell code, with a breakdown by rank.
+func (unitMetrics *UnitMetrics) spellFamiliesToProto() []*proto.SpellFamilyMetrics {
+	var actionIDs []ActionID
+	for actionID, action := range unitMetrics.actions {
+		if action.SpellCode != 0 && action.Rank > 0 {
+			actionIDs = append(actionIDs, actionID)
+		}
+	}
+	// Sort by family and highest rank first, as map iteration order is random.
+	slices.SortFunc(actionIDs, func(a, b ActionID) int {
+		actionA, actionB := unitMetrics.actions[a], unitMetrics.actions[b]
+		if actionA.SpellCode != actionB.SpellCode {
+			return int(actionA.SpellCode - actionB.SpellCode)
+		} else if actionA.Rank != actionB.Rank {
+			return actionB.Rank - actionA.Rank
+		}
+		return int(a.Tag - b.Tag)
+	})
+
+	var families []*proto.SpellFamilyMetrics
+	var family *proto.SpellFamilyMetrics
+	for i, actionID := range actionIDs {
+		action := unitMetrics.actions[actionID]
+		if i == 0 || action.SpellCode != unitMetrics.actions[actionIDs[i-1]].SpellCode {
+			family = &proto.SpellFamilyMetrics{Id: actionID.ToProto()}
+			families = append(families, family)
+		}
+
+		rankMetrics := &proto.SpellRankMetrics{
+			Id:   actionID.ToProto(),
+			Rank: int32(action.Rank),
+		}
+		for _, tam := range action.Targets {
+			rankMetrics.Casts += tam.Casts
+			rankMetrics.Damage += tam.Damage
+			rankMetrics.Healing += tam.Healing + tam.Shielding
+			rankMetrics.Threat += tam.Threat
+		}
+		family.Casts += rankMetrics.Casts
+		family.Damage += rankMetrics.Damage
+		family.Healing += rankMetrics.Healing
+		family.Threat += rankMetrics.Threat
+		family.Ranks = append(family.Ranks, rankMetrics)
+	}
+	return families
+}
+
 type AuraMetrics struct {
 	ID ActionID
 
 	// Metrics for the current iteration.
-	Uptime time.Duration
-	Procs  int32
+	Uptime          time.Duration
+	Procs           int32
+	StackSeconds    float64
+	MaxStacksUptime time.Duration
+	StacksConsumed  int32
+
+	lastStacksChange time.Duration
 
 	// Aggregate values. These are updated after each iteration.
 	aggregator
-	procsSum int32
+	procsSum           int32
+	stackSecondsSum    float64
+	maxStacksUptimeSum float64
+	stacksConsumedSum  int32
 }
 
 func (auraMetrics *AuraMetrics) reset() {
 	auraMetrics.Uptime = 0
 	auraMetrics.Procs = 0
+	auraMetrics.StackSeconds = 0
+	auraMetrics.MaxStacksUptime = 0
+	auraMetrics.StacksConsumed = 0
+	auraMetrics.lastStacksChange = 0
+}
+
+// Adds the current stacks of the aura since their last change, up to the given time.
+func (auraMetrics *AuraMetrics) addStackTime(aura *Aura, at time.Duration) {
+	at = max(at, 0)
+	if at <= auraMetrics.lastStacksChange {
+		return
+	}
+	elapsed := at - auraMetrics.lastStacksChange
+	auraMetrics.StackSeconds += float64(aura.stacks) * elapsed.Seconds()
+	if aura.stacks > 0 && aura.stacks == aura.MaxStacks {
+		auraMetrics.MaxStacksUptime += elapsed
+	}
+	auraMetrics.lastStacksChange = at
 }
 
 // This should be called when a Sim iteration is complete.
 func (auraMetrics *AuraMetrics) doneIteration() {
 	auraMetrics.add(auraMetrics.Uptime.Seconds())
 	auraMetrics.procsSum += auraMetrics.Procs
+	auraMetrics.stackSecondsSum += auraMetrics.StackSeconds
+	auraMetrics.maxStacksUptimeSum += auraMetrics.MaxStacksUptime.Seconds()
+	auraMetrics.stacksConsumedSum += auraMetrics.StacksConsumed
 }
 
 func (auraMetrics *AuraMetrics) ToProto() *proto.AuraMetrics {
@@ -654,6 +847,10 @@ func (auraMetrics *AuraMetrics) ToProto() *proto.AuraMetrics {
 		UptimeSecondsStdev: stdev,
 		ProcsAvg:           float64(auraMetrics.procsSum) / float64(auraMetrics.n),
 
+		StackSecondsAvg:     auraMetrics.stackSecondsSum / float64(auraMetrics.n),
+		MaxStacksSecondsAvg: auraMetrics.maxStacksUptimeSum / float64(auraMetrics.n),
+		StacksConsumedAvg:   float64(auraMetrics.stacksConsumedSum) / float64(auraMetrics.n),
+
 		AggregatorData: &proto.AggregatorData{
 			N:     int32(auraMetrics.n),
 			SumSq: auraMetrics.sumSq,
@@ -0,0 +1,276 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+const (
+	PhasePreExecute = "Pre-Execute"
+	PhaseExecute35  = "Execute 35%"
+	PhaseExecute25  = "Execute 25%"
+	PhaseExecute20  = "Execute 20%"
+)
+
+func executePhaseName(executePhase int32) string {
+	switch executePhase {
+	case 35:
+		return PhaseExecute35
+	case 25:
+		return PhaseExecute25
+	case 20:
+		return PhaseExecute20
+	default:
+		return PhasePreExecute
+	}
+}
+
+// Sets the encounter phase declared by the AI, e.g. at a boss transition, or "" if there is none.
+// When phase metrics are enabled, results are also reported for each encounter phase.
+func (sim *Simulation) SetEncounterPhase(name string) {
+	if name == sim.encounterPhase {
+		return
+	}
+	if sim.phaseTracker != nil {
+		sim.phaseTracker.endPhase(sim, sim.encounterPhase)
+		sim.phaseTracker.startPhase(sim, name)
+	}
+	sim.encounterPhase = name
+}
+
+func (sim *Simulation) EncounterPhase() string {
+	return sim.encounterPhase
+}
+
+type phaseActionKey struct {
+	phase    string
+	actionID ActionID
+}
+
+type phaseActionMetrics struct {
+	casts  int32
+	damage float64
+}
+
+// Sums of metrics of a unit within each phase, over all iterations.
+type phaseMetrics struct {
+	names      []string // In order of first appearance.
+	iterations map[string]int32
+	seconds    map[string]float64
+	damage     map[string]float64
+	actions    map[phaseActionKey]*phaseActionMetrics
+}
+
+func newPhaseMetrics() *phaseMetrics {
+	return &phaseMetrics{
+		iterations: make(map[string]int32),
+		seconds:    make(map[string]float64),
+		damage:     make(map[string]float64),
+		actions:    make(map[phaseActionKey]*phaseActionMetrics),
+	}
+}
+
+func (pm *phaseMetrics) action(phase string, actionID ActionID) *phaseActionMetrics {
+	key := phaseActionKey{phase: phase, actionID: actionID}
+	action, ok := pm.actions[key]
+	if !ok {
+		action = &phaseActionMetrics{}
+		pm.actions[key] = action
+	}
+	return action
+}
+
+func (pm *phaseMetrics) addIteration(phase string, seconds float64) {
+	if _, ok := pm.iterations[phase]; !ok {
+		pm.names = append(pm.names, phase)
+	}
+	pm.iterations[phase]++
+	pm.seconds[phase] += seconds
+}
+
+func (pm *phaseMetrics) ToProto() []*proto.PhaseMetrics {
+	phases := make([]*proto.PhaseMetrics, 0, len(pm.names))
+	for _, name := range pm.names {
+		iterations := float64(pm.iterations[name])
+		phase := &proto.PhaseMetrics{
+			Name:       name,
+			Iterations: pm.iterations[name],
+			SecondsAvg: pm.seconds[name] / iterations,
+			DamageAvg:  pm.damage[name] / iterations,
+		}
+		if pm.seconds[name] > 0 {
+			phase.Dps = pm.damage[name] / pm.seconds[name]
+		}
+		phases = append(phases, phase)
+	}
+	return phases
+}
+
+// Returns the metrics of the action within each phase, in the order of the unit's phases.
+func (pm *phaseMetrics) actionToProto(actionID ActionID) []*proto.ActionPhaseMetrics {
+	var phases []*proto.ActionPhaseMetrics
+	for _, name := range pm.names {
+		if action, ok := pm.actions[phaseActionKey{phase: name, actionID: actionID}]; ok {
+			phases = append(phases, &proto.ActionPhaseMetrics{
+				Name:   name,
+				Casts:  action.casts,
+				Damage: action.damage,
+			})
+		}
+	}
+	return phases
+}
+
+// Tracks the active phases of the current iteration, and the time spent in each.
+type phaseTracker struct {
+	units []*Unit
+
+	executePhase string
+	starts       map[string]time.Duration
+	seconds      map[string]time.Duration
+	order        []string // Phases reached in this iteration.
+}
+
+func (sim *Simulation) enablePhaseMetrics() {
+	for _, unit := range sim.Environment.AllUnits {
+		unit.Metrics.phases = newPhaseMetrics()
+	}
+	sim.phaseTracker = &phaseTracker{
+		units:   sim.Environment.AllUnits,
+		starts:  make(map[string]time.Duration),
+		seconds: make(map[string]time.Duration),
+	}
+}
+
+func (pt *phaseTracker) reset(sim *Simulation) {
+	clear(pt.starts)
+	clear(pt.seconds)
+	pt.order = pt.order[:0]
+	pt.executePhase = executePhaseName(sim.executePhase)
+	pt.startPhase(sim, pt.executePhase)
+}
+
+func (pt *phaseTracker) startPhase(sim *Simulation, name string) {
+	if name == "" {
+		return
+	}
+	if _, ok := pt.seconds[name]; !ok {
+		pt.order = append(pt.order, name)
+		pt.seconds[name] = 0
+	}
+	pt.starts[name] = max(sim.CurrentTime, 0)
+}
+
+func (pt *phaseTracker) endPhase(sim *Simulation, name string) {
+	if start, ok := pt.starts[name]; ok {
+		pt.seconds[name] += max(sim.CurrentTime, 0) - start
+		delete(pt.starts, name)
+	}
+}
+
+func (pt *phaseTracker) setExecutePhase(sim *Simulation) {
+	name := executePhaseName(sim.executePhase)
+	if name == pt.executePhase {
+		return
+	}
+	pt.endPhase(sim, pt.executePhase)
+	pt.startPhase(sim, name)
+	pt.executePhase = name
+}
+
+func (pt *phaseTracker) addDamage(sim *Simulation, unit *Unit, actionID ActionID, damage float64) {
+	pm := unit.Metrics.phases
+	for _, phase := range [2]string{pt.executePhase, sim.encounterPhase} {
+		if phase != "" {
+			pm.damage[phase] += damage
+			pm.action(phase, actionID).damage += damage
+		}
+	}
+}
+
+func (pt *phaseTracker) addCast(sim *Simulation, unit *Unit, actionID ActionID) {
+	pm := unit.Metrics.phases
+	for _, phase := range [2]string{pt.executePhase, sim.encounterPhase} {
+		if phase != "" {
+			pm.action(phase, actionID).casts++
+		}
+	}
+}
+
+// Ends the active phases and adds the time in each phase to the metrics of all units.
+// Phases which were skipped over, e.g. execute ranges of the same proportion, are not counted.
+func (pt *phaseTracker) doneIteration(sim *Simulation) {
+	pt.endPhase(sim, pt.executePhase)
+	pt.endPhase(sim, sim.encounterPhase)
+	for _, name := range pt.order {
+		if pt.seconds[name] <= 0 {
+			continue
+		}
+		for _, unit := range pt.units {
+			unit.Metrics.phases.addIteration(name, pt.seconds[name].Seconds())
+		}
+	}
+}
+
+// Adds the damage of a pet to the phases of its owner, like the owner's DPS.
+func addPetPhases(owner []*proto.PhaseMetrics, pet []*proto.PhaseMetrics) {
+	for _, petPhase := range pet {
+		for _, phase := range owner {
+			if phase.Name == petPhase.Name {
+				phase.DamageAvg += petPhase.DamageAvg
+				phase.Dps += petPhase.Dps
+			}
+		}
+	}
+}
+
+// Combines the phases of two results, weighting each phase by its iterations.
+func combinePhaseMetrics(base []*proto.PhaseMetrics, add []*proto.PhaseMetrics) []*proto.PhaseMetrics {
+	for _, addPhase := range add {
+		var phase *proto.PhaseMetrics
+		for _, basePhase := range base {
+			if basePhase.Name == addPhase.Name {
+				phase = basePhase
+				break
+			}
+		}
+		if phase == nil {
+			phase = &proto.PhaseMetrics{Name: addPhase.Name}
+			base = append(base, phase)
+		}
+
+		baseN, addN := float64(phase.Iterations), float64(addPhase.Iterations)
+		if baseN+addN == 0 {
+			continue
+		}
+		seconds := phase.SecondsAvg*baseN + addPhase.SecondsAvg*addN
+		damage := phase.DamageAvg*baseN + addPhase.DamageAvg*addN
+		phase.Iterations += addPhase.Iterations
+		phase.SecondsAvg = seconds / (baseN + addN)
+		phase.DamageAvg = damage / (baseN + addN)
+		if seconds > 0 {
+			phase.Dps = damage / seconds
+		}
+	}
+	return base
+}
+
+func combineActionPhaseMetrics(base []*proto.ActionPhaseMetrics, add []*proto.ActionPhaseMetrics) []*proto.ActionPhaseMetrics {
+	for _, addPhase := range add {
+		var phase *proto.ActionPhaseMetrics
+		for _, basePhase := range base {
+			if basePhase.Name == addPhase.Name {
+				phase = basePhase
+				break
+			}
+		}
+		if phase == nil {
+			phase = &proto.ActionPhaseMetrics{Name: addPhase.Name}
+			base = append(base, phase)
+		}
+		phase.Casts += addPhase.Casts
+		phase.Damage += addPhase.Damage
+	}
+	return base
+}
@@ -0,0 +1,76 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestPhaseMetrics(t *testing.T) {
+	unit := &Unit{Metrics: UnitMetrics{phases: newPhaseMetrics()}}
+	sim := &Simulation{
+		Duration:     time.Second * 20,
+		executePhase: 100,
+		phaseTracker: &phaseTracker{
+			units:   []*Unit{unit},
+			starts:  make(map[string]time.Duration),
+			seconds: make(map[string]time.Duration),
+		},
+	}
+	spell := ActionID{SpellID: 1}
+
+	sim.phaseTracker.reset(sim)
+	sim.phaseTracker.addDamage(sim, unit, spell, 100)
+
+	sim.CurrentTime = time.Second * 5
+	sim.SetEncounterPhase("Adds")
+	sim.phaseTracker.addCast(sim, unit, spell)
+
+	sim.CurrentTime = time.Second * 15
+	sim.executePhase = 20
+	sim.phaseTracker.setExecutePhase(sim)
+	sim.phaseTracker.addDamage(sim, unit, spell, 50)
+
+	sim.CurrentTime = sim.Duration
+	sim.phaseTracker.doneIteration(sim)
+
+	phases := unit.Metrics.phases.ToProto()
+	expected := []*proto.PhaseMetrics{
+		{Name: PhasePreExecute, Iterations: 1, SecondsAvg: 15, DamageAvg: 100, Dps: 100.0 / 15},
+		{Name: "Adds", Iterations: 1, SecondsAvg: 15, DamageAvg: 50, Dps: 50.0 / 15},
+		{Name: PhaseExecute20, Iterations: 1, SecondsAvg: 5, DamageAvg: 50, Dps: 10},
+	}
+	if len(phases) != len(expected) {
+		t.Fatalf("Expected %d phases, got %v", len(expected), phases)
+	}
+	for i, phase := range phases {
+		if phase.String() != expected[i].String() {
+			t.Fatalf("Expected phase %v, got %v", expected[i], phase)
+		}
+	}
+
+	actionPhases := unit.Metrics.phases.actionToProto(spell)
+	if len(actionPhases) != 3 || actionPhases[0].Casts != 1 || actionPhases[1].Casts != 1 || actionPhases[2].Damage != 50 {
+		t.Fatalf("Unexpected action phases %v", actionPhases)
+	}
+}
+
+func TestCombinePhaseMetrics(t *testing.T) {
+	base := []*proto.PhaseMetrics{{Name: PhasePreExecute, Iterations: 3, SecondsAvg: 10, DamageAvg: 100, Dps: 10}}
+	add := []*proto.PhaseMetrics{
+		{Name: PhasePreExecute, Iterations: 1, SecondsAvg: 20, DamageAvg: 400, Dps: 20},
+		{Name: PhaseExecute20, Iterations: 1, SecondsAvg: 5, DamageAvg: 50, Dps: 10},
+	}
+
+	combined := combinePhaseMetrics(combinePhaseMetrics(nil, base), add)
+	if len(combined) != 2 {
+		t.Fatalf("Expected 2 phases, got %v", combined)
+	}
+	if combined[0].Iterations != 4 || combined[0].SecondsAvg != 12.5 || combined[0].DamageAvg != 175 || combined[0].Dps != 14 {
+		t.Fatalf("Expected phase weighted by iterations, got %v", combined[0])
+	}
+	if combined[1].Iterations != 1 || combined[1].Dps != 10 {
+		t.Fatalf("Unexpected new phase %v", combined[1])
+	}
+}
@@ -127,6 +127,9 @@ type Raid struct {
 	replenishmentUnits         []*Unit   // All units who can receive replenishment.
 	curReplenishmentUnits      [][]*Unit // Units that currently have replenishment active, separated by source.
 	leftoverReplenishmentUnits []*Unit   // Units without replenishment currently active.
+
+	deathModel    *proto.DeathModel
+	resurrections int32 // Resurrections used in the current iteration.
 }
 
 func (raid *Raid) GetActiveUnits() []*Unit {
@@ -291,7 +294,7 @@ func (raid *Raid) applyCharacterEffects(raidConfig *proto.Raid) *proto.RaidStats
 
 			char := player.GetCharacter()
 			char.EnableHealthBar()
-			char.trackChanceOfDeath(playerConfig.HealingModel)
+			char.trackChanceOfDeath(playerConfig.HealingModel, raidConfig.DeathModel)
 			partyStats.Players[char.PartyIndex] = char.applyAllEffects(player, raidBuffs, partyBuffs, individualBuffs)
 
 			for _, pet := range char.Pets {
@@ -366,6 +369,7 @@ func (raid *Raid) reset(sim *Simulation) {
 	}
 	raid.dpsMetrics.reset()
 	raid.hpsMetrics.reset()
+	raid.resurrections = 0
 }
 
 func (raid *Raid) doneIteration(sim *Simulation) {
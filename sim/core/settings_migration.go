@@ -0,0 +1,310 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protojson"
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Version of the settings format of RaidSimRequest and IndividualSimSettings. Whenever a setting is removed or
+// changes shape, increase it and add a migration which carries the old setting over.
+const CurrentAPIVersion = 1
+
+// Migrations work on the JSON form of the settings, so they can still read fields which were removed from the protos.
+var settingsMigrations = []struct {
+	version int32 // Version the migration upgrades to.
+	migrate func(m *settingsMigrator)
+}{
+	{version: 1, migrate: migrateToVersion1},
+}
+
+type jsonObject = map[string]any
+
+// A JSON object within the settings, with its path for warnings.
+type jsonNode struct {
+	path string
+	obj  jsonObject
+}
+
+type settingsMigrator struct {
+	players  []jsonNode
+	debuffs  []jsonNode
+	warnings []string
+}
+
+func (m *settingsMigrator) warnf(path string, format string, args ...any) {
+	m.warnings = append(m.warnings, path+": "+fmt.Sprintf(format, args...))
+}
+
+// Upgrades a RaidSimRequest in protojson format to the current version. Returns warnings for settings
+// which could not be carried over.
+func MigrateRaidSimRequest(data []byte) (*proto.RaidSimRequest, []string, error) {
+	rsr := &proto.RaidSimRequest{}
+	warnings, err := migrateSettings(data, rsr, func(root jsonObject) *settingsMigrator {
+		m := &settingsMigrator{}
+		raid := jsonChild(root, "raid")
+		for i, party := range jsonList(raid, "parties") {
+			for j, player := range jsonList(party, "players") {
+				m.players = append(m.players, jsonNode{path: fmt.Sprintf("raid.parties[%d].players[%d]", i, j), obj: player})
+			}
+		}
+		if debuffs := jsonChild(raid, "debuffs"); debuffs != nil {
+			m.debuffs = append(m.debuffs, jsonNode{path: "raid.debuffs", obj: debuffs})
+		}
+		return m
+	})
+	return rsr, warnings, err
+}
+
+// Upgrades IndividualSimSettings in protojson format to the current version. Returns warnings for settings
+// which could not be carried over.
+func MigrateIndividualSimSettings(data []byte) (*proto.IndividualSimSettings, []string, error) {
+	settings := &proto.IndividualSimSettings{}
+	warnings, err := migrateSettings(data, settings, func(root jsonObject) *settingsMigrator {
+		m := &settingsMigrator{}
+		if player := jsonChild(root, "player"); player != nil {
+			m.players = append(m.players, jsonNode{path: "player", obj: player})
+		}
+		if debuffs := jsonChild(root, "debuffs"); debuffs != nil {
+			m.debuffs = append(m.debuffs, jsonNode{path: "debuffs", obj: debuffs})
+		}
+		return m
+	})
+	return settings, warnings, err
+}
+
+func migrateSettings(data []byte, msg goproto.Message, newMigrator func(root jsonObject) *settingsMigrator) ([]string, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var root jsonObject
+	if err := decoder.Decode(&root); err != nil {
+		return nil, err
+	}
+
+	var version int32
+	if value, ok := popJSONField(root, "api_version"); ok {
+		if v, ok := jsonNumber(value); ok {
+			version = int32(v)
+		}
+	}
+	if version > CurrentAPIVersion {
+		return nil, fmt.Errorf("settings are from a newer version of the sim (%d, current is %d)", version, CurrentAPIVersion)
+	}
+
+	m := newMigrator(root)
+	for _, migration := range settingsMigrations {
+		if migration.version > version {
+			migration.migrate(m)
+		}
+	}
+	dropRemovedFields(m, root, msg.ProtoReflect().Descriptor(), "")
+	root["apiVersion"] = CurrentAPIVersion
+
+	migrated, err := json.Marshal(root)
+	if err != nil {
+		return nil, err
+	}
+	// Unknown enum values are still discarded, like before.
+	if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(migrated, msg); err != nil {
+		return nil, err
+	}
+	return m.warnings, nil
+}
+
+// Removes the fields which no longer exist in the message and were not migrated, with a warning for each.
+func dropRemovedFields(m *settingsMigrator, obj jsonObject, md protoreflect.MessageDescriptor, path string) {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		fd := md.Fields().ByJSONName(key)
+		if fd == nil {
+			fd = md.Fields().ByTextName(key)
+		}
+		if fd == nil {
+			m.warnf(joinJSONPath(path, key), "Setting no longer exists and was dropped")
+			delete(obj, key)
+			continue
+		}
+		if fd.Message() == nil || (fd.IsMap() && fd.MapValue().Message() == nil) {
+			continue
+		}
+
+		fieldPath := joinJSONPath(path, fd.JSONName())
+		switch value := obj[key].(type) {
+		case jsonObject:
+			if fd.IsMap() {
+				for mapKey, mapValue := range value {
+					if child, ok := mapValue.(jsonObject); ok {
+						dropRemovedFields(m, child, fd.MapValue().Message(), fmt.Sprintf("%s[%s]", fieldPath, mapKey))
+					}
+				}
+			} else {
+				dropRemovedFields(m, value, fd.Message(), fieldPath)
+			}
+		case []any:
+			for i, element := range value {
+				if child, ok := element.(jsonObject); ok {
+					dropRemovedFields(m, child, fd.Message(), fmt.Sprintf("%s[%d]", fieldPath, i))
+				}
+			}
+		}
+	}
+}
+
+func joinJSONPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+// Returns the JSON name of a proto field, e.g. 'atalaiMojo' for 'atalai_mojo'.
+func jsonFieldName(protoName string) string {
+	parts := strings.Split(protoName, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// Returns the value of a field given by its proto name, which protojson accepts by either its JSON or proto name.
+func jsonField(obj jsonObject, protoName string) (any, bool) {
+	if value, ok := obj[jsonFieldName(protoName)]; ok {
+		return value, true
+	}
+	value, ok := obj[protoName]
+	return value, ok
+}
+
+func popJSONField(obj jsonObject, protoName string) (any, bool) {
+	value, ok := jsonField(obj, protoName)
+	delete(obj, jsonFieldName(protoName))
+	delete(obj, protoName)
+	return value, ok
+}
+
+func setJSONField(obj jsonObject, protoName string, value any) {
+	delete(obj, protoName)
+	obj[jsonFieldName(protoName)] = value
+}
+
+func jsonChild(obj jsonObject, protoName string) jsonObject {
+	value, _ := jsonField(obj, protoName)
+	child, _ := value.(jsonObject)
+	return child
+}
+
+func jsonList(obj jsonObject, protoName string) []jsonObject {
+	value, _ := jsonField(obj, protoName)
+	list, _ := value.([]any)
+	var objects []jsonObject
+	for _, element := range list {
+		if child, ok := element.(jsonObject); ok {
+			objects = append(objects, child)
+		}
+	}
+	return objects
+}
+
+func jsonNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+func jsonBool(value any) bool {
+	b, _ := value.(bool)
+	return b
+}
+
+// Returns the name of an enum value, which protojson accepts by either its name or number.
+func jsonEnumName(value any, names map[int32]string) string {
+	if name, ok := value.(string); ok {
+		return name
+	}
+	number, _ := jsonNumber(value)
+	return names[int32(number)]
+}
+
+// Version 1 introduced the settings version, and carries over the settings deprecated or removed before it.
+func migrateToVersion1(m *settingsMigrator) {
+	for _, player := range m.players {
+		if consumes := jsonChild(player.obj, "consumes"); consumes != nil {
+			path := player.path + ".consumes"
+			if value, ok := popJSONField(consumes, "atalai_mojo"); ok {
+				mojo := jsonEnumName(value, proto.AtalaiMojo_name)
+				zanzaValue, _ := jsonField(consumes, "zanza_buff")
+				zanza := jsonEnumName(zanzaValue, proto.ZanzaBuff_name)
+				switch {
+				case mojo == "" || mojo == proto.AtalaiMojo_AtalaiMojoUnknown.String():
+				case zanza != "" && zanza != proto.ZanzaBuff_ZanzaBuffUnknown.String():
+					m.warnf(path+".atalaiMojo", "%s was dropped, as it doesn't stack with %s", mojo, zanza)
+				default:
+					setJSONField(consumes, "zanza_buff", "AtalaiMojoOf"+strings.TrimPrefix(mojo, "MojoOf"))
+				}
+			}
+			if value, ok := popJSONField(consumes, "bogling_root"); ok && jsonBool(value) {
+				misc := jsonChild(consumes, "misc_consumes")
+				if misc == nil {
+					misc = jsonObject{}
+					setJSONField(consumes, "misc_consumes", misc)
+				}
+				setJSONField(misc, "bogling_root", true)
+			}
+		}
+
+		if buffs := jsonChild(player.obj, "buffs"); buffs != nil {
+			if value, ok := popJSONField(buffs, "dragonslayer_buff"); ok && jsonBool(value) {
+				setJSONField(buffs, "rallying_cry_of_the_dragonslayer", true)
+			}
+		}
+
+		if options := jsonChild(jsonChild(player.obj, "shadow_priest"), "options"); options != nil {
+			value, _ := popJSONField(options, "latency")
+			clipDelay, _ := jsonField(player.obj, "channel_clip_delay_ms")
+			if latency, ok := jsonNumber(value); ok && latency > 0 {
+				if delay, _ := jsonNumber(clipDelay); delay == 0 {
+					setJSONField(player.obj, "channel_clip_delay_ms", int32(latency))
+				}
+			}
+		}
+		if options := jsonChild(jsonChild(player.obj, "restoration_shaman"), "options"); options != nil {
+			if _, ok := popJSONField(options, "totems"); ok {
+				m.warnf(player.path+".restorationShaman.options.totems", "Totems are no longer an option and were dropped, cast them in the rotation instead")
+			}
+		}
+		if options := jsonChild(jsonChild(player.obj, "warrior"), "options"); options != nil {
+			if value, ok := popJSONField(options, "use_recklessness"); ok && jsonBool(value) {
+				m.warnf(player.path+".warrior.options.useRecklessness", "Recklessness is no longer an option and was dropped, cast it in the rotation instead")
+			}
+		}
+	}
+
+	for _, debuffs := range m.debuffs {
+		for _, curse := range []string{"curse_of_elements", "curse_of_shadow"} {
+			value, _ := popJSONField(debuffs.obj, curse+"_new")
+			if effect := jsonEnumName(value, proto.TristateEffect_name); effect != "" && effect != proto.TristateEffect_TristateEffectMissing.String() {
+				setJSONField(debuffs.obj, curse, true)
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestMigrateRaidSimRequest(t *testing.T) {
+	rsr, warnings, err := MigrateRaidSimRequest([]byte(`{
+		"raid": {
+			"parties": [{"players": [
+				{
+					"consumes": {"atalaiMojo": "MojoOfWar", "bogling_root": true},
+					"buffs": {"dragonslayerBuff": true},
+					"warrior": {"options": {"startingRage": 10, "useRecklessness": true}}
+				},
+				{
+					"consumes": {"atalai_mojo": 2, "zanzaBuff": "SpiritOfZanza"},
+					"shadowPriest": {"options": {"latency": 150}}
+				}
+			]}],
+			"debuffs": {"curseOfElementsNew": "TristateEffectImproved", "curseOfShadowNew": "TristateEffectMissing"}
+		},
+		"encounter": {"critBlockDamage": 1}
+	}`))
+	if err != nil {
+		t.Fatalf("Failed to migrate: %v", err)
+	}
+
+	if rsr.ApiVersion != CurrentAPIVersion {
+		t.Fatalf("Expected version %d, got %d", CurrentAPIVersion, rsr.ApiVersion)
+	}
+	warrior := rsr.Raid.Parties[0].Players[0]
+	if warrior.Consumes.ZanzaBuff != proto.ZanzaBuff_AtalaiMojoOfWar || !warrior.Consumes.MiscConsumes.GetBoglingRoot() {
+		t.Fatalf("Expected consumes to be carried over, got %v", warrior.Consumes)
+	}
+	if !warrior.Buffs.RallyingCryOfTheDragonslayer || warrior.GetWarrior().Options.StartingRage != 10 {
+		t.Fatalf("Expected buffs and options to be carried over, got %v", warrior)
+	}
+	priest := rsr.Raid.Parties[0].Players[1]
+	if priest.Consumes.ZanzaBuff != proto.ZanzaBuff_SpiritOfZanza || priest.ChannelClipDelayMs != 150 {
+		t.Fatalf("Expected priest settings to be carried over, got %v", priest)
+	}
+	if !rsr.Raid.Debuffs.CurseOfElements || rsr.Raid.Debuffs.CurseOfShadow || rsr.Raid.Debuffs.CurseOfElementsNew != proto.TristateEffect_TristateEffectMissing {
+		t.Fatalf("Expected curses to be carried over, got %v", rsr.Raid.Debuffs)
+	}
+
+	expectedWarnings := []string{
+		"raid.parties[0].players[0].warrior.options.useRecklessness: Recklessness is no longer an option and was dropped, cast it in the rotation instead",
+		"raid.parties[0].players[1].consumes.atalaiMojo: MojoOfForbiddenMagic was dropped, as it doesn't stack with SpiritOfZanza",
+		"encounter.critBlockDamage: Setting no longer exists and was dropped",
+	}
+	if !slices.Equal(warnings, expectedWarnings) {
+		t.Fatalf("Expected warnings %q, got %q", expectedWarnings, warnings)
+	}
+
+	if _, _, err := MigrateRaidSimRequest([]byte(`{"apiVersion": 1000}`)); err == nil {
+		t.Fatalf("Expected settings of a newer version to fail")
+	}
+}
@@ -47,6 +47,8 @@ type Simulation struct {
 
 	executePhaseCallbacks []func(*Simulation, int32) // 2nd parameter is 35 for 35%, 25 for 25% and 20 for 20%
 
+	encounterPhase string // Declared by the encounter AI, see SetEncounterPhase.
+
 	nextExecuteDuration time.Duration
 	nextExecuteDamage   float64
 
@@ -62,6 +64,19 @@ type Simulation struct {
 
 	minTaskTime time.Duration
 	tasks       []Task
+
+	// Only set if SimOptions.time_series_bin_seconds is set.
+	timeSeriesSampler *timeSeriesSampler
+
+	// Only set if SimOptions.phase_metrics is set.
+	phaseTracker *phaseTracker
+
+	// Used for snapshots, see Snapshot(). Only set for interactive sims.
+	request        *proto.RaidSimRequest
+	iterationStart iterationStart
+	steps          int
+	inStep         bool
+	actions        []recordedAction
 }
 
 func (sim *Simulation) rescheduleTracker(trackerTime time.Duration) {
@@ -139,6 +154,22 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 		}()
 	}
 
+	if rsr.SimOptions.GetRejectInvalid() {
+		if errorOutcome := validationErrorOutcome(ValidateRaidSimRequest(rsr)); errorOutcome != nil {
+			result = &proto.RaidSimResult{Error: errorOutcome}
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{FinalRaidResult: result}
+			}
+			return result
+		}
+	}
+
+	if rsr.SimOptions.TargetPrecision > 0 {
+		return runSimToPrecision(rsr, progress, func(batch *proto.RaidSimRequest, batchProgress chan *proto.ProgressMetrics) *proto.RaidSimResult {
+			return runSim(batch, batchProgress, skipPresim, signals)
+		})
+	}
+
 	sim := NewSim(rsr, signals)
 
 	if !skipPresim {
@@ -185,7 +216,12 @@ func runSim(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, ski
 
 func NewSim(rsr *proto.RaidSimRequest, signals simsignals.Signals) *Simulation {
 	env, _, _ := NewEnvironment(rsr.Raid, rsr.Encounter, false)
-	return newSimWithEnv(env, rsr.SimOptions, signals)
+	sim := newSimWithEnv(env, rsr.SimOptions, signals)
+	// Only interactive sims take outside actions, so only they need to be snapshotted.
+	if rsr.SimOptions.GetInteractive() {
+		sim.request = cloneRaidSimRequest(rsr)
+	}
+	return sim
 }
 
 func newSimWithEnv(env *Environment, simOptions *proto.SimOptions, signals simsignals.Signals) *Simulation {
@@ -194,7 +230,7 @@ func newSimWithEnv(env *Environment, simOptions *proto.SimOptions, signals simsi
 		rseed = time.Now().UnixNano()
 	}
 
-	return &Simulation{
+	sim := &Simulation{
 		Environment: env,
 		Options:     simOptions,
 
@@ -206,6 +242,15 @@ func newSimWithEnv(env *Environment, simOptions *proto.SimOptions, signals simsi
 
 		Signals: signals,
 	}
+
+	if simOptions.TimeSeriesBinSeconds > 0 {
+		sim.enableTimeSeries(simOptions.TimeSeriesBinSeconds)
+	}
+	if simOptions.PhaseMetrics {
+		sim.enablePhaseMetrics()
+	}
+
+	return sim
 }
 
 // Returns a random float64 between 0.0 (inclusive) and 1.0 (exclusive).
@@ -251,6 +296,11 @@ func (sim *Simulation) RandomExpFloat(label string) float64 {
 	return rand.New(sim.labelRand(label)).ExpFloat64()
 }
 
+// Returns a normally distributed float64 with mean 0 and standard deviation 1.
+func (sim *Simulation) RandomNormFloat(label string) float64 {
+	return rand.New(sim.labelRand(label)).NormFloat64()
+}
+
 // Shorthand for commonly-used RNG behavior.
 // Returns a random number between min and max.
 func (sim *Simulation) Roll(min float64, max float64) float64 {
@@ -386,6 +436,8 @@ func (sim *Simulation) reset() {
 		sim.BaseDuration = sim.CurrentTime
 		sim.Encounter.DurationIsEstimate = false
 	}
+	sim.recordIterationStart()
+
 	sim.Duration = sim.BaseDuration
 	if sim.DurationVariation != 0 {
 		variation := sim.DurationVariation * 2
@@ -409,6 +461,11 @@ func (sim *Simulation) reset() {
 
 	sim.CurrentTime = 0
 
+	sim.encounterPhase = ""
+	if sim.phaseTracker != nil {
+		sim.phaseTracker.reset(sim)
+	}
+
 	sim.trackers = sim.trackers[:0]
 	sim.minTrackerTime = NeverExpires
 
@@ -421,6 +478,7 @@ func (sim *Simulation) reset() {
 	sim.Environment.reset(sim)
 
 	sim.initManaTickAction()
+	sim.initTimeSeriesSampler()
 }
 
 func (sim *Simulation) PrePull() {
@@ -462,6 +520,10 @@ func (sim *Simulation) Cleanup() {
 		}
 	}
 
+	if sim.phaseTracker != nil {
+		sim.phaseTracker.doneIteration(sim)
+	}
+
 	sim.Raid.doneIteration(sim)
 	sim.Encounter.doneIteration(sim)
 
@@ -482,6 +544,17 @@ func (sim *Simulation) runPendingActions() {
 }
 
 func (sim *Simulation) Step() bool {
+	if sim.request == nil {
+		return sim.step()
+	}
+	sim.inStep = true
+	finished := sim.step()
+	sim.inStep = false
+	sim.steps++
+	return finished
+}
+
+func (sim *Simulation) step() bool {
 	last := len(sim.pendingActions) - 1
 	pa := sim.pendingActions[last]
 
@@ -552,6 +625,9 @@ func (sim *Simulation) advance(nextTime time.Duration) {
 	// execute phases 35%, 25%, and 20% in the first advance() call.
 	for sim.CurrentTime >= sim.nextExecuteDuration || sim.Encounter.DamageTaken >= sim.nextExecuteDamage {
 		sim.nextExecutePhase()
+		if sim.phaseTracker != nil {
+			sim.phaseTracker.setExecutePhase(sim)
+		}
 		for _, callback := range sim.executePhaseCallbacks {
 			callback(sim, sim.executePhase)
 		}
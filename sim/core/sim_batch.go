@@ -0,0 +1,79 @@
+package core
+
+import (
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+// Runs the given raid sims one after another, reporting combined progress. Each sim
+// uses all available threads unless running in wasm or tests.
+// Returns the error of the first failing sim, if any.
+func runRaidSimBatch(requests []*proto.RaidSimRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) ([]*proto.RaidSimResult, *proto.ErrorOutcome) {
+	var iterationsTotal int32 = 0
+	var iterationsDone int32 = 0
+	var simsTotal = int32(len(requests))
+	var simsCompleted int32 = 0
+
+	isTest := false
+	for _, req := range requests {
+		iterationsTotal += req.SimOptions.Iterations
+		isTest = isTest || req.SimOptions.IsTest
+	}
+
+	waitForResult := func(srcProgressChannel chan *proto.ProgressMetrics) *proto.RaidSimResult {
+		var lastCompleted int32 = 0
+		for metrics := range srcProgressChannel {
+			iterationsDone += metrics.CompletedIterations - lastCompleted
+			lastCompleted = metrics.CompletedIterations
+
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{
+					TotalIterations:     iterationsTotal,
+					CompletedIterations: iterationsDone,
+					CompletedSims:       simsCompleted,
+					TotalSims:           simsTotal,
+				}
+			}
+
+			if metrics.FinalRaidResult != nil {
+				simsCompleted++
+				return metrics.FinalRaidResult
+			}
+		}
+		return nil
+	}
+
+	simFunc := runSimConcurrent
+	// Don't use go threads in wasm, it just adds more overhead and makes the worker more unresponsive.
+	if IsRunningInWasm() || isTest {
+		simFunc = RunSim
+	}
+
+	results := make([]*proto.RaidSimResult, len(requests))
+	for i, req := range requests {
+		simProgress := make(chan *proto.ProgressMetrics, 100)
+		go simFunc(req, simProgress, signals)
+		result := waitForResult(simProgress)
+		if result == nil {
+			return nil, &proto.ErrorOutcome{Message: "missing sim result"}
+		}
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		results[i] = result
+	}
+
+	return results, nil
+}
+
+// Returns the metrics of the first player in the result, which is the player for single player requests.
+func FirstPlayerMetrics(result *proto.RaidSimResult) *proto.UnitMetrics {
+	for _, party := range result.RaidMetrics.Parties {
+		for _, player := range party.Players {
+			if player.Name != "" {
+				return player
+			}
+		}
+	}
+	return nil
+}
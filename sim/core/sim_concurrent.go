@@ -7,6 +7,7 @@ import (
 	"reflect"
 	"runtime"
 	"runtime/debug"
+	"slices"
 
 	"github.com/wowsims/classic/sim/core/proto"
 	"github.com/wowsims/classic/sim/core/simsignals"
@@ -83,6 +84,10 @@ func (rsrc *raidSimResultCombiner) newUnitMetrics(baseUnit *proto.UnitMetrics) *
 		Pets:      make([]*proto.UnitMetrics, len(baseUnit.Pets)),
 	}
 
+	if baseUnit.TimeAlive != nil {
+		newUm.TimeAlive = rsrc.newDistMetrics()
+	}
+
 	for i, aura := range baseUnit.Auras {
 		newUm.Auras[i] = &proto.AuraMetrics{
 			Id:             aura.Id,
@@ -202,12 +207,19 @@ func (rsrc *raidSimResultCombiner) addActionMetrics(unit *proto.UnitMetrics, add
 		baseTgt.CritHealing += addTgt.CritHealing
 		baseTgt.Shielding += addTgt.Shielding
 		baseTgt.CastTimeMs += addTgt.CastTimeMs
+		baseTgt.Interrupts += addTgt.Interrupts
+		baseTgt.DamageAvoided += addTgt.DamageAvoided
 	}
+
+	am.Phases = combineActionPhaseMetrics(am.Phases, add.Phases)
 }
 
 func (rsrc *raidSimResultCombiner) combineAuraMetrics(base *proto.AuraMetrics, add *proto.AuraMetrics, weight float64, isLast bool) {
 	base.UptimeSecondsAvg += add.UptimeSecondsAvg * weight
 	base.ProcsAvg += add.ProcsAvg * weight
+	base.StackSecondsAvg += add.StackSecondsAvg * weight
+	base.MaxStacksSecondsAvg += add.MaxStacksSecondsAvg * weight
+	base.StacksConsumedAvg += add.StacksConsumedAvg * weight
 
 	base.AggregatorData.N += add.AggregatorData.N
 	base.AggregatorData.SumSq += add.AggregatorData.SumSq
@@ -243,6 +255,120 @@ func (rsrc *raidSimResultCombiner) addResourceMetrics(unit *proto.UnitMetrics, a
 	rm.ActualGain += add.ActualGain
 }
 
+func (rsrc *raidSimResultCombiner) addDamageTakenMetrics(unit *proto.UnitMetrics, add *proto.DamageTakenMetrics, weight float64) {
+	var dtm *proto.DamageTakenMetrics
+	for _, baseDamageTaken := range unit.DamageTaken {
+		if baseDamageTaken.Class == add.Class && baseDamageTaken.SpellSchool == add.SpellSchool {
+			dtm = baseDamageTaken
+			break
+		}
+	}
+
+	if dtm == nil {
+		dtm = &proto.DamageTakenMetrics{
+			SpellSchool: add.SpellSchool,
+			Class:       add.Class,
+		}
+		unit.DamageTaken = append(unit.DamageTaken, dtm)
+	}
+
+	dtm.DamageAvg += add.DamageAvg * weight
+}
+
+// Families are the same if they share a rank, as their id is the highest rank cast, which can differ between results.
+func (rsrc *raidSimResultCombiner) addSpellFamilyMetrics(unit *proto.UnitMetrics, add *proto.SpellFamilyMetrics) {
+	var sfm *proto.SpellFamilyMetrics
+	for _, baseFamily := range unit.SpellFamilies {
+		if slices.ContainsFunc(baseFamily.Ranks, func(baseRank *proto.SpellRankMetrics) bool {
+			return slices.ContainsFunc(add.Ranks, func(addRank *proto.SpellRankMetrics) bool {
+				return baseRank.Id.String() == addRank.Id.String()
+			})
+		}) {
+			sfm = baseFamily
+			break
+		}
+	}
+
+	if sfm == nil {
+		sfm = &proto.SpellFamilyMetrics{}
+		unit.SpellFamilies = append(unit.SpellFamilies, sfm)
+	}
+
+	sfm.Casts += add.Casts
+	sfm.Damage += add.Damage
+	sfm.Healing += add.Healing
+	sfm.Threat += add.Threat
+
+	for _, addRank := range add.Ranks {
+		idx := slices.IndexFunc(sfm.Ranks, func(baseRank *proto.SpellRankMetrics) bool {
+			return baseRank.Id.String() == addRank.Id.String()
+		})
+		if idx == -1 {
+			sfm.Ranks = append(sfm.Ranks, &proto.SpellRankMetrics{
+				Id:   addRank.Id,
+				Rank: addRank.Rank,
+			})
+			idx = len(sfm.Ranks) - 1
+		}
+		rm := sfm.Ranks[idx]
+		rm.Casts += addRank.Casts
+		rm.Damage += addRank.Damage
+		rm.Healing += addRank.Healing
+		rm.Threat += addRank.Threat
+	}
+	slices.SortStableFunc(sfm.Ranks, func(a, b *proto.SpellRankMetrics) int {
+		return int(b.Rank - a.Rank)
+	})
+	sfm.Id = sfm.Ranks[0].Id
+}
+
+func (rsrc *raidSimResultCombiner) addManaMetrics(unit *proto.UnitMetrics, add *proto.ManaMetrics, weight float64) {
+	if unit.Mana == nil {
+		unit.Mana = &proto.ManaMetrics{}
+	}
+	mm := unit.Mana
+	mm.SpentAvg += add.SpentAvg * weight
+	mm.GainedAvg += add.GainedAvg * weight
+
+	for _, addAction := range add.Actions {
+		idx := slices.IndexFunc(mm.Actions, func(baseAction *proto.ManaActionMetrics) bool {
+			return baseAction.Id.String() == addAction.Id.String()
+		})
+		if idx == -1 {
+			mm.Actions = append(mm.Actions, &proto.ManaActionMetrics{Id: addAction.Id})
+			idx = len(mm.Actions) - 1
+		}
+		mam := mm.Actions[idx]
+		mam.CastsAvg += addAction.CastsAvg * weight
+		mam.ManaSpentAvg += addAction.ManaSpentAvg * weight
+		mam.DamageAvg += addAction.DamageAvg * weight
+	}
+
+	for _, addSource := range add.Sources {
+		idx := slices.IndexFunc(mm.Sources, func(baseSource *proto.ManaSourceMetrics) bool {
+			return baseSource.Id.String() == addSource.Id.String() && baseSource.Source == addSource.Source
+		})
+		if idx == -1 {
+			mm.Sources = append(mm.Sources, &proto.ManaSourceMetrics{Id: addSource.Id, Source: addSource.Source})
+			idx = len(mm.Sources) - 1
+		}
+		msm := mm.Sources[idx]
+		msm.GainAvg += addSource.GainAvg * weight
+		msm.OverflowAvg += addSource.OverflowAvg * weight
+	}
+}
+
+// The filler suggestion is computed from the combined averages, so AvgIterationDuration has to be combined already.
+func (rsrc *raidSimResultCombiner) finalizeManaMetrics(unit *proto.UnitMetrics) {
+	for _, action := range unit.Mana.Actions {
+		action.DamagePerMana = action.DamageAvg / action.ManaSpentAvg
+	}
+	sortManaMetrics(unit.Mana)
+	if unit.SecondsOomAvg > 0 {
+		unit.Mana.FillerSuggestion = manaFillerSuggestion(unit.Mana, unit.SecondsOomAvg, rsrc.Combined.AvgIterationDuration, unit.Tto.Avg)
+	}
+}
+
 func (rsrc *raidSimResultCombiner) combineUnitMetrics(base *proto.UnitMetrics, add *proto.UnitMetrics, isLast bool, weight float64) {
 	rsrc.combineDistMetrics(base.Dps, add.Dps, isLast, weight)
 	rsrc.combineDistMetrics(base.Dpasp, add.Dpasp, isLast, weight)
@@ -254,6 +380,10 @@ func (rsrc *raidSimResultCombiner) combineUnitMetrics(base *proto.UnitMetrics, a
 
 	base.SecondsOomAvg += add.SecondsOomAvg * weight
 	base.ChanceOfDeath += add.ChanceOfDeath * weight
+	base.DeathsAvg += add.DeathsAvg * weight
+	if base.TimeAlive != nil {
+		rsrc.combineDistMetrics(base.TimeAlive, add.TimeAlive, isLast, weight)
+	}
 
 	for _, addAction := range add.Actions {
 		rsrc.addActionMetrics(base, addAction)
@@ -270,9 +400,32 @@ func (rsrc *raidSimResultCombiner) combineUnitMetrics(base *proto.UnitMetrics, a
 	for i, addPet := range add.Pets {
 		rsrc.combineUnitMetrics(base.Pets[i], addPet, isLast, weight)
 	}
+
+	if add.TimeSeries != nil {
+		base.TimeSeries = combineTimeSeries(base.TimeSeries, add.TimeSeries)
+	}
+	base.Phases = combinePhaseMetrics(base.Phases, add.Phases)
+
+	for _, addDamageTaken := range add.DamageTaken {
+		rsrc.addDamageTakenMetrics(base, addDamageTaken, weight)
+	}
+
+	for _, addFamily := range add.SpellFamilies {
+		rsrc.addSpellFamilyMetrics(base, addFamily)
+	}
+
+	if add.Mana != nil {
+		rsrc.addManaMetrics(base, add.Mana, weight)
+	}
+	if isLast && base.Mana != nil {
+		rsrc.finalizeManaMetrics(base)
+	}
 }
 
 func (rsrc *raidSimResultCombiner) AddResult(result *proto.RaidSimResult, isLast bool, weight float64) {
+	// Combined before the units, as the mana metrics of the last result need it.
+	rsrc.Combined.AvgIterationDuration += result.AvgIterationDuration * weight
+
 	rsrc.combineDistMetrics(rsrc.Combined.RaidMetrics.Dps, result.RaidMetrics.Dps, isLast, weight)
 	rsrc.combineDistMetrics(rsrc.Combined.RaidMetrics.Hps, result.RaidMetrics.Hps, isLast, weight)
 
@@ -289,7 +442,6 @@ func (rsrc *raidSimResultCombiner) AddResult(result *proto.RaidSimResult, isLast
 		rsrc.combineUnitMetrics(rsrc.Combined.EncounterMetrics.Targets[i], tar, isLast, weight)
 	}
 
-	rsrc.Combined.AvgIterationDuration += result.AvgIterationDuration * weight
 	rsrc.Combined.IterationsDone += result.IterationsDone
 
 	if rsrc.Debug {
@@ -437,6 +589,22 @@ func runSimConcurrent(request *proto.RaidSimRequest, progress chan *proto.Progre
 		}
 	}()
 
+	if request.SimOptions.GetRejectInvalid() {
+		if errorOutcome := validationErrorOutcome(ValidateRaidSimRequest(request)); errorOutcome != nil {
+			result = &proto.RaidSimResult{Error: errorOutcome}
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{FinalRaidResult: result}
+			}
+			return result
+		}
+	}
+
+	if request.SimOptions.TargetPrecision > 0 {
+		return runSimToPrecision(request, progress, func(batch *proto.RaidSimRequest, batchProgress chan *proto.ProgressMetrics) *proto.RaidSimResult {
+			return runSimConcurrent(batch, batchProgress, signals)
+		})
+	}
+
 	splitRes := SplitSimRequestForConcurrency(request, TernaryInt32(request.SimOptions.IsTest, 3, int32(runtime.NumCPU())))
 
 	if splitRes.ErrorResult != "" {
@@ -0,0 +1,187 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+const (
+	defaultPrecisionMaxIterations = 100000
+	defaultPrecisionFirstBatch    = 1000
+
+	// z-score of a two-sided 95% confidence interval.
+	confidenceZ95 = 1.96
+)
+
+// Runs a single batch of iterations, sending progress and the final result to the channel if it is set.
+type simBatchRunner func(*proto.RaidSimRequest, chan *proto.ProgressMetrics) *proto.RaidSimResult
+
+// Runs the request in batches until the mean DPS of all precision units, or of the raid if there
+// are none, is known within the target precision, or the iteration cap is hit. Each batch is seeded
+// with the seed of the request plus the iterations done so far, so batches don't repeat each other's
+// fights. The combined result is only reproducible for the same seed and batch sizes, not equal to
+// a single sim.
+func runSimToPrecision(request *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, runBatch simBatchRunner) *proto.RaidSimResult {
+	options := request.SimOptions
+	maxIterations := TernaryInt32(options.MaxIterations > 0, options.MaxIterations, defaultPrecisionMaxIterations)
+	batchSize := min(TernaryInt32(options.Iterations > 0, options.Iterations, defaultPrecisionFirstBatch), maxIterations)
+	seed := options.RandomSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+
+	var results []*proto.RaidSimResult
+	var combined *proto.RaidSimResult
+	var precision *proto.SimPrecision
+	iterationsDone := int32(0)
+
+	for {
+		batch := googleProto.Clone(request).(*proto.RaidSimRequest)
+		batch.SimOptions.TargetPrecision = 0
+		batch.SimOptions.Iterations = batchSize
+		batch.SimOptions.RandomSeed = seed + int64(iterationsDone)
+		if iterationsDone > 0 {
+			batch.SimOptions.DebugFirstIteration = false
+		}
+
+		result := runSimBatch(batch, progress, runBatch, iterationsDone, maxIterations)
+		if result.Error != nil {
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{FinalRaidResult: result}
+			}
+			return result
+		}
+		results = append(results, result)
+		combined = CombineConcurrentSimResults(results, options.Debug)
+		iterationsDone += batchSize
+
+		var needed int32
+		var err error
+		precision, needed, err = computeSimPrecision(combined, options)
+		if err != nil {
+			result := &proto.RaidSimResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+			if progress != nil {
+				progress <- &proto.ProgressMetrics{FinalRaidResult: result}
+			}
+			return result
+		}
+		if precision.Reached || iterationsDone >= maxIterations {
+			break
+		}
+		// Overshoot the estimate a little, as the stdev itself is only an estimate.
+		batchSize = min(max(needed+needed/10-iterationsDone, 1), maxIterations-iterationsDone)
+	}
+
+	combined.Precision = precision
+	if progress != nil {
+		progress <- &proto.ProgressMetrics{
+			TotalIterations:     iterationsDone,
+			CompletedIterations: iterationsDone,
+			Dps:                 combined.RaidMetrics.Dps.Avg,
+			FinalRaidResult:     combined,
+		}
+	}
+	return combined
+}
+
+// Runs one batch, forwarding its progress with the iterations of previous batches added.
+func runSimBatch(batch *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, runBatch simBatchRunner, iterationsDone int32, maxIterations int32) *proto.RaidSimResult {
+	if progress == nil {
+		return runBatch(batch, nil)
+	}
+
+	batchProgress := make(chan *proto.ProgressMetrics, 100)
+	go runBatch(batch, batchProgress)
+	for msg := range batchProgress {
+		if msg.FinalRaidResult != nil {
+			return msg.FinalRaidResult
+		}
+		msg.CompletedIterations += iterationsDone
+		msg.TotalIterations = maxIterations
+		progress <- msg
+	}
+	return &proto.RaidSimResult{Error: &proto.ErrorOutcome{Message: "missing sim result"}}
+}
+
+// Returns the achieved precision, and the estimated number of iterations needed to reach the target.
+// Only the precision units have to reach the target if any are given, otherwise the raid DPS has to.
+func computeSimPrecision(result *proto.RaidSimResult, options *proto.SimOptions) (*proto.SimPrecision, int32, error) {
+	target := options.TargetPrecision
+	iterations := float64(result.IterationsDone)
+	ci95 := func(dist *proto.DistributionMetrics) float64 {
+		return confidenceZ95 * dist.Stdev / math.Sqrt(iterations)
+	}
+
+	precision := &proto.SimPrecision{
+		TargetPrecision: target,
+		RaidDpsCi95:     ci95(result.RaidMetrics.Dps),
+	}
+	dists := []*proto.DistributionMetrics{result.RaidMetrics.Dps}
+	if len(options.PrecisionUnits) > 0 {
+		dists = nil
+	}
+	for _, ref := range options.PrecisionUnits {
+		units, err := precisionUnits(result, ref)
+		if err != nil {
+			return nil, 0, err
+		}
+		for _, unit := range units {
+			precision.Units = append(precision.Units, &proto.UnitPrecision{
+				Unit:    unit.ref,
+				Name:    unit.metrics.Name,
+				DpsCi95: ci95(unit.metrics.Dps),
+			})
+			dists = append(dists, unit.metrics.Dps)
+		}
+	}
+
+	precision.Reached = true
+	needed := 0.0
+	for _, dist := range dists {
+		precision.Reached = precision.Reached && ci95(dist) <= target
+		needed = max(needed, math.Pow(confidenceZ95*dist.Stdev/target, 2))
+	}
+	return precision, int32(min(math.Ceil(needed), math.MaxInt32)), nil
+}
+
+type precisionUnit struct {
+	ref     *proto.UnitReference
+	metrics *proto.UnitMetrics
+}
+
+func precisionUnits(result *proto.RaidSimResult, ref *proto.UnitReference) ([]precisionUnit, error) {
+	var players, targets []precisionUnit
+	for partyIdx, party := range result.RaidMetrics.Parties {
+		for playerIdx, player := range party.Players {
+			if player.Name != "" {
+				raidIndex := int32(partyIdx*5 + playerIdx)
+				players = append(players, precisionUnit{&proto.UnitReference{Type: proto.UnitReference_Player, Index: raidIndex}, player})
+			}
+		}
+	}
+	for i, target := range result.EncounterMetrics.Targets {
+		targets = append(targets, precisionUnit{&proto.UnitReference{Type: proto.UnitReference_Target, Index: int32(i)}, target})
+	}
+
+	var units []precisionUnit
+	switch ref.Type {
+	case proto.UnitReference_Player:
+		units = FilterSlice(players, func(unit precisionUnit) bool { return unit.ref.Index == ref.Index })
+	case proto.UnitReference_Target:
+		units = FilterSlice(targets, func(unit precisionUnit) bool { return unit.ref.Index == ref.Index })
+	case proto.UnitReference_AllPlayers:
+		units = players
+	case proto.UnitReference_AllTargets:
+		units = targets
+	default:
+		return nil, fmt.Errorf("unsupported precision unit type %s", ref.Type)
+	}
+	if len(units) == 0 {
+		return nil, fmt.Errorf("no unit found for precision unit %s", ref)
+	}
+	return units, nil
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Returns a runner whose results always have a mean DPS of 100 with a stdev of 10.
+func fakePrecisionRunner(batches *[]*proto.SimOptions) simBatchRunner {
+	return func(request *proto.RaidSimRequest, _ chan *proto.ProgressMetrics) *proto.RaidSimResult {
+		*batches = append(*batches, request.SimOptions)
+		n := request.SimOptions.Iterations
+		return &proto.RaidSimResult{
+			RaidMetrics: &proto.RaidMetrics{
+				Dps: &proto.DistributionMetrics{
+					Avg:            100,
+					Stdev:          10,
+					AggregatorData: &proto.AggregatorData{N: n, SumSq: float64(n) * (10*10 + 100*100)},
+				},
+				Hps: &proto.DistributionMetrics{AggregatorData: &proto.AggregatorData{}},
+			},
+			EncounterMetrics: &proto.EncounterMetrics{},
+			IterationsDone:   n,
+		}
+	}
+}
+
+func TestRunSimToPrecision(t *testing.T) {
+	var batches []*proto.SimOptions
+	request := &proto.RaidSimRequest{SimOptions: &proto.SimOptions{Iterations: 100, RandomSeed: 5, TargetPrecision: 1}}
+
+	result := runSimToPrecision(request, nil, fakePrecisionRunner(&batches))
+
+	// 1.96 * 10 / sqrt(100) is above the target, which needs about 385 iterations.
+	if len(batches) != 2 || batches[1].RandomSeed != 105 || batches[1].TargetPrecision != 0 {
+		t.Fatalf("Expected a second batch continuing the seeds, got %v", batches)
+	}
+	if result.IterationsDone != 423 || !result.Precision.Reached {
+		t.Fatalf("Expected the target to be reached after 423 iterations, got %d: %v", result.IterationsDone, result.Precision)
+	}
+	if ci := 1.96 * 10 / math.Sqrt(423); math.Abs(result.Precision.RaidDpsCi95-ci) > 1e-6 {
+		t.Fatalf("Expected a confidence interval of %f, got %f", ci, result.Precision.RaidDpsCi95)
+	}
+
+	batches = nil
+	request.SimOptions.MaxIterations = 200
+	result = runSimToPrecision(request, nil, fakePrecisionRunner(&batches))
+	if result.IterationsDone != 200 || result.Precision.Reached {
+		t.Fatalf("Expected to stop at the iteration cap, got %d: %v", result.IterationsDone, result.Precision)
+	}
+}
+
+func TestRunSimToPrecisionUnitsOnly(t *testing.T) {
+	var batches []*proto.SimOptions
+	runner := func(request *proto.RaidSimRequest, progress chan *proto.ProgressMetrics) *proto.RaidSimResult {
+		result := fakePrecisionRunner(&batches)(request, progress)
+		result.RaidMetrics.Parties = []*proto.PartyMetrics{{Players: []*proto.UnitMetrics{{
+			Name: "Player",
+			Dps:  &proto.DistributionMetrics{Avg: 100, Stdev: 1},
+		}}}}
+		return result
+	}
+	request := &proto.RaidSimRequest{SimOptions: &proto.SimOptions{
+		Iterations:      100,
+		TargetPrecision: 1,
+		PrecisionUnits:  []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 0}},
+	}}
+
+	// 1.96 * 1 / sqrt(100) is within the target, even though the raid DPS isn't.
+	result := runSimToPrecision(request, nil, runner)
+	if len(batches) != 1 || !result.Precision.Reached || len(result.Precision.Units) != 1 {
+		t.Fatalf("Expected the player to reach the target after the first batch, got %v", result.Precision)
+	}
+}
+
+func TestRunSimToPrecisionUnknownUnit(t *testing.T) {
+	var batches []*proto.SimOptions
+	request := &proto.RaidSimRequest{SimOptions: &proto.SimOptions{
+		Iterations:      100,
+		TargetPrecision: 1,
+		PrecisionUnits:  []*proto.UnitReference{{Type: proto.UnitReference_Player, Index: 3}},
+	}}
+
+	result := runSimToPrecision(request, nil, fakePrecisionRunner(&batches))
+	if result.Error == nil || len(batches) != 1 {
+		t.Fatalf("Expected an error for a player which isn't in the raid, got %v", result)
+	}
+}
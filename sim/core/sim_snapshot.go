@@ -0,0 +1,148 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// An action taken from outside the event loop, like a cast chosen by an interactive agent.
+// It must only reach the sim state through the sim it is given, so it can be replayed by forks.
+type SimAction func(sim *Simulation)
+
+// Returns an action casting a spell of the unit with the given index in Environment.AllUnits,
+// on its current target. Does nothing if the spell can't be cast.
+func CastAction(unitIndex int32, actionID ActionID) SimAction {
+	return func(sim *Simulation) {
+		unit := sim.Environment.AllUnits[unitIndex]
+		if spell := unit.GetSpell(actionID); spell != nil && spell.CanCast(sim, unit.CurrentTarget) {
+			spell.Cast(sim, unit.CurrentTarget)
+		}
+	}
+}
+
+type recordedAction struct {
+	step   int // Number of steps into the iteration when the action was taken.
+	action SimAction
+}
+
+// State needed to replay the current iteration from its start.
+type iterationStart struct {
+	rand      SplitMix64
+	testRands map[string]SplitMix64
+
+	baseDuration       time.Duration
+	durationIsEstimate bool
+}
+
+// A point within an iteration of a sim, from which any number of independent sims can be forked.
+//
+// Game state is full of callbacks closing over the units and spells of their sim, so it can't be
+// copied. Instead the snapshot records how the iteration got there: the RNG state at its start,
+// the number of steps since, and the actions taken from outside the event loop. Forking replays
+// those into a new sim built from the same request, which ends up in the same state since
+// iterations are deterministic. Forks are therefore a re-run rather than a copy of the state, and
+// take as long as simming the iteration up to the snapshot.
+//
+// Only interactive sims, see SimOptions.interactive, can be snapshotted, as other sims don't take
+// actions from outside the event loop and skip the bookkeeping.
+type SimSnapshot struct {
+	request *proto.RaidSimRequest
+	start   iterationStart
+	steps   int
+	actions []recordedAction
+
+	CurrentTime time.Duration
+}
+
+// Records the current state of the iteration. Snapshots can only be taken between steps, e.g.
+// when an interactive sim needs input, and not from within callbacks of the event loop.
+func (sim *Simulation) Snapshot() *SimSnapshot {
+	if sim.request == nil {
+		panic("Snapshots need an interactive sim created with NewSim")
+	}
+	if sim.inStep {
+		panic("Snapshots can't be taken during a step")
+	}
+	return &SimSnapshot{
+		request:     sim.request,
+		start:       sim.iterationStart,
+		steps:       sim.steps,
+		actions:     append([]recordedAction(nil), sim.actions...),
+		CurrentTime: sim.CurrentTime,
+	}
+}
+
+// Shorthand for sim.Snapshot().Fork().
+func (sim *Simulation) Fork() *Simulation {
+	return sim.Snapshot().Fork()
+}
+
+// Returns a new sim in the same state as the sim was when the snapshot was taken. Forks are
+// independent of each other and of the original sim, and can be snapshotted again.
+//
+// Forks don't run presims, but keep the fight duration estimated by them. They don't share the
+// logger or signals of the original sim, nor its metrics of earlier iterations.
+func (snapshot *SimSnapshot) Fork() *Simulation {
+	sim := NewSim(snapshot.request, simsignals.CreateSignals())
+	sim.BaseDuration = snapshot.start.baseDuration
+	sim.Encounter.DurationIsEstimate = snapshot.start.durationIsEstimate
+
+	rand := snapshot.start.rand
+	sim.rand = &rand
+	for label, labelRand := range snapshot.start.testRands {
+		labelRand := labelRand
+		sim.testRands[label] = &labelRand
+	}
+
+	sim.reset()
+	sim.PrePull()
+
+	actions := snapshot.actions
+	for {
+		for len(actions) > 0 && actions[0].step == sim.steps {
+			sim.Act(actions[0].action)
+			actions = actions[1:]
+		}
+		if sim.steps == snapshot.steps {
+			break
+		}
+		if sim.Step() {
+			panic("Sim finished before reaching the snapshot")
+		}
+	}
+	return sim
+}
+
+// Runs an action from outside the event loop, recording it so forks of the sim can replay it.
+func (sim *Simulation) Act(action SimAction) {
+	if sim.request != nil {
+		sim.actions = append(sim.actions, recordedAction{step: sim.steps, action: action})
+	}
+	action(sim)
+}
+
+// Records the state the current iteration starts from, called by reset() before drawing from the RNG.
+func (sim *Simulation) recordIterationStart() {
+	if sim.request == nil {
+		return
+	}
+	sim.steps = 0
+	sim.actions = sim.actions[:0]
+
+	sim.iterationStart = iterationStart{
+		rand:               *sim.rand.(*SplitMix64),
+		testRands:          make(map[string]SplitMix64, len(sim.testRands)),
+		baseDuration:       sim.BaseDuration,
+		durationIsEstimate: sim.Encounter.DurationIsEstimate,
+	}
+	for label, labelRand := range sim.testRands {
+		sim.iterationStart.testRands[label] = *labelRand.(*SplitMix64)
+	}
+}
+
+func cloneRaidSimRequest(rsr *proto.RaidSimRequest) *proto.RaidSimRequest {
+	return googleProto.Clone(rsr).(*proto.RaidSimRequest)
+}
@@ -0,0 +1,106 @@
+package core
+
+import (
+	"math"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestSimSnapshotFork(t *testing.T) {
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed:  101,
+			Interactive: true,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Victim",
+							Class:     proto.Class_ClassShaman,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+			DeathModel: &proto.DeathModel{
+				Enabled: true,
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{
+				{
+					Name:  "target",
+					Level: 63,
+					Spells: []*proto.TargetSpell{{
+						SpellId:      686,
+						SpellSchool:  proto.SpellSchool_SpellSchoolShadow,
+						Cooldown:     2,
+						InitialDelay: 1,
+						MinDamage:    1,
+						MaxDamage:    50,
+					}},
+				},
+			},
+			Duration:          180,
+			DurationVariation: 20,
+		},
+	}, simsignals.CreateSignals())
+	// Use another seed than the request, like later iterations do.
+	sim.Reseed(7)
+	sim.Reset()
+	sim.PrePull()
+
+	health := func(sim *Simulation) float64 {
+		return sim.Raid.Parties[0].Players[0].GetCharacter().CurrentHealth()
+	}
+	steps := func(sim *Simulation, n int) {
+		for i := 0; i < n; i++ {
+			if sim.Step() {
+				t.Fatalf("Sim finished early")
+			}
+		}
+	}
+
+	steps(sim, 20)
+	sim.Act(func(sim *Simulation) {
+		sim.Raid.Parties[0].Players[0].GetCharacter().RemoveHealth(sim, 1000)
+	})
+	steps(sim, 20)
+
+	snapshot := sim.Snapshot()
+	fork := snapshot.Fork()
+	if fork.CurrentTime != sim.CurrentTime || fork.Duration != sim.Duration || health(fork) != health(sim) {
+		t.Fatalf("Expected fork at %s with %s duration and %0.1f health, got %s, %s and %0.1f",
+			sim.CurrentTime, sim.Duration, health(sim), fork.CurrentTime, fork.Duration, health(fork))
+	}
+
+	// Forks continue like the original, including their RNG rolls.
+	steps(sim, 20)
+	steps(fork, 20)
+	if fork.CurrentTime != sim.CurrentTime || health(fork) != health(sim) {
+		t.Fatalf("Expected fork to match the original sim, got %0.1f health vs %0.1f", health(fork), health(sim))
+	}
+
+	// Forks are independent, and can branch off with different actions.
+	branch := snapshot.Fork()
+	branch.Act(func(sim *Simulation) {
+		sim.Raid.Parties[0].Players[0].GetCharacter().RemoveHealth(sim, 500)
+	})
+	steps(branch, 20)
+	if branch.CurrentTime != sim.CurrentTime || math.Abs(health(branch)-(health(sim)-500)) > 1e-6 {
+		t.Fatalf("Expected branch to lose 500 more health than the original sim, got %0.1f vs %0.1f", health(branch), health(sim))
+	}
+
+	// Forks of forks replay the actions of the branch as well.
+	if forkOfBranch := branch.Fork(); health(forkOfBranch) != health(branch) {
+		t.Fatalf("Expected fork of branch to have %0.1f health, got %0.1f", health(branch), health(forkOfBranch))
+	}
+}
@@ -536,6 +536,13 @@ func (spell *Spell) CanCast(sim *Simulation, target *Unit) bool {
 		return false
 	}
 
+	if spell.Unit.IsSchoolLockedOut(sim, spell.SpellSchool) {
+		//if sim.Log != nil {
+		//	sim.Log("Cant cast because spell school is locked out")
+		//}
+		return false
+	}
+
 	if spell.DefaultCast.GCD > 0 && !spell.Unit.GCD.IsReady(sim) {
 		//if sim.Log != nil {
 		//	sim.Log("Cant cast because of GCD")
@@ -572,6 +579,9 @@ func (spell *Spell) Cast(sim *Simulation, target *Unit) bool {
 func (spell *Spell) applyEffects(sim *Simulation, target *Unit) {
 	spell.SpellMetrics[target.UnitIndex].Casts++
 	spell.casts++
+	if sim.phaseTracker != nil && !spell.Flags.Matches(SpellFlagNoMetrics) && !spell.Flags.Matches(SpellFlagPassiveSpell) {
+		sim.phaseTracker.addCast(sim, spell.Unit, spell.ActionID.WithTag(spell.Tag))
+	}
 
 	spell.ApplyEffects(sim, target, spell)
 }
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"slices"
+)
+
+// Returns the ranks of the spell known by the unit, highest rank first. The ranks of a spell are
+// the spells with the same spell code, so spells without a spell code or rank only have themselves.
+func (unit *Unit) SpellRanks(spell *Spell) []*Spell {
+	if spell.SpellCode == 0 || spell.Rank == 0 {
+		return []*Spell{spell}
+	}
+	ranks := FilterSlice(unit.Spellbook, func(s *Spell) bool {
+		return s.SpellCode == spell.SpellCode && s.Rank > 0
+	})
+	slices.SortStableFunc(ranks, func(a, b *Spell) int {
+		return b.Rank - a.Rank
+	})
+	return ranks
+}
+
+// Mana cost of casting the spell right now, or 0 for spells which don't cost mana.
+func (spell *Spell) manaCost() float64 {
+	if spell.Cost == nil || spell.Cost.CostType() != CostTypeMana {
+		return 0
+	}
+	return spell.Cost.GetCurrentCost()
+}
+
+// Whether the unit has the mana to cast the spell. Unlike CanCast, this doesn't start an OOM
+// event when it doesn't, so it can be used to check ranks which might not be cast.
+func (spell *Spell) canAffordMana() bool {
+	return spell.Unit.CurrentMana() >= spell.manaCost()
+}
+
+// Average damage, healing and shielding per cast of the spell in the current iteration, so the
+// estimate doesn't depend on earlier iterations. Spells often deal their damage through tick or
+// hit spells with the same spell id, e.g. channels, so those count towards the spell. Returns false
+// if the spell hasn't been cast in this iteration yet.
+func (spell *Spell) valuePerCast() (float64, bool) {
+	if spell.casts == 0 {
+		return 0, false
+	}
+	var value float64
+	for _, s := range spell.Unit.Spellbook {
+		if s != spell && (spell.ActionID.Tag != 0 || !s.ActionID.SameActionIgnoreTag(spell.ActionID)) {
+			continue
+		}
+		for _, spellMetrics := range s.splitSpellMetrics {
+			for _, targetMetrics := range spellMetrics {
+				value += targetMetrics.TotalDamage + targetMetrics.TotalHealing + targetMetrics.TotalShielding
+			}
+		}
+	}
+	return value / float64(spell.casts), true
+}
@@ -0,0 +1,205 @@
+package core
+
+import (
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+func TestCastSpellRank(t *testing.T) {
+	const rankedSpellsItemID = 999921
+	const rankedSpellCode = 999
+
+	// Each rank does more damage per cast, but less per mana.
+	manaCosts := []float64{100, 200, 400}
+	damages := []float64{150, 250, 400}
+	castTimes := []time.Duration{time.Second * 3 / 2, time.Second * 2, time.Second * 3}
+
+	ItemsByID[rankedSpellsItemID] = Item{ID: rankedSpellsItemID, Type: proto.ItemType_ItemTypeTrinket, Stats: stats.Stats{stats.Intellect: 200}}
+	defer delete(ItemsByID, rankedSpellsItemID)
+	itemEffects[rankedSpellsItemID] = func(agent Agent) {
+		character := agent.GetCharacter()
+		character.EnableManaBar()
+		for i := range manaCosts {
+			damage := damages[i]
+			// Like channels, the ranks deal their damage through a tick spell with the same spell id.
+			tickSpell := character.RegisterSpell(SpellConfig{
+				ActionID:         ActionID{SpellID: int32(1000 + i)}.WithTag(1),
+				SpellSchool:      SpellSchoolFire,
+				ProcMask:         ProcMaskSpellDamage,
+				DamageMultiplier: 1,
+				ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+					spell.CalcAndDealDamage(sim, target, damage, spell.OutcomeAlwaysHit)
+				},
+			})
+			character.RegisterSpell(SpellConfig{
+				ActionID:    ActionID{SpellID: int32(1000 + i)},
+				SpellCode:   rankedSpellCode,
+				SpellSchool: SpellSchoolFire,
+				ProcMask:    ProcMaskSpellDamage,
+				Flags:       SpellFlagAPL,
+				Rank:        i + 1,
+				ManaCost: ManaCostOptions{
+					FlatCost: manaCosts[i],
+				},
+				Cast: CastConfig{
+					DefaultCast: Cast{
+						GCD:      GCDDefault,
+						CastTime: castTimes[i],
+					},
+				},
+				ApplyEffects: func(sim *Simulation, target *Unit, spell *Spell) {
+					tickSpell.Cast(sim, target)
+				},
+			})
+		}
+	}
+	defer delete(itemEffects, rankedSpellsItemID)
+
+	sim := NewSim(&proto.RaidSimRequest{
+		SimOptions: &proto.SimOptions{
+			RandomSeed: 100,
+		},
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{
+				{
+					Players: []*proto.Player{
+						{
+							Name:      "Caster",
+							Class:     proto.Class_ClassShaman,
+							Level:     60,
+							Consumes:  &proto.Consumes{},
+							Buffs:     &proto.IndividualBuffs{},
+							Spec:      &proto.Player_ElementalShaman{},
+							Equipment: &proto.EquipmentSpec{Items: []*proto.ItemSpec{{}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {}, {Id: rankedSpellsItemID}}},
+						},
+					},
+					Buffs: &proto.PartyBuffs{},
+				},
+			},
+		},
+		Encounter: &proto.Encounter{
+			Targets:  []*proto.Target{{Name: "target", Level: 63}},
+			Duration: 15,
+		},
+	}, simsignals.CreateSignals())
+	sim.Reset()
+	sim.PrePull()
+
+	fa := sim.Raid.Parties[0].Players[0].(*FakeAgent)
+	rot := &APLRotation{unit: &fa.Unit}
+	ranks := fa.SpellRanks(fa.GetSpell(ActionID{SpellID: 1001}))
+	if len(ranks) != 3 || ranks[0].Rank != 3 || ranks[2].Rank != 1 {
+		t.Fatalf("Expected 3 ranks, highest first, got %d", len(ranks))
+	}
+
+	newAction := func(policy proto.APLActionCastSpellRank_RankPolicy, deadline string) *APLActionCastSpellRank {
+		config := &proto.APLActionCastSpellRank{
+			SpellId: ActionID{SpellID: 1000}.ToProto(),
+			Policy:  policy,
+		}
+		if deadline != "" {
+			config.Deadline = &proto.APLValue{Value: &proto.APLValue_Const{Const: &proto.APLValueConst{Val: deadline}}}
+		}
+		return rot.newActionCastSpellRank(config).(*APLActionCastSpellRank)
+	}
+	setMana := func(mana float64) {
+		fa.SpendMana(sim, fa.CurrentMana(), fa.manaNotCastingMetrics)
+		fa.AddMana(sim, mana, fa.manaNotCastingMetrics)
+	}
+	expectRank := func(action *APLActionCastSpellRank, expected int) {
+		t.Helper()
+		rank := action.chooseRank(sim)
+		if expected == 0 && rank != nil {
+			t.Fatalf("%s: expected no rank, got rank %d", action, rank.Rank)
+		} else if expected != 0 && (rank == nil || rank.Rank != expected) {
+			t.Fatalf("%s: expected rank %d, got %v", action, expected, rank)
+		}
+	}
+
+	maxRank := newAction(proto.APLActionCastSpellRank_RankPolicyMaxRank, "")
+	setMana(1000)
+	expectRank(maxRank, 3)
+	// Downranks once the higher ranks can't be afforded.
+	setMana(250)
+	expectRank(maxRank, 2)
+	setMana(50)
+	expectRank(maxRank, 0)
+
+	// Casting for the rest of the 15s fight takes 2000 mana with rank 3, 1600 with rank 2 and 1000
+	// with rank 1. Without any rank fitting the budget, the lowest rank is cast.
+	manaBudget := newAction(proto.APLActionCastSpellRank_RankPolicyManaBudget, "")
+	setMana(2000)
+	expectRank(manaBudget, 3)
+	setMana(1800)
+	expectRank(manaBudget, 2)
+	setMana(500)
+	expectRank(manaBudget, 1)
+
+	deadline := newAction(proto.APLActionCastSpellRank_RankPolicyDeadline, "2.5s")
+	setMana(1000)
+	expectRank(deadline, 2)
+	expectRank(newAction(proto.APLActionCastSpellRank_RankPolicyDeadline, "1s"), 0)
+
+	// Every rank is tried once, after which the most efficient one is kept.
+	damagePerMana := newAction(proto.APLActionCastSpellRank_RankPolicyDamagePerMana, "")
+	for _, expected := range []int{3, 2, 1, 1} {
+		setMana(1000)
+		expectRank(damagePerMana, expected)
+		fa.GetSpell(ActionID{SpellID: int32(1000 + expected - 1)}).applyEffects(sim, fa.CurrentTarget)
+	}
+
+	sim.Cleanup()
+	families := fa.Metrics.ToProto().SpellFamilies
+	if len(families) != 1 || len(families[0].Ranks) != 3 || families[0].Casts != 4 || families[0].Ranks[0].Rank != 3 || families[0].Ranks[2].Casts != 2 {
+		t.Fatalf("Unexpected spell family metrics %v", families)
+	}
+
+	// Casts of earlier iterations don't count, so every rank is tried again.
+	sim.Reset()
+	sim.PrePull()
+	setMana(1000)
+	expectRank(damagePerMana, 3)
+}
+
+func TestCombineSpellFamilies(t *testing.T) {
+	rank := func(spellID int32, rank int32, casts int32) *proto.SpellRankMetrics {
+		return &proto.SpellRankMetrics{Id: ActionID{SpellID: spellID}.ToProto(), Rank: rank, Casts: casts, Damage: float64(casts) * 100}
+	}
+	family := func(ranks ...*proto.SpellRankMetrics) *proto.SpellFamilyMetrics {
+		family := &proto.SpellFamilyMetrics{Id: ranks[0].Id, Ranks: ranks}
+		for _, rank := range ranks {
+			family.Casts += rank.Casts
+			family.Damage += rank.Damage
+		}
+		return family
+	}
+
+	// The second result cast a higher rank, which becomes the id of the combined family.
+	base := &proto.UnitMetrics{}
+	rsrc := &raidSimResultCombiner{}
+	for _, unit := range []*proto.UnitMetrics{
+		{SpellFamilies: []*proto.SpellFamilyMetrics{family(rank(1001, 2, 3), rank(1000, 1, 1))}},
+		{SpellFamilies: []*proto.SpellFamilyMetrics{family(rank(1002, 3, 2), rank(1001, 2, 1))}},
+	} {
+		for _, addFamily := range unit.SpellFamilies {
+			rsrc.addSpellFamilyMetrics(base, addFamily)
+		}
+	}
+
+	if len(base.SpellFamilies) != 1 {
+		t.Fatalf("Expected 1 family, got %v", base.SpellFamilies)
+	}
+	combined := base.SpellFamilies[0]
+	if combined.Id.GetSpellId() != 1002 || combined.Casts != 7 || combined.Damage != 700 || len(combined.Ranks) != 3 {
+		t.Fatalf("Unexpected combined family %v", combined)
+	}
+	for i, expected := range []struct{ rank, casts int32 }{{3, 2}, {2, 4}, {1, 1}} {
+		if combined.Ranks[i].Rank != expected.rank || combined.Ranks[i].Casts != expected.casts {
+			t.Fatalf("Expected rank %d with %d casts, got %v", expected.rank, expected.casts, combined.Ranks[i])
+		}
+	}
+}
@@ -401,6 +401,12 @@ func (spell *Spell) dealDamageInternal(sim *Simulation, isPeriodic bool, result
 
 	if sim.CurrentTime >= 0 {
 		spell.SpellMetrics[result.Target.UnitIndex].TotalDamage += result.Damage
+		if timeSeries := spell.Unit.Metrics.timeSeries; timeSeries != nil && spell.Unit.IsOpponent(result.Target) && !spell.Flags.Matches(SpellFlagNoMetrics) {
+			timeSeries.addDamage(sim, spell.ActionID.WithTag(spell.Tag), result.Damage)
+		}
+		if sim.phaseTracker != nil && spell.Unit.IsOpponent(result.Target) && !spell.Flags.Matches(SpellFlagNoMetrics) {
+			sim.phaseTracker.addDamage(sim, spell.Unit, spell.ActionID.WithTag(spell.Tag), result.Damage)
+		}
 		if isPartialResist {
 			spell.SpellMetrics[result.Target.UnitIndex].TotalResistedDamage += result.Damage
 		}
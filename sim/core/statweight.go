@@ -273,7 +273,14 @@ func computeStatWeights(swcr *proto.StatWeightsCalcRequest) *proto.StatWeightsRe
 }
 
 // Run stat weight sims and compute weights.
-func runStatWeights(request *proto.StatWeightsRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) *proto.StatWeightsResult {
+func runStatWeights(request *proto.StatWeightsRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) (result *proto.StatWeightsResult) {
+	checkpoint := openRequestCheckpoint("statweights", request)
+	if checkpoint != nil {
+		defer func() {
+			checkpoint.finish(result != nil && result.Error == nil)
+		}()
+	}
+
 	requestData := buildStatWeightRequests(request)
 
 	var iterationsTotal int32 = requestData.BaseRequest.SimOptions.Iterations
@@ -315,6 +322,15 @@ func runStatWeights(request *proto.StatWeightsRequest, progress chan *proto.Prog
 	if IsRunningInWasm() || request.SimOptions.IsTest {
 		simFunc = RunSim
 	}
+	if checkpoint != nil {
+		uncheckpointedSimFunc := simFunc
+		runner := checkpoint.wrapRunner(func(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, _ bool, signals simsignals.Signals) *proto.RaidSimResult {
+			return uncheckpointedSimFunc(rsr, progress, signals)
+		})
+		simFunc = func(rsr *proto.RaidSimRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) *proto.RaidSimResult {
+			return runner(rsr, progress, false, signals)
+		}
+	}
 
 	baseProgress := make(chan *proto.ProgressMetrics, 100)
 	go simFunc(requestData.BaseRequest, baseProgress, signals)
@@ -0,0 +1,244 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Run a sim for every point of the grid spanned by the sweep axes.
+func runSweep(request *proto.SweepRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) *proto.SweepResult {
+	if request.BaseSettings == nil || request.BaseSettings.SimOptions == nil {
+		return &proto.SweepResult{Error: &proto.ErrorOutcome{Message: "sweep: no base settings given"}}
+	}
+
+	points, err := expandSweepGrid(request.Axes)
+	if err != nil {
+		return &proto.SweepResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+	}
+
+	// Build all requests up front, so invalid paths or values fail before anything is simmed.
+	requests := make([]*proto.RaidSimRequest, len(points))
+	for i, point := range points {
+		requests[i] = googleProto.Clone(request.BaseSettings).(*proto.RaidSimRequest)
+		for _, pv := range point {
+			if err := ApplySweepValue(requests[i], pv.Path, pv.Value); err != nil {
+				return &proto.SweepResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+			}
+		}
+	}
+
+	simResults, errorOutcome := runRaidSimBatch(requests, progress, signals)
+	if errorOutcome != nil {
+		return &proto.SweepResult{Error: errorOutcome}
+	}
+
+	result := &proto.SweepResult{}
+	for i, simResult := range simResults {
+		pointResult := &proto.SweepPointResult{
+			Values:               points[i],
+			RaidDps:              simResult.RaidMetrics.Dps,
+			AvgIterationDuration: simResult.AvgIterationDuration,
+		}
+		for _, party := range simResult.RaidMetrics.Parties {
+			for _, player := range party.Players {
+				if player.Name == "" {
+					continue
+				}
+				player.Actions = nil
+				player.Auras = nil
+				player.Resources = nil
+				player.Pets = nil
+				pointResult.Players = append(pointResult.Players, player)
+			}
+		}
+		result.Points = append(result.Points, pointResult)
+	}
+
+	return result
+}
+
+// Returns the cartesian product of all axis values. The first axis varies slowest.
+func expandSweepGrid(axes []*proto.SweepAxis) ([][]*proto.SweepPointValue, error) {
+	points := [][]*proto.SweepPointValue{{}}
+	for _, axis := range axes {
+		if axis.Path == "" {
+			return nil, fmt.Errorf("sweep: axis without path")
+		}
+		if len(axis.Values) == 0 {
+			return nil, fmt.Errorf("sweep: axis %q has no values", axis.Path)
+		}
+
+		newPoints := make([][]*proto.SweepPointValue, 0, len(points)*len(axis.Values))
+		for _, point := range points {
+			for _, value := range axis.Values {
+				newPoint := make([]*proto.SweepPointValue, len(point), len(point)+1)
+				copy(newPoint, point)
+				newPoints = append(newPoints, append(newPoint, &proto.SweepPointValue{Path: axis.Path, Value: value}))
+			}
+		}
+		points = newPoints
+	}
+	return points, nil
+}
+
+// ApplySweepValue sets the field at path in the request to value. See proto.SweepAxis for the path format.
+func ApplySweepValue(request *proto.RaidSimRequest, path string, value string) error {
+	if err := setFieldByPath(request.ProtoReflect(), strings.Split(path, "."), value); err != nil {
+		return fmt.Errorf("sweep: cannot set %q to %q: %w", path, value, err)
+	}
+	return nil
+}
+
+func setFieldByPath(msg protoreflect.Message, segments []string, value string) error {
+	fd := findField(msg.Descriptor(), segments[0])
+	if fd == nil {
+		return fmt.Errorf("no field %q in %s", segments[0], msg.Descriptor().Name())
+	}
+	rest := segments[1:]
+
+	switch {
+	case fd.IsMap():
+		return fmt.Errorf("map field %q is not supported", fd.Name())
+
+	case fd.IsList() && len(rest) == 0:
+		if fd.Message() == nil {
+			return fmt.Errorf("list field %q needs an index", fd.Name())
+		}
+		count, err := strconv.Atoi(value)
+		if err != nil || count < 1 {
+			return fmt.Errorf("list field %q can only be resized to a positive count", fd.Name())
+		}
+		return resizeMessageList(msg.Mutable(fd).List(), count)
+
+	case fd.IsList():
+		list := msg.Mutable(fd).List()
+		indices, err := listIndices(list, rest[0])
+		if err != nil {
+			return err
+		}
+		for _, i := range indices {
+			if fd.Message() != nil {
+				if len(rest) == 1 {
+					return fmt.Errorf("cannot assign a value to message %q", fd.Name())
+				}
+				if err := setFieldByPath(list.Get(i).Message(), rest[1:], value); err != nil {
+					return err
+				}
+				continue
+			}
+			if len(rest) > 1 {
+				return fmt.Errorf("field %q is not a message", fd.Name())
+			}
+			v, err := parseScalarValue(fd, value)
+			if err != nil {
+				return err
+			}
+			list.Set(i, v)
+		}
+		return nil
+
+	case fd.Message() != nil:
+		if len(rest) == 0 {
+			return fmt.Errorf("cannot assign a value to message %q", fd.Name())
+		}
+		return setFieldByPath(msg.Mutable(fd).Message(), rest, value)
+
+	default:
+		if len(rest) > 0 {
+			return fmt.Errorf("field %q is not a message", fd.Name())
+		}
+		v, err := parseScalarValue(fd, value)
+		if err != nil {
+			return err
+		}
+		msg.Set(fd, v)
+		return nil
+	}
+}
+
+func findField(md protoreflect.MessageDescriptor, name string) protoreflect.FieldDescriptor {
+	if fd := md.Fields().ByName(protoreflect.Name(name)); fd != nil {
+		return fd
+	}
+	return md.Fields().ByJSONName(name)
+}
+
+func listIndices(list protoreflect.List, segment string) ([]int, error) {
+	if segment == "*" {
+		indices := make([]int, list.Len())
+		for i := range indices {
+			indices[i] = i
+		}
+		return indices, nil
+	}
+
+	i, err := strconv.Atoi(segment)
+	if err != nil {
+		return nil, fmt.Errorf("invalid list index %q", segment)
+	}
+	if i < 0 || i >= list.Len() {
+		return nil, fmt.Errorf("list index %d out of range [0, %d)", i, list.Len())
+	}
+	return []int{i}, nil
+}
+
+// Truncates the list or grows it with copies of its first element.
+func resizeMessageList(list protoreflect.List, count int) error {
+	if list.Len() == 0 {
+		return fmt.Errorf("cannot resize an empty list")
+	}
+	if count < list.Len() {
+		list.Truncate(count)
+		return nil
+	}
+	first := list.Get(0).Message().Interface()
+	for list.Len() < count {
+		list.Append(protoreflect.ValueOfMessage(googleProto.Clone(first).ProtoReflect()))
+	}
+	return nil
+}
+
+func parseScalarValue(fd protoreflect.FieldDescriptor, value string) (protoreflect.Value, error) {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		v, err := strconv.ParseBool(value)
+		return protoreflect.ValueOfBool(v), err
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind:
+		v, err := strconv.ParseInt(value, 10, 32)
+		return protoreflect.ValueOfInt32(int32(v)), err
+	case protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		v, err := strconv.ParseInt(value, 10, 64)
+		return protoreflect.ValueOfInt64(v), err
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind:
+		v, err := strconv.ParseUint(value, 10, 32)
+		return protoreflect.ValueOfUint32(uint32(v)), err
+	case protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		v, err := strconv.ParseUint(value, 10, 64)
+		return protoreflect.ValueOfUint64(v), err
+	case protoreflect.FloatKind:
+		v, err := strconv.ParseFloat(value, 32)
+		return protoreflect.ValueOfFloat32(float32(v)), err
+	case protoreflect.DoubleKind:
+		v, err := strconv.ParseFloat(value, 64)
+		return protoreflect.ValueOfFloat64(v), err
+	case protoreflect.StringKind:
+		return protoreflect.ValueOfString(value), nil
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByName(protoreflect.Name(value)); ev != nil {
+			return protoreflect.ValueOfEnum(ev.Number()), nil
+		}
+		v, err := strconv.ParseInt(value, 10, 32)
+		if err != nil {
+			return protoreflect.Value{}, fmt.Errorf("unknown %s value %q", fd.Enum().Name(), value)
+		}
+		return protoreflect.ValueOfEnum(protoreflect.EnumNumber(v)), nil
+	default:
+		return protoreflect.Value{}, fmt.Errorf("unsupported field kind %s", fd.Kind())
+	}
+}
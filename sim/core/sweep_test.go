@@ -0,0 +1,82 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestExpandSweepGrid(t *testing.T) {
+	points, err := expandSweepGrid([]*proto.SweepAxis{
+		{Path: "encounter.duration", Values: []string{"60", "120", "300"}},
+		{Path: "encounter.targets", Values: []string{"1", "3"}},
+	})
+	if err != nil {
+		t.Fatalf("expandSweepGrid() failed: %v", err)
+	}
+	if len(points) != 6 {
+		t.Fatalf("expandSweepGrid() returned %d points, want 6", len(points))
+	}
+	if got := points[1][0].Value + "/" + points[1][1].Value; got != "60/3" {
+		t.Fatalf("expandSweepGrid() second point = %s, want 60/3", got)
+	}
+
+	if _, err := expandSweepGrid([]*proto.SweepAxis{{Path: "encounter.duration"}}); err == nil {
+		t.Fatalf("expandSweepGrid() with empty axis values should fail")
+	}
+}
+
+func TestApplySweepValue(t *testing.T) {
+	request := &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{Players: []*proto.Player{{Name: "a"}, {Name: "b"}}}},
+		},
+		Encounter: &proto.Encounter{
+			Targets: []*proto.Target{{Name: "boss", Level: 63}},
+		},
+	}
+
+	for _, tc := range []struct {
+		path  string
+		value string
+	}{
+		{"encounter.duration", "120"},
+		{"encounter.executeProportion20", "0.2"},
+		{"encounter.targets", "3"},
+		{"encounter.targets.2.mob_type", "MobTypeUndead"},
+		{"raid.parties.0.players.*.distance_from_target", "25"},
+	} {
+		if err := ApplySweepValue(request, tc.path, tc.value); err != nil {
+			t.Fatalf("ApplySweepValue(%s, %s) failed: %v", tc.path, tc.value, err)
+		}
+	}
+
+	if request.Encounter.Duration != 120 || request.Encounter.ExecuteProportion_20 != 0.2 {
+		t.Fatalf("encounter values not applied: %v", request.Encounter)
+	}
+	if len(request.Encounter.Targets) != 3 || request.Encounter.Targets[2].Level != 63 {
+		t.Fatalf("targets not resized with copies of the first target: %v", request.Encounter.Targets)
+	}
+	if request.Encounter.Targets[2].MobType != proto.MobType_MobTypeUndead || request.Encounter.Targets[0].MobType == proto.MobType_MobTypeUndead {
+		t.Fatalf("enum not applied to only the indexed target: %v", request.Encounter.Targets)
+	}
+	for _, player := range request.Raid.Parties[0].Players {
+		if player.DistanceFromTarget != 25 {
+			t.Fatalf("distance not applied to player %s", player.Name)
+		}
+	}
+
+	for _, tc := range []struct {
+		path  string
+		value string
+	}{
+		{"encounter.nonexistent", "1"},
+		{"encounter.duration", "long"},
+		{"encounter.targets.5.level", "60"},
+		{"encounter", "1"},
+	} {
+		if err := ApplySweepValue(request, tc.path, tc.value); err == nil {
+			t.Fatalf("ApplySweepValue(%s, %s) should fail", tc.path, tc.value)
+		}
+	}
+}
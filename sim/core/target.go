@@ -107,6 +107,8 @@ type Target struct {
 	Unit
 
 	AI TargetAI
+
+	spells []*targetSpell
 }
 
 func NewTarget(options *proto.Target, targetIndex int32) *Target {
@@ -157,6 +159,7 @@ func NewTarget(options *proto.Target, targetIndex int32) *Target {
 
 func (target *Target) Reset(sim *Simulation) {
 	target.Unit.reset(sim, nil)
+	target.CurrentTarget = target.defaultTarget
 	target.SetGCDTimer(sim, 0)
 	if target.AI != nil {
 		target.AI.Reset(sim)
@@ -176,6 +179,7 @@ func (target *Target) GetMetricsProto() *proto.UnitMetrics {
 	metrics.Name = target.Label
 	metrics.UnitIndex = target.UnitIndex
 	metrics.Auras = target.auraTracker.GetMetricsProto()
+	metrics.DamageTaken = target.Metrics.damageTakenToProto(target.Env.Raid)
 	return metrics
 }
 
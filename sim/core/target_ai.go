@@ -37,18 +37,34 @@ func (target *Target) initialize(config *proto.Target) {
 		}
 	}
 
+	target.registerTargetSpells(config.Spells)
+
 	if target.AI != nil {
 		target.AI.Initialize(target, config)
+	}
 
+	if target.HasRotation() {
 		target.gcdAction = &PendingAction{
 			Priority: ActionPriorityGCD,
 			OnAction: func(sim *Simulation) {
+				if hc := &target.Hardcast; hc.Expires != startingCDTime && !target.IsCasting(sim) {
+					hc.Expires = startingCDTime
+					if hc.OnComplete != nil {
+						hc.OnComplete(sim, hc.Target)
+					}
+				}
+
 				target.Rotation.DoNextAction(sim)
 			},
 		}
 	}
 }
 
+// Returns whether the target acts on its own, i.e. has a custom AI or casts spells.
+func (target *Target) HasRotation() bool {
+	return target.AI != nil || len(target.spells) > 0
+}
+
 // Empty Agent interface functions.
 func (target *Target) AddRaidBuffs(_ *proto.RaidBuffs)   {}
 func (target *Target) AddPartyBuffs(_ *proto.PartyBuffs) {}
@@ -58,6 +74,9 @@ func (target *Target) GetCharacter() *Character          { return nil }
 func (target *Target) Initialize()                       {}
 
 func (target *Target) ExecuteCustomRotation(sim *Simulation) {
+	if target.castTargetSpells(sim) {
+		return
+	}
 	if target.AI != nil {
 		target.AI.ExecuteCustomRotation(sim)
 	}
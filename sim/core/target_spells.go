@@ -0,0 +1,110 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// A spell cast by a target, configured through proto.TargetSpell.
+type targetSpell struct {
+	*Spell
+	initialDelay time.Duration
+}
+
+func (target *Target) registerTargetSpells(configs []*proto.TargetSpell) {
+	for _, config := range configs {
+		minDamage := config.MinDamage
+		maxDamage := max(config.MaxDamage, config.MinDamage)
+		hitsRaid := config.HitsRaid
+
+		flags := SpellFlagNone
+		if config.Interruptible {
+			flags |= SpellFlagInterruptible
+		}
+
+		spellConfig := SpellConfig{
+			ActionID:    ActionID{SpellID: config.SpellId},
+			SpellSchool: SpellSchoolFromProto(config.SpellSchool),
+			DefenseType: DefenseTypeMagic,
+			ProcMask:    ProcMaskSpellDamage,
+			Flags:       flags,
+
+			Cast: CastConfig{
+				DefaultCast: Cast{
+					CastTime: DurationFromSeconds(config.CastTime),
+				},
+			},
+
+			DamageMultiplier: 1,
+			ThreatMultiplier: 1,
+
+			ExpectedInitialDamage: func(sim *Simulation, victim *Unit, spell *Spell, _ bool) *SpellResult {
+				result := spell.CalcDamage(sim, victim, (minDamage+maxDamage)/2, spell.OutcomeExpectedMagicHit)
+				if hitsRaid {
+					result.Damage *= float64(len(sim.Raid.AllPlayerUnits))
+				}
+				return result
+			},
+
+			ApplyEffects: func(sim *Simulation, victim *Unit, spell *Spell) {
+				if !hitsRaid {
+					spell.CalcAndDealDamage(sim, victim, sim.Roll(minDamage, maxDamage), spell.OutcomeMagicHit)
+					return
+				}
+				for _, player := range sim.Raid.AllPlayerUnits {
+					spell.CalcAndDealDamage(sim, player, sim.Roll(minDamage, maxDamage), spell.OutcomeMagicHit)
+				}
+			},
+		}
+		if config.Cooldown > 0 {
+			spellConfig.Cast.CD = Cooldown{
+				Timer:    target.NewTimer(),
+				Duration: DurationFromSeconds(config.Cooldown),
+			}
+		}
+
+		target.spells = append(target.spells, &targetSpell{
+			Spell:        target.RegisterSpell(spellConfig),
+			initialDelay: DurationFromSeconds(config.InitialDelay),
+		})
+	}
+}
+
+// Casts the first ready target spell. Returns whether a spell was cast.
+func (target *Target) castTargetSpells(sim *Simulation) bool {
+	if len(target.spells) == 0 || target.IsCasting(sim) {
+		return false
+	}
+
+	victim := target.CurrentTarget
+	if victim == nil {
+		// For individual non tank sims we still want spells to be cast.
+		victim = target.Env.Raid.AllPlayerUnits[0]
+	}
+
+	nextCastAt := NeverExpires
+	for _, spell := range target.spells {
+		if sim.CurrentTime < spell.initialDelay {
+			nextCastAt = min(nextCastAt, spell.initialDelay)
+			continue
+		}
+		if spell.CanCast(sim, victim) {
+			return spell.Cast(sim, victim)
+		}
+		readyAt := sim.CurrentTime
+		if spell.CD.Timer != nil {
+			readyAt = max(readyAt, spell.CD.ReadyAt())
+		}
+		if target.IsSchoolLockedOut(sim, spell.SpellSchool) {
+			readyAt = max(readyAt, target.interruptLockoutExpires)
+		}
+		nextCastAt = min(nextCastAt, readyAt)
+	}
+
+	// Targets without an AI have nothing else to do, so sleep until the next spell is ready.
+	if target.AI == nil && nextCastAt > sim.CurrentTime && nextCastAt != NeverExpires {
+		target.WaitUntil(sim, nextCastAt)
+	}
+	return false
+}
@@ -0,0 +1,310 @@
+package core
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Sums of metrics over fight time in bins of binSize, over all iterations.
+type timeSeriesMetrics struct {
+	binSize time.Duration
+
+	iterations []int32
+	seconds    []float64 // Fight time within each bin.
+	damage     []float64
+
+	actionIDs    []ActionID
+	actionDamage map[ActionID][]float64
+
+	resourceTypes  []proto.ResourceType
+	resourceLevels map[proto.ResourceType][]float64
+
+	auraIDs    []ActionID
+	auraUptime map[ActionID][]float64 // In seconds.
+}
+
+func newTimeSeriesMetrics(binSize time.Duration) *timeSeriesMetrics {
+	return &timeSeriesMetrics{
+		binSize:        binSize,
+		actionDamage:   make(map[ActionID][]float64),
+		resourceLevels: make(map[proto.ResourceType][]float64),
+		auraUptime:     make(map[ActionID][]float64),
+	}
+}
+
+// Returns the values with at least bin+1 elements.
+func growBins(values []float64, bin int) []float64 {
+	for len(values) <= bin {
+		values = append(values, 0)
+	}
+	return values
+}
+
+func (ts *timeSeriesMetrics) addDamage(sim *Simulation, actionID ActionID, damage float64) {
+	bin := int(sim.CurrentTime / ts.binSize)
+	ts.damage = growBins(ts.damage, bin)
+	ts.damage[bin] += damage
+
+	values, ok := ts.actionDamage[actionID]
+	if !ok {
+		ts.actionIDs = append(ts.actionIDs, actionID)
+	}
+	values = growBins(values, bin)
+	values[bin] += damage
+	ts.actionDamage[actionID] = values
+}
+
+// Adds the time between start and end to the uptime of the aura, split over the bins.
+func (ts *timeSeriesMetrics) addUptime(actionID ActionID, start time.Duration, end time.Duration) {
+	if end <= start {
+		return
+	}
+	values, ok := ts.auraUptime[actionID]
+	if !ok {
+		ts.auraIDs = append(ts.auraIDs, actionID)
+	}
+	for bin := int(start / ts.binSize); time.Duration(bin)*ts.binSize < end; bin++ {
+		binStart := time.Duration(bin) * ts.binSize
+		overlap := min(end, binStart+ts.binSize) - max(start, binStart)
+		values = growBins(values, bin)
+		values[bin] += overlap.Seconds()
+	}
+	ts.auraUptime[actionID] = values
+}
+
+func (ts *timeSeriesMetrics) addResourceLevel(resourceType proto.ResourceType, bin int, level float64) {
+	values, ok := ts.resourceLevels[resourceType]
+	if !ok {
+		ts.resourceTypes = append(ts.resourceTypes, resourceType)
+	}
+	values = growBins(values, bin)
+	values[bin] += level
+	ts.resourceLevels[resourceType] = values
+}
+
+// Adds the fight time of the iteration to its bins, so partial bins at the end are averaged correctly.
+func (ts *timeSeriesMetrics) doneIteration(sim *Simulation) {
+	for bin := 0; time.Duration(bin)*ts.binSize < sim.Duration; bin++ {
+		binStart := time.Duration(bin) * ts.binSize
+		ts.seconds = growBins(ts.seconds, bin)
+		ts.seconds[bin] += (min(sim.Duration, binStart+ts.binSize) - binStart).Seconds()
+	}
+}
+
+// Records that the current iteration reached the bin, and the resource levels of the unit at its start.
+func (ts *timeSeriesMetrics) sample(unit *Unit, bin int) {
+	for len(ts.iterations) <= bin {
+		ts.iterations = append(ts.iterations, 0)
+	}
+	ts.iterations[bin]++
+
+	if unit.HasHealthBar() {
+		ts.addResourceLevel(proto.ResourceType_ResourceTypeHealth, bin, unit.CurrentHealth())
+	}
+	if unit.HasManaBar() {
+		ts.addResourceLevel(proto.ResourceType_ResourceTypeMana, bin, unit.CurrentMana())
+	}
+	if unit.HasRageBar() {
+		ts.addResourceLevel(proto.ResourceType_ResourceTypeRage, bin, unit.CurrentRage())
+	}
+	if unit.HasEnergyBar() {
+		ts.addResourceLevel(proto.ResourceType_ResourceTypeEnergy, bin, unit.CurrentEnergy())
+		ts.addResourceLevel(proto.ResourceType_ResourceTypeComboPoints, bin, float64(unit.ComboPoints()))
+	}
+	if unit.HasFocusBar() {
+		ts.addResourceLevel(proto.ResourceType_ResourceTypeFocus, bin, unit.CurrentFocus())
+	}
+}
+
+func (ts *timeSeriesMetrics) ToProto() *proto.TimeSeriesMetrics {
+	// Rates are averaged over the fight time within the bins, levels over the iterations reaching them.
+	average := func(values []float64, totals func(bin int) float64) []float64 {
+		averages := make([]float64, len(ts.iterations))
+		for bin := range averages {
+			if total := totals(bin); bin < len(values) && total > 0 {
+				averages[bin] = values[bin] / total
+			}
+		}
+		return averages
+	}
+	perSecond := func(values []float64) []float64 {
+		return average(values, func(bin int) float64 {
+			if bin < len(ts.seconds) {
+				return ts.seconds[bin]
+			}
+			return 0
+		})
+	}
+	perIteration := func(values []float64) []float64 {
+		return average(values, func(bin int) float64 { return float64(ts.iterations[bin]) })
+	}
+
+	metrics := &proto.TimeSeriesMetrics{
+		BinSeconds: ts.binSize.Seconds(),
+		Iterations: ts.iterations,
+		Dps:        perSecond(ts.damage),
+	}
+	for _, actionID := range ts.actionIDs {
+		metrics.Actions = append(metrics.Actions, &proto.ActionTimeSeries{
+			Id:  actionID.ToProto(),
+			Dps: perSecond(ts.actionDamage[actionID]),
+		})
+	}
+	for _, resourceType := range ts.resourceTypes {
+		metrics.Resources = append(metrics.Resources, &proto.ResourceTimeSeries{
+			Type:   resourceType,
+			Levels: perIteration(ts.resourceLevels[resourceType]),
+		})
+	}
+	for _, auraID := range ts.auraIDs {
+		metrics.Auras = append(metrics.Auras, &proto.AuraTimeSeries{
+			Id:      auraID.ToProto(),
+			Uptimes: perSecond(ts.auraUptime[auraID]),
+		})
+	}
+	return metrics
+}
+
+// Samples the time series of all units at the start of each bin.
+type timeSeriesSampler struct {
+	units      []*Unit
+	binSize    time.Duration
+	nextSample time.Duration
+}
+
+func (sampler *timeSeriesSampler) RunTask(sim *Simulation) time.Duration {
+	if sim.CurrentTime < sampler.nextSample {
+		return sampler.nextSample
+	}
+
+	bin := int(sampler.nextSample / sampler.binSize)
+	for _, unit := range sampler.units {
+		unit.Metrics.timeSeries.sample(unit, bin)
+	}
+	sampler.nextSample += sampler.binSize
+	return sampler.nextSample
+}
+
+func (sim *Simulation) enableTimeSeries(binSeconds float64) {
+	binSize := DurationFromSeconds(binSeconds)
+	for _, unit := range sim.Environment.AllUnits {
+		unit.Metrics.timeSeries = newTimeSeriesMetrics(binSize)
+	}
+	sim.timeSeriesSampler = &timeSeriesSampler{
+		units:   sim.Environment.AllUnits,
+		binSize: binSize,
+	}
+}
+
+func (sim *Simulation) initTimeSeriesSampler() {
+	if sim.timeSeriesSampler == nil {
+		return
+	}
+	sim.timeSeriesSampler.nextSample = 0
+	sim.AddTask(sim.timeSeriesSampler)
+	sim.RescheduleTask(0)
+}
+
+// Adds the damage of a pet to the time series of its owner, like the owner's DPS.
+func addPetTimeSeries(owner *proto.TimeSeriesMetrics, pet *proto.TimeSeriesMetrics) {
+	for bin := range owner.Dps {
+		if bin < len(pet.Dps) {
+			owner.Dps[bin] += pet.Dps[bin]
+		}
+	}
+}
+
+// Combines the time series of two results, weighting each bin by its iterations.
+func combineTimeSeries(base *proto.TimeSeriesMetrics, add *proto.TimeSeriesMetrics) *proto.TimeSeriesMetrics {
+	if base == nil {
+		return googleProto.Clone(add).(*proto.TimeSeriesMetrics)
+	}
+
+	baseIterations := base.Iterations
+	combine := func(baseValues []float64, addValues []float64) []float64 {
+		combined := make([]float64, max(len(baseIterations), len(add.Iterations)))
+		for bin := range combined {
+			var baseN, addN float64
+			if bin < len(baseIterations) {
+				baseN = float64(baseIterations[bin])
+			}
+			if bin < len(add.Iterations) {
+				addN = float64(add.Iterations[bin])
+			}
+			if baseN+addN == 0 {
+				continue
+			}
+			if bin < len(baseValues) {
+				combined[bin] += baseValues[bin] * baseN
+			}
+			if bin < len(addValues) {
+				combined[bin] += addValues[bin] * addN
+			}
+			combined[bin] /= baseN + addN
+		}
+		return combined
+	}
+
+	combined := &proto.TimeSeriesMetrics{
+		BinSeconds: base.BinSeconds,
+		Dps:        combine(base.Dps, add.Dps),
+	}
+	combined.Iterations = make([]int32, max(len(baseIterations), len(add.Iterations)))
+	for bin := range combined.Iterations {
+		if bin < len(baseIterations) {
+			combined.Iterations[bin] += baseIterations[bin]
+		}
+		if bin < len(add.Iterations) {
+			combined.Iterations[bin] += add.Iterations[bin]
+		}
+	}
+
+	combined.Actions = combineSeriesByKey(base.Actions, add.Actions, combine,
+		func(a *proto.ActionTimeSeries) ActionID { return ProtoToActionID(a.Id) },
+		func(a *proto.ActionTimeSeries) []float64 { return a.Dps },
+		func(a *proto.ActionTimeSeries, dps []float64) *proto.ActionTimeSeries {
+			return &proto.ActionTimeSeries{Id: a.Id, Dps: dps}
+		})
+	combined.Resources = combineSeriesByKey(base.Resources, add.Resources, combine,
+		func(r *proto.ResourceTimeSeries) proto.ResourceType { return r.Type },
+		func(r *proto.ResourceTimeSeries) []float64 { return r.Levels },
+		func(r *proto.ResourceTimeSeries, levels []float64) *proto.ResourceTimeSeries {
+			return &proto.ResourceTimeSeries{Type: r.Type, Levels: levels}
+		})
+	combined.Auras = combineSeriesByKey(base.Auras, add.Auras, combine,
+		func(a *proto.AuraTimeSeries) ActionID { return ProtoToActionID(a.Id) },
+		func(a *proto.AuraTimeSeries) []float64 { return a.Uptimes },
+		func(a *proto.AuraTimeSeries, uptimes []float64) *proto.AuraTimeSeries {
+			return &proto.AuraTimeSeries{Id: a.Id, Uptimes: uptimes}
+		})
+	return combined
+}
+
+// Combines the series of base and add with the same key, in order of their first appearance.
+func combineSeriesByKey[T any, K comparable](baseSeries []T, addSeries []T, combine func([]float64, []float64) []float64,
+	key func(T) K, values func(T) []float64, newSeries func(T, []float64) T) []T {
+
+	addByKey := make(map[K]T, len(addSeries))
+	for _, series := range addSeries {
+		addByKey[key(series)] = series
+	}
+
+	var combined []T
+	inBase := make(map[K]bool, len(baseSeries))
+	for _, series := range baseSeries {
+		var addValues []float64
+		if addSeries, ok := addByKey[key(series)]; ok {
+			addValues = values(addSeries)
+		}
+		combined = append(combined, newSeries(series, combine(values(series), addValues)))
+		inBase[key(series)] = true
+	}
+	for _, series := range addSeries {
+		if !inBase[key(series)] {
+			combined = append(combined, newSeries(series, combine(nil, values(series))))
+		}
+	}
+	return combined
+}
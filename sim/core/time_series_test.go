@@ -0,0 +1,56 @@
+package core
+
+import (
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestTimeSeriesMetrics(t *testing.T) {
+	ts := newTimeSeriesMetrics(time.Second * 10)
+	sim := &Simulation{Duration: time.Second * 25}
+	aura := ActionID{SpellID: 1}
+
+	for bin := 0; bin < 3; bin++ {
+		ts.sample(&Unit{}, bin)
+	}
+	ts.addUptime(aura, time.Second*5, time.Second*22)
+	sim.CurrentTime = time.Second * 21
+	ts.addDamage(sim, ActionID{SpellID: 2}, 50)
+	ts.doneIteration(sim)
+
+	metrics := ts.ToProto()
+	if !slices.Equal(metrics.Auras[0].Uptimes, []float64{0.5, 1, 0.4}) {
+		t.Fatalf("Expected uptimes split over the bins, got %v", metrics.Auras[0].Uptimes)
+	}
+	// The last bin only covers 5s of fight time.
+	if !slices.Equal(metrics.Dps, []float64{0, 0, 10}) || !slices.Equal(metrics.Actions[0].Dps, metrics.Dps) {
+		t.Fatalf("Expected damage per second of fight time, got %v", metrics)
+	}
+}
+
+func TestCombineTimeSeries(t *testing.T) {
+	base := &proto.TimeSeriesMetrics{
+		BinSeconds: 10,
+		Iterations: []int32{3, 1},
+		Dps:        []float64{10, 20},
+		Resources:  []*proto.ResourceTimeSeries{{Type: proto.ResourceType_ResourceTypeMana, Levels: []float64{100, 50}}},
+	}
+	add := &proto.TimeSeriesMetrics{
+		BinSeconds: 10,
+		Iterations: []int32{1},
+		Dps:        []float64{30},
+		Actions:    []*proto.ActionTimeSeries{{Id: ActionID{SpellID: 1}.ToProto(), Dps: []float64{30}}},
+		Resources:  []*proto.ResourceTimeSeries{{Type: proto.ResourceType_ResourceTypeMana, Levels: []float64{200}}},
+	}
+
+	combined := combineTimeSeries(combineTimeSeries(nil, base), add)
+	if !slices.Equal(combined.Iterations, []int32{4, 1}) || !slices.Equal(combined.Dps, []float64{15, 20}) {
+		t.Fatalf("Expected bins weighted by iterations, got %v", combined)
+	}
+	if !slices.Equal(combined.Resources[0].Levels, []float64{125, 50}) || !slices.Equal(combined.Actions[0].Dps, []float64{7.5, 0}) {
+		t.Fatalf("Unexpected combined series %v", combined)
+	}
+}
@@ -0,0 +1,189 @@
+package core
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+	googleProto "google.golang.org/protobuf/proto"
+)
+
+// Ranking more trinkets than this is not computationally feasible, as every pair is simmed.
+const maxTrinketRankingTrinkets = 100
+
+// Sims every legal pair of trinkets at each fight length. All sims share the same seed and labeled
+// rands, so pairs are compared on the same fights rather than on run-to-run noise.
+func runTrinketRanking(request *proto.TrinketRankingRequest, progress chan *proto.ProgressMetrics, signals simsignals.Signals) *proto.TrinketRankingResult {
+	if request.BaseSettings == nil || request.BaseSettings.Raid == nil || request.BaseSettings.SimOptions == nil || request.BaseSettings.Encounter == nil {
+		return &proto.TrinketRankingResult{Error: &proto.ErrorOutcome{Message: "trinket ranking: no base settings given"}}
+	}
+
+	baseRequest := googleProto.Clone(request.BaseSettings).(*proto.RaidSimRequest)
+	baseRequest.SimOptions.UseLabeledRands = true
+	if baseRequest.SimOptions.RandomSeed == 0 {
+		baseRequest.SimOptions.RandomSeed = time.Now().UnixNano()
+	}
+	if request.IterationsPerPair > 0 {
+		baseRequest.SimOptions.Iterations = request.IterationsPerPair
+	}
+
+	// Like bulk sims, trinkets are ranked for a single player.
+	var players []*proto.Player
+	for _, party := range baseRequest.Raid.Parties {
+		for _, player := range party.Players {
+			if player.Name != "" {
+				players = append(players, player)
+			}
+		}
+	}
+	if len(players) != 1 || baseRequest.Raid.Parties[0].Players[0] != players[0] {
+		return &proto.TrinketRankingResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("trinket ranking: expected exactly 1 player in the first slot of the raid, found %d players", len(players))}}
+	}
+	player := players[0]
+	if player.Database != nil {
+		addToDatabase(player.Database)
+	}
+	if player.Equipment == nil {
+		player.Equipment = &proto.EquipmentSpec{}
+	}
+	for len(player.Equipment.Items) < len(proto.ItemSlot_name) {
+		player.Equipment.Items = append(player.Equipment.Items, &proto.ItemSpec{})
+	}
+
+	trinkets, err := trinketRankingPool(request, player)
+	if err != nil {
+		return &proto.TrinketRankingResult{Error: &proto.ErrorOutcome{Message: err.Error()}}
+	}
+	if len(trinkets) > maxTrinketRankingTrinkets {
+		return &proto.TrinketRankingResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf(
+			"trinket ranking: too many trinkets (%d > %d), not computationally feasible, narrow them down by ids or phase", len(trinkets), maxTrinketRankingTrinkets)}}
+	}
+
+	durations := request.Durations
+	if len(durations) == 0 {
+		durations = []float64{baseRequest.Encounter.Duration}
+	}
+
+	type trinketPair struct {
+		trinket1, trinket2 int32
+	}
+	var pairs []trinketPair
+	var pairRequests []*proto.RaidSimRequest
+	for i, trinket1 := range trinkets {
+		for _, trinket2 := range trinkets[i+1:] {
+			// The change log is only needed to report bulk sim results, pairs are reported by id.
+			pairRequest, _ := createNewRequestWithSubstitution(baseRequest, &equipmentSubstitution{Items: []*itemWithSlot{
+				{Item: &proto.ItemSpec{Id: trinket1}, Slot: proto.ItemSlot_ItemSlotTrinket1},
+				{Item: &proto.ItemSpec{Id: trinket2}, Slot: proto.ItemSlot_ItemSlotTrinket2},
+			}}, false)
+			if isValidEquipment(pairRequest.Raid.Parties[0].Players[0].Equipment) {
+				pairs = append(pairs, trinketPair{trinket1, trinket2})
+				pairRequests = append(pairRequests, pairRequest)
+			}
+		}
+	}
+	if len(pairs) == 0 {
+		return &proto.TrinketRankingResult{Error: &proto.ErrorOutcome{Message: "trinket ranking: no legal pairs of trinkets to sim"}}
+	}
+	if totalIterations := int64(len(pairs)*len(durations)) * int64(baseRequest.SimOptions.Iterations); totalIterations > math.MaxInt32 {
+		return &proto.TrinketRankingResult{Error: &proto.ErrorOutcome{Message: fmt.Sprintf("trinket ranking: number of total iterations %d too large", totalIterations)}}
+	}
+
+	var requests []*proto.RaidSimRequest
+	for _, duration := range durations {
+		for _, pairRequest := range pairRequests {
+			req := googleProto.Clone(pairRequest).(*proto.RaidSimRequest)
+			req.Encounter.Duration = duration
+			requests = append(requests, req)
+		}
+	}
+
+	simResults, errorOutcome := runRaidSimBatch(requests, progress, signals)
+	if errorOutcome != nil {
+		return &proto.TrinketRankingResult{Error: errorOutcome}
+	}
+
+	result := &proto.TrinketRankingResult{}
+	trinketDps := make(map[int32]*aggregator, len(trinkets))
+	for _, trinket := range trinkets {
+		trinketDps[trinket] = &aggregator{}
+	}
+	for i, duration := range durations {
+		durationResult := &proto.TrinketRankingDuration{Duration: duration}
+		for j, pair := range pairs {
+			dps := FirstPlayerMetrics(simResults[i*len(pairs)+j]).Dps
+			durationResult.Pairs = append(durationResult.Pairs, &proto.TrinketPairResult{
+				Trinket1Id: pair.trinket1,
+				Trinket2Id: pair.trinket2,
+				Dps:        dps.Avg,
+				DpsStdev:   dps.Stdev,
+			})
+			trinketDps[pair.trinket1].add(dps.Avg)
+			trinketDps[pair.trinket2].add(dps.Avg)
+		}
+		sort.SliceStable(durationResult.Pairs, func(a, b int) bool {
+			return durationResult.Pairs[a].Dps > durationResult.Pairs[b].Dps
+		})
+		result.Durations = append(result.Durations, durationResult)
+	}
+
+	// Trinkets without a legal pair aren't ranked.
+	for _, trinket := range trinkets {
+		if trinketDps[trinket].n > 0 {
+			result.TrinketIds = append(result.TrinketIds, trinket)
+		}
+	}
+	sort.SliceStable(result.TrinketIds, func(a, b int) bool {
+		meanA, _ := trinketDps[result.TrinketIds[a]].meanAndStdDev()
+		meanB, _ := trinketDps[result.TrinketIds[b]].meanAndStdDev()
+		return meanA > meanB
+	})
+
+	return result
+}
+
+// Returns the ids of the requested trinkets, or of all trinkets in the database which the player can equip.
+func trinketRankingPool(request *proto.TrinketRankingRequest, player *proto.Player) ([]int32, error) {
+	if len(request.TrinketIds) > 0 {
+		var trinkets []int32
+		for _, id := range request.TrinketIds {
+			item, ok := ItemsByID[id]
+			if !ok {
+				return nil, fmt.Errorf("trinket ranking: unknown item with id %d", id)
+			}
+			if item.Type != proto.ItemType_ItemTypeTrinket {
+				return nil, fmt.Errorf("trinket ranking: %s (%d) is not a trinket", item.Name, id)
+			}
+			if !slices.Contains(trinkets, id) {
+				trinkets = append(trinkets, id)
+			}
+		}
+		return trinkets, nil
+	}
+
+	var trinkets []int32
+	for id, item := range ItemsByID {
+		if item.Type == proto.ItemType_ItemTypeTrinket && canEquipInPhase(item, player, request.Phase) {
+			trinkets = append(trinkets, id)
+		}
+	}
+	// Sort for a stable order, as the database is a map.
+	slices.Sort(trinkets)
+	return trinkets, nil
+}
+
+// Returns whether the player can equip the item in the given phase, 0 for any phase. This isn't
+// an ItemFilter, as that only matches items which list the class and have an effect registered
+// for tests, which would drop most trinkets.
+func canEquipInPhase(item Item, player *proto.Player, phase int32) bool {
+	if player.Level > 0 && item.RequiresLevel > player.Level {
+		return false
+	} else if len(item.ClassAllowlist) > 0 && !slices.Contains(item.ClassAllowlist, player.Class) {
+		return false
+	}
+	return phase == 0 || item.Phase <= phase
+}
@@ -0,0 +1,102 @@
+package core
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/simsignals"
+)
+
+func TestTrinketRanking(t *testing.T) {
+	const trinket1ID = 999911
+	const trinket2ID = 999912
+	const laterPhaseTrinketID = 999913
+	const mageTrinketID = 999914
+	const ringID = 999915
+
+	items := []Item{
+		{ID: trinket1ID, Name: "Trinket 1", Type: proto.ItemType_ItemTypeTrinket, Phase: 1},
+		{ID: trinket2ID, Name: "Trinket 2", Type: proto.ItemType_ItemTypeTrinket, Phase: 2},
+		{ID: laterPhaseTrinketID, Name: "Later Trinket", Type: proto.ItemType_ItemTypeTrinket, Phase: 3},
+		{ID: mageTrinketID, Name: "Mage Trinket", Type: proto.ItemType_ItemTypeTrinket, ClassAllowlist: []proto.Class{proto.Class_ClassMage}},
+		{ID: ringID, Name: "Ring", Type: proto.ItemType_ItemTypeFinger},
+	}
+	for _, item := range items {
+		ItemsByID[item.ID] = item
+		defer delete(ItemsByID, item.ID)
+	}
+
+	newRequest := func() *proto.TrinketRankingRequest {
+		return &proto.TrinketRankingRequest{
+			BaseSettings: &proto.RaidSimRequest{
+				Raid: &proto.Raid{
+					Parties: []*proto.Party{
+						{
+							Players: []*proto.Player{
+								{
+									Name:      "Player",
+									Class:     proto.Class_ClassShaman,
+									Level:     60,
+									Consumes:  &proto.Consumes{},
+									Buffs:     &proto.IndividualBuffs{},
+									Spec:      &proto.Player_ElementalShaman{},
+									Equipment: &proto.EquipmentSpec{},
+								},
+							},
+							Buffs: &proto.PartyBuffs{},
+						},
+					},
+				},
+				Encounter: &proto.Encounter{
+					Targets:  []*proto.Target{{Name: "target", Level: 63}},
+					Duration: 180,
+				},
+				SimOptions: &proto.SimOptions{Iterations: 10, RandomSeed: 1},
+			},
+			Durations: []float64{60, 180},
+		}
+	}
+
+	// Without explicit trinkets, the pool is all trinkets the player can equip in the phase.
+	request := newRequest()
+	request.Phase = 2
+	result := runTrinketRanking(request, nil, simsignals.CreateSignals())
+	if result.Error != nil {
+		t.Fatalf("Trinket ranking failed: %s", result.Error.Message)
+	}
+	if len(result.TrinketIds) != 2 || !slices.Contains(result.TrinketIds, trinket1ID) || !slices.Contains(result.TrinketIds, trinket2ID) {
+		t.Fatalf("Expected trinkets %d and %d to be ranked, got %v", trinket1ID, trinket2ID, result.TrinketIds)
+	}
+	if len(result.Durations) != 2 {
+		t.Fatalf("Expected results for 2 durations, got %d", len(result.Durations))
+	}
+	for _, duration := range result.Durations {
+		if len(duration.Pairs) != 1 {
+			t.Fatalf("Expected 1 pair at %0.0fs, got %d", duration.Duration, len(duration.Pairs))
+		}
+	}
+
+	// Explicitly given trinkets skip the class and phase checks.
+	request = newRequest()
+	request.TrinketIds = []int32{trinket1ID, trinket2ID, laterPhaseTrinketID}
+	if result := runTrinketRanking(request, nil, simsignals.CreateSignals()); result.Error != nil || len(result.Durations[0].Pairs) != 3 {
+		t.Fatalf("Expected 3 pairs of the given trinkets, got %v", result)
+	}
+
+	request = newRequest()
+	request.TrinketIds = []int32{trinket1ID, ringID}
+	if result := runTrinketRanking(request, nil, simsignals.CreateSignals()); result.Error == nil {
+		t.Fatalf("Expected ranking a ring to fail")
+	}
+
+	request = newRequest()
+	for i := int32(0); i <= maxTrinketRankingTrinkets; i++ {
+		ItemsByID[998000+i] = Item{ID: 998000 + i, Type: proto.ItemType_ItemTypeTrinket}
+		defer delete(ItemsByID, 998000+i)
+		request.TrinketIds = append(request.TrinketIds, 998000+i)
+	}
+	if result := runTrinketRanking(request, nil, simsignals.CreateSignals()); result.Error == nil {
+		t.Fatalf("Expected ranking %d trinkets to fail", len(request.TrinketIds))
+	}
+}
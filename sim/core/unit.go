@@ -56,6 +56,9 @@ type Unit struct {
 	// Amount of time following a post-GCD channel tick, to when the next action can be performed.
 	ChannelClipDelay time.Duration
 
+	// Imperfect human execution, nil if disabled.
+	executionModel *executionModel
+
 	// How far this unit is from its target(s). Measured in yards, this is used
 	// for calculating spell travel time for certain spells.
 	StartDistanceFromTarget float64
@@ -139,6 +142,10 @@ type Unit struct {
 	// No more than one cast may be active at any given time.
 	Hardcast Hardcast
 
+	// Spell school locked out by the last interrupt, see Interrupt().
+	interruptLockoutSchool  SpellSchool
+	interruptLockoutExpires time.Duration
+
 	// GCD-related PendingActions.
 	gcdAction              *PendingAction
 	hardcastAction         *PendingAction
@@ -147,6 +154,7 @@ type Unit struct {
 	// Cached mana return values per tick.
 	manaTickWhileCasting    float64
 	manaTickWhileNotCasting float64
+	manaTickMP5             float64 // Included in the ticks above, only used for the mana metrics.
 
 	CastSpeed float64
 
@@ -466,6 +474,10 @@ func (unit *Unit) reset(sim *Simulation, _ Agent) {
 	unit.enabled = true
 	unit.resetCDs(sim)
 	unit.Hardcast.Expires = startingCDTime
+	unit.interruptLockoutExpires = 0
+	if unit.executionModel != nil {
+		unit.executionModel.reset()
+	}
 	unit.ChanneledDot = nil
 	unit.Metrics.reset()
 	unit.ResetStatDeps()
@@ -0,0 +1,318 @@
+package core
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"google.golang.org/protobuf/encoding/protowire"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+// Talent tree sizes of each class, see FillTalentsProto.
+var talentTreeSizes = map[proto.Class][3]int{}
+
+// Registers the talent tree sizes of a class, so its talent strings can be validated.
+func RegisterTalentTreeSizes(class proto.Class, treeSizes [3]int) {
+	talentTreeSizes[class] = treeSizes
+}
+
+type requestValidator struct {
+	issues []*proto.ValidationIssue
+
+	// Database of the player being validated. It's only added to the global database when the
+	// request is simmed, so validating doesn't change the global database.
+	db *proto.SimDatabase
+}
+
+func (v *requestValidator) errorf(path string, format string, args ...any) {
+	v.issues = append(v.issues, &proto.ValidationIssue{
+		Path:     path,
+		Severity: proto.ValidationSeverity_ValidationSeverityError,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+func (v *requestValidator) warnf(path string, format string, args ...any) {
+	v.issues = append(v.issues, &proto.ValidationIssue{
+		Path:     path,
+		Severity: proto.ValidationSeverity_ValidationSeverityWarning,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Checks a request for problems which would make the sim fail or give meaningless results, like items which
+// can't be equipped by the player. Paths use the JSON field names of the request. Requests are only rejected
+// because of errors if SimOptions.reject_invalid is set, as some checks are stricter than the sim itself.
+func ValidateRaidSimRequest(rsr *proto.RaidSimRequest) []*proto.ValidationIssue {
+	v := &requestValidator{}
+	if rsr.GetRaid() == nil {
+		v.errorf("raid", "Request has no raid")
+		return v.issues
+	}
+	for partyIdx, party := range rsr.Raid.Parties {
+		for playerIdx, player := range party.GetPlayers() {
+			v.validatePlayer(fmt.Sprintf("raid.parties[%d].players[%d]", partyIdx, playerIdx), player)
+		}
+	}
+	if rsr.Raid.Debuffs != nil {
+		v.validateDebuffProfiles("raid.debuffs.profiles", rsr.Raid.Debuffs, len(rsr.GetEncounter().GetTargets()))
+	}
+	return v.issues
+}
+
+func HasValidationErrors(issues []*proto.ValidationIssue) bool {
+	return slices.ContainsFunc(issues, func(issue *proto.ValidationIssue) bool {
+		return issue.Severity == proto.ValidationSeverity_ValidationSeverityError
+	})
+}
+
+// Returns an outcome for a request with validation errors, or nil if there are none.
+func validationErrorOutcome(issues []*proto.ValidationIssue) *proto.ErrorOutcome {
+	var messages []string
+	for _, issue := range issues {
+		if issue.Severity == proto.ValidationSeverity_ValidationSeverityError {
+			messages = append(messages, issue.Path+": "+issue.Message)
+		}
+	}
+	if len(messages) == 0 {
+		return nil
+	}
+	return &proto.ErrorOutcome{
+		Message: "Invalid request:\n" + strings.Join(messages, "\n"),
+		Issues:  issues,
+	}
+}
+
+func (v *requestValidator) validatePlayer(path string, player *proto.Player) {
+	if player.Spec == nil {
+		// Empty raid slots.
+		return
+	}
+	if player.Class == proto.Class_ClassUnknown {
+		v.errorf(path+".class", "Player has no class")
+	}
+	if player.Level < 0 || player.Level > CharacterMaxLevel {
+		v.errorf(path+".level", "Level %d is not between 1 and %d", player.Level, CharacterMaxLevel)
+	}
+
+	v.db = player.Database
+	v.validateEquipment(path+".equipment", player)
+	v.validateTalents(path+".talentsString", player)
+}
+
+func (v *requestValidator) item(id int32) (Item, bool) {
+	if item, ok := ItemsByID[id]; ok {
+		return item, true
+	}
+	for _, item := range v.db.GetItems() {
+		if item.Id == id {
+			return ItemFromProto(item), true
+		}
+	}
+	return Item{}, false
+}
+
+func (v *requestValidator) hasRandomSuffix(id int32) bool {
+	if _, ok := RandomSuffixesByID[id]; ok {
+		return true
+	}
+	return slices.ContainsFunc(v.db.GetRandomSuffixes(), func(suffix *proto.ItemRandomSuffix) bool { return suffix.Id == id })
+}
+
+func (v *requestValidator) hasEnchant(effectID int32) bool {
+	if _, ok := EnchantsByEffectID[effectID]; ok {
+		return true
+	}
+	return slices.ContainsFunc(v.db.GetEnchants(), func(enchant *proto.SimEnchant) bool { return enchant.EffectId == effectID })
+}
+
+func (v *requestValidator) rune(id int32) (Rune, bool) {
+	if r, ok := RunesByID[id]; ok {
+		return r, true
+	}
+	for _, r := range v.db.GetRunes() {
+		if r.Id == id {
+			return RuneFromProto(r), true
+		}
+	}
+	return Rune{}, false
+}
+
+func playerLevel(player *proto.Player) int32 {
+	if player.Level == 0 {
+		return CharacterMaxLevel
+	}
+	return player.Level
+}
+
+func className(class proto.Class) string {
+	return strings.TrimPrefix(class.String(), "Class")
+}
+
+func slotName(slot proto.ItemSlot) string {
+	return strings.TrimPrefix(slot.String(), "ItemSlot")
+}
+
+func (v *requestValidator) validateEquipment(path string, player *proto.Player) {
+	specs := player.GetEquipment().GetItems()
+	if len(specs) > len(Equipment{}) {
+		v.errorf(path+".items", "Equipment has %d items, but there are only %d slots", len(specs), len(Equipment{}))
+		return
+	}
+
+	var equipment Equipment
+	for i, spec := range specs {
+		slot := proto.ItemSlot(i)
+		itemPath := fmt.Sprintf("%s.items[%d]", path, i)
+		if spec.GetId() == 0 {
+			if spec.GetRune() != 0 {
+				v.errorf(itemPath+".rune", "Rune %d is engraved on the empty %s slot", spec.Rune, slotName(slot))
+			}
+			continue
+		}
+
+		item, ok := v.item(spec.Id)
+		if !ok {
+			v.errorf(itemPath+".id", "No item with id %d", spec.Id)
+			continue
+		}
+		equipment[slot] = item
+
+		if !slices.Contains(eligibleSlotsForItem(item), slot) {
+			v.errorf(itemPath+".id", "%s (%d) can't be equipped in the %s slot", item.Name, item.ID, slotName(slot))
+		}
+		if len(item.ClassAllowlist) > 0 && !slices.Contains(item.ClassAllowlist, player.Class) {
+			v.errorf(itemPath+".id", "%s (%d) can't be used by a %s", item.Name, item.ID, className(player.Class))
+		}
+		if item.RequiresLevel > playerLevel(player) {
+			v.errorf(itemPath+".id", "%s (%d) requires level %d", item.Name, item.ID, item.RequiresLevel)
+		}
+
+		if spec.RandomSuffix != 0 {
+			if !v.hasRandomSuffix(spec.RandomSuffix) {
+				v.errorf(itemPath+".randomSuffix", "No random suffix with id %d", spec.RandomSuffix)
+			}
+		}
+		if spec.Enchant != 0 {
+			if !v.hasEnchant(spec.Enchant) {
+				v.warnf(itemPath+".enchant", "No enchant with effect id %d, it will be ignored", spec.Enchant)
+			}
+		}
+		v.validateRune(itemPath+".rune", player, slot, spec.Rune)
+	}
+
+	if mh := equipment.MainHand(); mh.HandType == proto.HandType_HandTypeTwoHand && equipment.OffHand().ID != 0 {
+		v.errorf(fmt.Sprintf("%s.items[%d]", path, proto.ItemSlot_ItemSlotOffHand),
+			"%s (%d) can't be equipped with the two-handed %s (%d)", equipment.OffHand().Name, equipment.OffHand().ID, mh.Name, mh.ID)
+	}
+
+	for i := range equipment {
+		for j := i + 1; j < len(equipment); j++ {
+			if equipment[i].Unique && equipment[i].ID == equipment[j].ID {
+				v.errorf(fmt.Sprintf("%s.items[%d]", path, j), "%s (%d) is unique-equipped, but is also in the %s slot",
+					equipment[j].Name, equipment[j].ID, slotName(proto.ItemSlot(i)))
+			}
+		}
+	}
+}
+
+func (v *requestValidator) validateRune(path string, player *proto.Player, slot proto.ItemSlot, runeID int32) {
+	if runeID == 0 {
+		return
+	}
+	r, ok := v.rune(runeID)
+	if !ok {
+		v.warnf(path, "No rune with id %d", runeID)
+		return
+	}
+	if !slices.Contains(itemTypeToSlotsMap[r.Type], slot) {
+		v.errorf(path, "Rune %d can't be engraved on the %s slot", runeID, slotName(slot))
+	}
+	if len(r.ClassAllowlist) > 0 && !slices.Contains(r.ClassAllowlist, player.Class) {
+		v.errorf(path, "Rune %d can't be used by a %s", runeID, className(player.Class))
+	}
+	if r.RequiresLevel > playerLevel(player) {
+		v.errorf(path, "Rune %d requires level %d", runeID, r.RequiresLevel)
+	}
+}
+
+func (v *requestValidator) validateTalents(path string, player *proto.Player) {
+	if player.TalentsString == "" {
+		return
+	}
+	trees := strings.Split(player.TalentsString, "-")
+	if len(trees) > 3 {
+		v.errorf(path, "Talents have %d trees, but classes have 3", len(trees))
+		return
+	}
+
+	treeSizes, hasTreeSizes := talentTreeSizes[player.Class]
+	var fields protoreflect.FieldDescriptors
+	if talents, err := protoregistry.GlobalTypes.FindMessageByName(protoreflect.FullName(
+		"proto." + className(player.Class) + "Talents")); err == nil {
+		fields = talents.Descriptor().Fields()
+	}
+
+	var points, offset int
+	for treeIdx, tree := range trees {
+		if hasTreeSizes && len(tree) > treeSizes[treeIdx] {
+			v.errorf(path, "Talent tree %d has %d talents, but a %s only has %d", treeIdx+1, len(tree), className(player.Class), treeSizes[treeIdx])
+			return
+		}
+		for talentIdx, c := range tree {
+			if c < '0' || c > '5' {
+				v.errorf(path, "Invalid talent rank '%c' in tree %d", c, treeIdx+1)
+				return
+			}
+			rank := int(c - '0')
+			if hasTreeSizes && fields != nil {
+				fd := fields.ByNumber(protowire.Number(offset + talentIdx + 1))
+				if fd != nil && fd.Kind() == protoreflect.BoolKind && rank > 1 {
+					v.errorf(path, "Talent %d of tree %d has rank %d, but only has 1 rank", talentIdx+1, treeIdx+1, rank)
+				}
+			}
+			points += rank
+		}
+		if hasTreeSizes {
+			offset += treeSizes[treeIdx]
+		}
+	}
+
+	if maxPoints := max(int(playerLevel(player))-9, 0); points > maxPoints {
+		v.errorf(path, "Talents use %d points, but a level %d character only has %d", points, playerLevel(player), maxPoints)
+	}
+}
+
+// Profiles which would be ignored, in full or for some targets, are reported as well.
+func (v *requestValidator) validateDebuffProfiles(path string, debuffs *proto.Debuffs, numTargets int) {
+	names := make([]string, 0, len(debuffs.Profiles))
+	for name := range debuffs.Profiles {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	msg := debuffs.ProtoReflect()
+	for _, name := range names {
+		profilePath := fmt.Sprintf("%s[\"%s\"]", path, name)
+		field := msg.Descriptor().Fields().ByName(protoreflect.Name(name))
+		if field == nil || field.IsMap() {
+			v.errorf(profilePath, "No debuff named '%s'", name)
+			continue
+		}
+		if slices.Contains(debuffsWithoutProfiles, name) {
+			v.errorf(profilePath, "Debuff '%s' doesn't support profiles", name)
+			continue
+		}
+		// Improved Faerie Fire also applies the regular Faerie Fire.
+		if !msg.Has(field) && !(name == "faerie_fire" && debuffs.ImprovedFaerieFire) {
+			v.warnf(profilePath, "Debuff '%s' is not enabled, so its profile is ignored", name)
+		}
+		if numTargets > 1 && slices.Contains(firstTargetOnlyDebuffs, name) {
+			v.warnf(profilePath, "Debuff '%s' is only applied to the first target, so its profile doesn't apply to the other targets", name)
+		}
+	}
+}
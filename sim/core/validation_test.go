@@ -0,0 +1,95 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+func TestValidateRaidSimRequest(t *testing.T) {
+	RegisterTalentTreeSizes(proto.Class_ClassWarrior, [3]int{18, 17, 17})
+
+	const (
+		itemGreatsword = 990001
+		itemBuckler    = 990002
+		itemRing       = 990003
+		itemHelm       = 990004
+		runeChest      = 990005
+	)
+	items := make([]*proto.ItemSpec, proto.ItemSlot_ItemSlotRanged+1)
+	for i := range items {
+		items[i] = &proto.ItemSpec{}
+	}
+	items[proto.ItemSlot_ItemSlotMainHand] = &proto.ItemSpec{Id: itemGreatsword}
+	items[proto.ItemSlot_ItemSlotOffHand] = &proto.ItemSpec{Id: itemBuckler}
+	items[proto.ItemSlot_ItemSlotFinger1] = &proto.ItemSpec{Id: itemRing}
+	items[proto.ItemSlot_ItemSlotFinger2] = &proto.ItemSpec{Id: itemRing}
+	items[proto.ItemSlot_ItemSlotChest] = &proto.ItemSpec{Id: itemHelm, Rune: runeChest}
+	items[proto.ItemSlot_ItemSlotLegs] = &proto.ItemSpec{Rune: runeChest}
+
+	rsr := &proto.RaidSimRequest{
+		Raid: SinglePlayerRaidProto(&proto.Player{
+			Class:         proto.Class_ClassWarrior,
+			Level:         40,
+			Equipment:     &proto.EquipmentSpec{Items: items},
+			TalentsString: "00000005-3-",
+			Spec:          &proto.Player_Warrior{Warrior: &proto.Warrior{}},
+			Database: &proto.SimDatabase{
+				Items: []*proto.SimItem{
+					{Id: itemGreatsword, Name: "Greatsword", Type: proto.ItemType_ItemTypeWeapon, HandType: proto.HandType_HandTypeTwoHand, RequiresLevel: 50},
+					{Id: itemBuckler, Name: "Buckler", Type: proto.ItemType_ItemTypeWeapon, HandType: proto.HandType_HandTypeOffHand, ClassAllowlist: []proto.Class{proto.Class_ClassPaladin}},
+					{Id: itemRing, Name: "Ring", Type: proto.ItemType_ItemTypeFinger, Unique: true},
+					{Id: itemHelm, Name: "Helm", Type: proto.ItemType_ItemTypeHead},
+				},
+				Runes: []*proto.SimRune{{Id: runeChest, Type: proto.ItemType_ItemTypeChest}},
+			},
+		}, nil, nil, nil),
+	}
+
+	issues := ValidateRaidSimRequest(rsr)
+	const items0 = "raid.parties[0].players[0].equipment.items"
+	expected := []struct {
+		path    string
+		message string
+	}{
+		{items0 + "[4].id", "Helm (990004) can't be equipped in the Chest slot"},
+		{items0 + "[8].rune", "Rune 990005 is engraved on the empty Legs slot"},
+		{items0 + "[14].id", "Greatsword (990001) requires level 50"},
+		{items0 + "[15].id", "Buckler (990002) can't be used by a Warrior"},
+		{items0 + "[15]", "Buckler (990002) can't be equipped with the two-handed Greatsword (990001)"},
+		{items0 + "[11]", "Ring (990003) is unique-equipped, but is also in the Finger1 slot"},
+		{"raid.parties[0].players[0].talentsString", "Talent 8 of tree 1 has rank 5, but only has 1 rank"},
+	}
+	if len(issues) != len(expected) {
+		t.Fatalf("Expected %d issues, got %v", len(expected), issues)
+	}
+	for i, issue := range issues {
+		if issue.Path != expected[i].path || issue.Message != expected[i].message {
+			t.Errorf("Expected issue %s: %s, got %s: %s", expected[i].path, expected[i].message, issue.Path, issue.Message)
+		}
+	}
+	if !HasValidationErrors(issues) {
+		t.Fatalf("Expected validation errors")
+	}
+	if _, ok := ItemsByID[itemGreatsword]; ok {
+		t.Fatalf("Expected validation to leave the global database unchanged")
+	}
+
+	// Invalid requests are only rejected when asked to, before anything is simmed.
+	rsr.SimOptions = &proto.SimOptions{Iterations: 1, RejectInvalid: true}
+	if result := RunRaidSim(rsr); result.Error == nil || len(result.Error.Issues) != len(expected) {
+		t.Fatalf("Expected the request to be rejected, got %v", result.Error)
+	}
+
+	rsr.Raid.Parties[0].Players[0].TalentsString = "05-3-"
+	for _, issue := range ValidateRaidSimRequest(rsr) {
+		if issue.Path == "raid.parties[0].players[0].talentsString" {
+			t.Fatalf("Expected valid talents, got %v", issue)
+		}
+	}
+
+	rsr.Raid.Parties[0].Players[0].TalentsString = "55555555555555555-3-"
+	if issues := ValidateRaidSimRequest(rsr); issues[len(issues)-1].Message != "Talents use 88 points, but a level 40 character only has 31" {
+		t.Fatalf("Expected too many talent points, got %v", issues[len(issues)-1])
+	}
+}
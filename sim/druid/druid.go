@@ -16,6 +16,10 @@ const (
 
 var TalentTreeSizes = [3]int{16, 16, 15}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassDruid, TalentTreeSizes)
+}
+
 const (
 	SpellCode_DruidNone int32 = iota
 
@@ -11,6 +11,10 @@ import (
 
 var TalentTreeSizes = [3]int{16, 14, 16}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassHunter, TalentTreeSizes)
+}
+
 const (
 	SpellFlagShot   = core.SpellFlagAgentReserved1
 	SpellFlagStrike = core.SpellFlagAgentReserved2
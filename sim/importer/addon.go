@@ -0,0 +1,76 @@
+package importer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+type addonExport struct {
+	Class       string `json:"class"`
+	Race        string `json:"race"`
+	Level       int32  `json:"level"`
+	Talents     string `json:"talents"`
+	Professions []struct {
+		Name  string `json:"name"`
+		Level int32  `json:"level"`
+	} `json:"professions"`
+	Gear struct {
+		Items []json.RawMessage `json:"items"`
+	} `json:"gear"`
+}
+
+// Parses the export of the WowSimsExporter in-game addon into a player without spec.
+func ParseAddonExport(data []byte) (*proto.Player, error) {
+	var export addonExport
+	if err := json.Unmarshal(data, &export); err != nil {
+		return nil, fmt.Errorf("cannot parse addon export: %w", err)
+	}
+
+	class, err := parseClass(export.Class)
+	if err != nil {
+		return nil, err
+	}
+	race, err := parseRace(export.Race)
+	if err != nil {
+		return nil, err
+	}
+
+	var professions []proto.Profession
+	for _, profData := range export.Professions {
+		profession, err := parseProfession(profData.Name)
+		if err != nil {
+			return nil, err
+		}
+		professions = append(professions, profession)
+	}
+	if len(professions) > 2 {
+		return nil, errors.New("addon export has more than 2 professions")
+	}
+	professions = append(professions, proto.Profession_ProfessionUnknown, proto.Profession_ProfessionUnknown)
+
+	// Empty slots are exported as null, keep them so the items stay in their slots.
+	equipment := &proto.EquipmentSpec{}
+	for i, itemJson := range export.Gear.Items {
+		item := &proto.ItemSpec{}
+		if string(itemJson) != "null" {
+			if err := (protojson.UnmarshalOptions{DiscardUnknown: true}).Unmarshal(itemJson, item); err != nil {
+				return nil, fmt.Errorf("cannot parse item %d of addon export: %w", i, err)
+			}
+		}
+		equipment.Items = append(equipment.Items, item)
+	}
+
+	return &proto.Player{
+		Class:         class,
+		Race:          race,
+		Level:         export.Level,
+		TalentsString: export.Talents,
+		Profession1:   professions[0],
+		Profession2:   professions[1],
+		Equipment:     equipment,
+	}, nil
+}
@@ -0,0 +1,232 @@
+package importer
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	goproto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+const DefaultIterations = 3000
+
+// Spec used for imported characters of each class, when no spec is given.
+var DefaultSpecs = map[proto.Class]string{
+	proto.Class_ClassDruid:   "feral_druid",
+	proto.Class_ClassHunter:  "hunter",
+	proto.Class_ClassMage:    "mage",
+	proto.Class_ClassPaladin: "retribution_paladin",
+	proto.Class_ClassPriest:  "shadow_priest",
+	proto.Class_ClassRogue:   "rogue",
+	proto.Class_ClassShaman:  "enhancement_shaman",
+	proto.Class_ClassWarlock: "warlock",
+	proto.Class_ClassWarrior: "warrior",
+}
+
+var specOneof = (&proto.Player{}).ProtoReflect().Descriptor().Oneofs().ByName("spec")
+
+// Returns the spec of the player as the name of its spec field, e.g. 'balance_druid'.
+// This is also the directory of the spec's sim UI.
+func SpecName(player *proto.Player) string {
+	fd := player.ProtoReflect().WhichOneof(specOneof)
+	if fd == nil {
+		return ""
+	}
+	return string(fd.Name())
+}
+
+// Sets the spec of the player by name, with default spec options.
+func SetSpec(player *proto.Player, specName string) error {
+	fd := specOneof.Fields().ByName(protoreflect.Name(specName))
+	if fd == nil {
+		return fmt.Errorf("unknown spec %q", specName)
+	}
+
+	spec := player.ProtoReflect().NewField(fd).Message()
+	// Agents access their options directly, so they must not be nil.
+	if options := fd.Message().Fields().ByName("options"); options != nil {
+		spec.Set(options, spec.NewField(options))
+	}
+	player.ProtoReflect().Set(fd, protoreflect.ValueOfMessage(spec))
+	return nil
+}
+
+// Options for the defaults of imported characters.
+type RequestOptions struct {
+	// Spec field name, defaults to DefaultSpecs of the player's class.
+	Spec string
+	// Directory of the sim UIs, for loading the default APL. No APL is loaded if empty.
+	UIDir string
+	// Overrides the default APL.
+	APLFile string
+	// Fight length in seconds, defaults to core.LongDuration.
+	Duration   float64
+	Iterations int32
+}
+
+// Builds a ready-to-run request for an imported player, using the spec's default APL and
+// full buffs and debuffs for the phase of the player's level.
+func NewRaidSimRequest(player *proto.Player, options RequestOptions) (*proto.RaidSimRequest, error) {
+	player = goproto.Clone(player).(*proto.Player)
+	if player.Level == 0 {
+		player.Level = 60
+	}
+	if player.Name == "" {
+		player.Name = "Player"
+	}
+
+	specName := options.Spec
+	if specName == "" {
+		specName = DefaultSpecs[player.Class]
+	}
+	if err := SetSpec(player, specName); err != nil {
+		return nil, err
+	}
+
+	aplFile := options.APLFile
+	if aplFile == "" && options.UIDir != "" {
+		var err error
+		if aplFile, err = DefaultAPLFile(options.UIDir, specName, player.Level); err != nil {
+			return nil, err
+		}
+	}
+	if aplFile != "" {
+		data, err := os.ReadFile(aplFile)
+		if err != nil {
+			return nil, fmt.Errorf("cannot read apl: %w", err)
+		}
+		player.Rotation = core.APLRotationFromJsonString(string(data))
+	}
+
+	buffs := buffsForLevel(player.Level)
+	if player.Buffs == nil {
+		player.Buffs = goproto.Clone(buffs.Player).(*proto.IndividualBuffs)
+	}
+	if player.Consumes == nil {
+		player.Consumes = &proto.Consumes{}
+	}
+
+	encounter := core.MakeSingleTargetEncounter(player.Level, 5)
+	if options.Duration > 0 {
+		encounter.Duration = options.Duration
+	}
+
+	return &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{
+				Players: []*proto.Player{player},
+				Buffs:   goproto.Clone(buffs.Party).(*proto.PartyBuffs),
+			}},
+			Buffs:   goproto.Clone(buffs.Raid).(*proto.RaidBuffs),
+			Debuffs: goproto.Clone(buffs.Debuffs).(*proto.Debuffs),
+		},
+		Encounter: encounter,
+		SimOptions: &proto.SimOptions{
+			Iterations: iterationsOrDefault(options.Iterations),
+		},
+	}, nil
+}
+
+func buffsForLevel(level int32) core.BuffsCombo {
+	switch {
+	case level <= 25:
+		return core.FullBuffsPhase1
+	case level <= 40:
+		return core.FullBuffsPhase2
+	case level <= 50:
+		return core.FullBuffsPhase3
+	default:
+		return core.FullBuffsPhase5
+	}
+}
+
+func phaseForLevel(level int32) int {
+	switch {
+	case level <= 25:
+		return 1
+	case level <= 40:
+		return 2
+	case level <= 50:
+		return 3
+	default:
+		return 5
+	}
+}
+
+var aplPhaseRegex = regexp.MustCompile(`^(?:phase_|p)(\d)`)
+
+// Returns the APL of the spec's sim UI for the latest phase up to the phase of the level.
+// Among several APLs of the same phase, the last one by name is used.
+func DefaultAPLFile(uiDir string, specName string, level int32) (string, error) {
+	files, err := filepath.Glob(filepath.Join(uiDir, specName, "apls", "*.apl.json"))
+	if err != nil || len(files) == 0 {
+		return "", fmt.Errorf("no apls found for spec %q in %s", specName, uiDir)
+	}
+
+	aplPhase := func(file string) int {
+		if match := aplPhaseRegex.FindStringSubmatch(filepath.Base(file)); match != nil {
+			phase, _ := strconv.Atoi(match[1])
+			return phase
+		}
+		return 0
+	}
+	maxPhase := phaseForLevel(level)
+	files = slices.DeleteFunc(files, func(file string) bool { return aplPhase(file) > maxPhase })
+	if len(files) == 0 {
+		return "", fmt.Errorf("no apls found for spec %q up to phase %d", specName, maxPhase)
+	}
+
+	slices.SortFunc(files, func(a, b string) int {
+		if pa, pb := aplPhase(a), aplPhase(b); pa != pb {
+			return pa - pb
+		}
+		return strings.Compare(a, b)
+	})
+	return files[len(files)-1], nil
+}
+
+// Parses names like 'Night Elf' or 'NIGHTELF' into the enum value named e.g. 'RaceNightElf'.
+func parseEnumName(enum protoreflect.EnumDescriptor, prefix string, name string) protoreflect.EnumNumber {
+	normalize := func(s string) string {
+		return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s))
+	}
+	normalized := normalize(name)
+	values := enum.Values()
+	for i := 0; i < values.Len(); i++ {
+		if value := values.Get(i); normalize(strings.TrimPrefix(string(value.Name()), prefix)) == normalized {
+			return value.Number()
+		}
+	}
+	return 0
+}
+
+func parseClass(name string) (proto.Class, error) {
+	class := proto.Class(parseEnumName(proto.Class(0).Descriptor(), "Class", name))
+	if class == proto.Class_ClassUnknown {
+		return class, fmt.Errorf("could not parse class %q", name)
+	}
+	return class, nil
+}
+
+func parseRace(name string) (proto.Race, error) {
+	race := proto.Race(parseEnumName(proto.Race(0).Descriptor(), "Race", name))
+	if race == proto.Race_RaceUnknown {
+		return race, fmt.Errorf("could not parse race %q", name)
+	}
+	return race, nil
+}
+
+func parseProfession(name string) (proto.Profession, error) {
+	profession := proto.Profession(parseEnumName(proto.Profession(0).Descriptor(), "", name))
+	if profession == proto.Profession_ProfessionUnknown {
+		return profession, fmt.Errorf("could not parse profession %q", name)
+	}
+	return profession, nil
+}
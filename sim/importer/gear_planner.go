@@ -0,0 +1,133 @@
+package importer
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core/proto"
+)
+
+var gearPlannerRegex = regexp.MustCompile(`wowhead\.com/classic/gear-planner/([a-z\-]+)/([a-z\-]+)/([a-zA-Z0-9_\-]+)`)
+
+// Wowhead gear planner slot IDs. Other slots, like shirt and tabard, are ignored.
+var gearPlannerSlots = map[byte]proto.ItemSlot{
+	1:  proto.ItemSlot_ItemSlotHead,
+	2:  proto.ItemSlot_ItemSlotNeck,
+	3:  proto.ItemSlot_ItemSlotShoulder,
+	15: proto.ItemSlot_ItemSlotBack,
+	5:  proto.ItemSlot_ItemSlotChest,
+	9:  proto.ItemSlot_ItemSlotWrist,
+	10: proto.ItemSlot_ItemSlotHands,
+	6:  proto.ItemSlot_ItemSlotWaist,
+	7:  proto.ItemSlot_ItemSlotLegs,
+	8:  proto.ItemSlot_ItemSlotFeet,
+	11: proto.ItemSlot_ItemSlotFinger1,
+	12: proto.ItemSlot_ItemSlotFinger2,
+	13: proto.ItemSlot_ItemSlotTrinket1,
+	14: proto.ItemSlot_ItemSlotTrinket2,
+	16: proto.ItemSlot_ItemSlotMainHand,
+	17: proto.ItemSlot_ItemSlotOffHand,
+	18: proto.ItemSlot_ItemSlotRanged,
+}
+
+// Maps the spell ID of an enchant to its effect ID, or 0 if unknown.
+type EnchantSpellLookup func(spellID int32) int32
+
+// Builds an EnchantSpellLookup from the enchants of the database.
+func NewEnchantSpellLookup(db *proto.UIDatabase) EnchantSpellLookup {
+	effectIDs := make(map[int32]int32, len(db.Enchants))
+	for _, enchant := range db.Enchants {
+		if enchant.SpellId != 0 {
+			effectIDs[enchant.SpellId] = enchant.EffectId
+		}
+	}
+	return func(spellID int32) int32 {
+		return effectIDs[spellID]
+	}
+}
+
+// Parses a wowhead classic gear planner link into a player without spec.
+// Gear planner links only contain item and enchant IDs, so runes have to be set separately.
+func ParseGearPlannerLink(link string, lookupEnchant EnchantSpellLookup) (*proto.Player, error) {
+	match := gearPlannerRegex.FindStringSubmatch(link)
+	if match == nil {
+		return nil, errors.New("invalid wowhead gear planner link")
+	}
+
+	class, err := parseClass(match[1])
+	if err != nil {
+		return nil, err
+	}
+	race, err := parseRace(match[2])
+	if err != nil {
+		return nil, err
+	}
+
+	base64Data := strings.NewReplacer("_", "/", "-", "+").Replace(match[3])
+	data, err := base64.RawStdEncoding.DecodeString(strings.TrimRight(base64Data, "="))
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode gear planner data: %w", err)
+	}
+
+	// Binary schema
+	// Byte 00: ??
+	// Byte 01: ?? Seems related to aesthetics (e.g. body type)
+	// Byte 02: 8-bit Player Level
+	// Byte 03: 8-bit length of talents bytes
+	// Next N Bytes: Talents in hex string format, with 'f' instead of '-' between trees.
+	if len(data) < 4 || len(data) < 4+int(data[3]) {
+		return nil, errors.New("gear planner data is too short")
+	}
+	numTalentBytes := int(data[3])
+	talentTrees := strings.Split(hex.EncodeToString(data[4:4+numTalentBytes]), "f")
+	player := &proto.Player{
+		Class:         class,
+		Race:          race,
+		Level:         int32(data[2]),
+		TalentsString: strings.Join(talentTrees[:min(len(talentTrees), 3)], "-"),
+		Equipment:     &proto.EquipmentSpec{Items: make([]*proto.ItemSpec, len(gearPlannerSlots))},
+	}
+	for i := range player.Equipment.Items {
+		player.Equipment.Items[i] = &proto.ItemSpec{}
+	}
+
+	// Binary schema for each item:
+	// 8-bit slotNumber, high bit = is enchanted
+	// 8-bit lower 5 bits are the upper bits of the item id
+	// 16-bit item id
+	// if enchant bit is set:
+	//   24-bit enchant spell id
+	gearBytes := data[4+numTalentBytes:]
+	for cur := 0; cur < len(gearBytes); {
+		if cur+4 > len(gearBytes) {
+			return nil, errors.New("truncated gear planner item")
+		}
+		slotID := gearBytes[cur] & 0b00111111
+		isEnchanted := gearBytes[cur]&0b10000000 != 0
+		item := &proto.ItemSpec{
+			Id: int32(gearBytes[cur+1]&0b00011111)<<16 | int32(gearBytes[cur+2])<<8 | int32(gearBytes[cur+3]),
+		}
+		cur += 4
+
+		if isEnchanted {
+			if cur+3 > len(gearBytes) {
+				return nil, errors.New("truncated gear planner enchant")
+			}
+			enchantSpellID := int32(gearBytes[cur])<<16 | int32(gearBytes[cur+1])<<8 | int32(gearBytes[cur+2])
+			if lookupEnchant != nil {
+				item.Enchant = lookupEnchant(enchantSpellID)
+			}
+			cur += 3
+		}
+
+		if slot, ok := gearPlannerSlots[slotID]; ok {
+			player.Equipment.Items[slot] = item
+		}
+	}
+
+	return player, nil
+}
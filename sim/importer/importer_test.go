@@ -0,0 +1,92 @@
+package importer
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	goproto "google.golang.org/protobuf/proto"
+)
+
+func TestParseGearPlannerLink(t *testing.T) {
+	data := []byte{0, 0, 60, 2, 0x12, 0xf3}
+	// Enchanted head 12345 with enchant spell 70000, then neck 23456 and a shirt.
+	data = append(data, 0x81, 0x00, 0x30, 0x39, 0x01, 0x11, 0x70)
+	data = append(data, 0x02, 0x00, 0x5b, 0xa0)
+	data = append(data, 0x04, 0x00, 0x00, 0x01)
+	encoded := strings.NewReplacer("/", "_", "+", "-").Replace(base64.RawStdEncoding.EncodeToString(data))
+
+	player, err := ParseGearPlannerLink("https://www.wowhead.com/classic/gear-planner/warrior/night-elf/"+encoded, func(spellID int32) int32 {
+		if spellID != 70000 {
+			t.Fatalf("Unexpected enchant spell %d", spellID)
+		}
+		return 1
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if player.Class != proto.Class_ClassWarrior || player.Race != proto.Race_RaceNightElf || player.Level != 60 || player.TalentsString != "12-3" {
+		t.Fatalf("Unexpected character %v", player)
+	}
+	items := player.Equipment.Items
+	if len(items) != 17 || items[0].Id != 12345 || items[0].Enchant != 1 || items[1].Id != 23456 || items[2].Id != 0 {
+		t.Fatalf("Unexpected items %v", items)
+	}
+}
+
+func TestParseAddonExport(t *testing.T) {
+	player, err := ParseAddonExport([]byte(`{
+		"version": "1",
+		"class": "priest",
+		"race": "Night Elf",
+		"level": 50,
+		"talents": "05-0-15",
+		"professions": [{"name": "Enchanting", "level": 300}],
+		"gear": {"version": "1", "items": [{"id": 1, "enchant": 2, "rune": 3}, null, {"id": 4, "gems": []}]}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if player.Class != proto.Class_ClassPriest || player.Race != proto.Race_RaceNightElf || player.Level != 50 ||
+		player.Profession1 != proto.Profession_Enchanting || player.Profession2 != proto.Profession_ProfessionUnknown {
+		t.Fatalf("Unexpected character %v", player)
+	}
+	items := player.Equipment.Items
+	if len(items) != 3 || items[0].Rune != 3 || items[1].Id != 0 || items[2].Id != 4 {
+		t.Fatalf("Expected empty slots to be kept, got %v", items)
+	}
+
+	if _, err := ParseAddonExport([]byte(`{"class": "deathknight", "race": "human"}`)); err == nil {
+		t.Fatal("Expected an error for an unknown class")
+	}
+}
+
+func TestSettingsLinkRoundTrip(t *testing.T) {
+	player := &proto.Player{Class: proto.Class_ClassMage, Race: proto.Race_RaceGnome, Level: 40}
+	request, err := NewRaidSimRequest(player, RequestOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if SpecName(request.Raid.Parties[0].Players[0]) != "mage" || request.Raid.Parties[0].Players[0].GetMage().Options == nil {
+		t.Fatalf("Expected the default spec with options, got %v", request.Raid.Parties[0].Players[0])
+	}
+
+	link, err := EncodeSettingsLink(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(link, SimBaseURL+"mage/#") {
+		t.Fatalf("Unexpected link %s", link)
+	}
+
+	settings, err := DecodeSettingsLink(link)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded := IndividualSettingsToRequest(settings.(*proto.IndividualSimSettings)); !goproto.Equal(decoded, request) {
+		t.Fatalf("Expected %v, got %v", request, decoded)
+	}
+}
@@ -0,0 +1,148 @@
+// Package importer converts external character formats, like wowsims share links, the in-game
+// export addon and wowhead gear planner links, into ready-to-run sim requests.
+package importer
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	goproto "google.golang.org/protobuf/proto"
+)
+
+// Base URL of the individual sims, followed by the spec, e.g. 'warrior/'.
+const SimBaseURL = "https://wowsims.github.io/classic/"
+
+var ErrInvalidLink = errors.New("invalid wowsims export link")
+
+// Decodes a wowsims share link into IndividualSimSettings, or RaidSimSettings for raid sim links.
+func DecodeSettingsLink(link string) (goproto.Message, error) {
+	parts := strings.Split(link, "#")
+	switch {
+	case len(parts) != 2:
+		return nil, ErrInvalidLink
+	case parts[1] == "":
+		return nil, ErrInvalidLink
+	}
+
+	raw, err := base64.StdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("cannot decode proto from link: %w", err)
+	}
+
+	r, err := zlib.NewReader(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("cannot create zlib reader: %w", err)
+	}
+	defer r.Close()
+
+	var buf bytes.Buffer
+	if _, err := buf.ReadFrom(r); err != nil {
+		return nil, fmt.Errorf("reading zlib data failed: %w", err)
+	}
+
+	var settings goproto.Message
+	if strings.Contains(link, "/raid/") {
+		settings = &proto.RaidSimSettings{}
+	} else {
+		settings = &proto.IndividualSimSettings{}
+	}
+
+	if err := goproto.Unmarshal(buf.Bytes(), settings); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal raw proto: %w", err)
+	}
+	return settings, nil
+}
+
+// Encodes the settings in the format used after the '#' of share links.
+func EncodeSettings(settings goproto.Message) (string, error) {
+	data, err := goproto.Marshal(settings)
+	if err != nil {
+		return "", err
+	}
+
+	var buffer bytes.Buffer
+	writer := zlib.NewWriter(&buffer)
+	writer.Write(data)
+	writer.Close()
+	return base64.StdEncoding.EncodeToString(buffer.Bytes()), nil
+}
+
+// Encodes the first player of the request as a share link for its individual sim.
+func EncodeSettingsLink(request *proto.RaidSimRequest) (string, error) {
+	settings, err := RequestToIndividualSettings(request)
+	if err != nil {
+		return "", err
+	}
+	data, err := EncodeSettings(settings)
+	if err != nil {
+		return "", err
+	}
+	return SimBaseURL + SpecName(settings.Player) + "/#" + data, nil
+}
+
+// Converts a single player request to the settings of its individual sim.
+func RequestToIndividualSettings(request *proto.RaidSimRequest) (*proto.IndividualSimSettings, error) {
+	raid := request.GetRaid()
+	if len(raid.GetParties()) == 0 || len(raid.Parties[0].Players) == 0 || raid.Parties[0].Players[0] == nil {
+		return nil, errors.New("request has no player")
+	}
+
+	return &proto.IndividualSimSettings{
+		Settings: &proto.SimSettings{
+			Iterations: request.GetSimOptions().GetIterations(),
+		},
+		RaidBuffs:     raid.Buffs,
+		Debuffs:       raid.Debuffs,
+		Tanks:         raid.Tanks,
+		PartyBuffs:    raid.Parties[0].Buffs,
+		Player:        raid.Parties[0].Players[0],
+		Encounter:     request.Encounter,
+		TargetDummies: raid.TargetDummies,
+	}, nil
+}
+
+// Converts the settings of an individual sim into a ready-to-run request.
+func IndividualSettingsToRequest(settings *proto.IndividualSimSettings) *proto.RaidSimRequest {
+	return &proto.RaidSimRequest{
+		Raid: &proto.Raid{
+			Parties: []*proto.Party{{
+				Players: []*proto.Player{settings.Player},
+				Buffs:   settings.PartyBuffs,
+			}},
+			Buffs:         settings.RaidBuffs,
+			Debuffs:       settings.Debuffs,
+			Tanks:         settings.Tanks,
+			TargetDummies: settings.TargetDummies,
+		},
+		Encounter: settings.Encounter,
+		SimOptions: &proto.SimOptions{
+			Iterations: iterationsOrDefault(settings.GetSettings().GetIterations()),
+			RandomSeed: settings.GetSettings().GetFixedRngSeed(),
+		},
+	}
+}
+
+// Converts the settings of a raid sim into a ready-to-run request.
+func RaidSettingsToRequest(settings *proto.RaidSimSettings) *proto.RaidSimRequest {
+	return &proto.RaidSimRequest{
+		Raid:      settings.Raid,
+		Encounter: settings.Encounter,
+		SimOptions: &proto.SimOptions{
+			Iterations: iterationsOrDefault(settings.GetSettings().GetIterations()),
+			RandomSeed: settings.GetSettings().GetFixedRngSeed(),
+		},
+	}
+}
+
+// Returns the given iterations, or the default if they are not set.
+func iterationsOrDefault(iterations int32) int32 {
+	if iterations <= 0 {
+		return DefaultIterations
+	}
+	return iterations
+}
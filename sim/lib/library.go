@@ -1,23 +1,36 @@
 package main
 
-// #include <stdlib.h>
+/*
+#include <stdlib.h>
+#include <stdint.h>
+
+// See wowsimclassic.h.
+typedef int32_t (*wowsim_progress_callback)(char* progress_json, void* user_data);
+
+static inline int32_t call_progress_callback(wowsim_progress_callback callback, char* progress_json, void* user_data) {
+	return callback(progress_json, user_data);
+}
+*/
 import "C"
 import (
-	"bytes"
-	"compress/zlib"
-	"encoding/base64"
 	"encoding/json"
-	"log"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
 	"unsafe"
 
 	"github.com/wowsims/classic/sim"
 	"github.com/wowsims/classic/sim/core"
 	"github.com/wowsims/classic/sim/core/proto"
 	"github.com/wowsims/classic/sim/core/simsignals"
+	"github.com/wowsims/classic/sim/importer"
 	"google.golang.org/protobuf/encoding/protojson"
 	goproto "google.golang.org/protobuf/proto"
 )
 
+// The exported functions are documented in wowsimclassic.h, which also describes who owns the returned memory.
+
 var _default_rsr = proto.RaidSimRequest{
 	Raid:       &proto.Raid{},
 	Encounter:  &proto.Encounter{},
@@ -28,127 +41,352 @@ var _active_seed int64 = 1
 var _aura_labels = []string{}
 var _target_aura_labels = []string{}
 
-//export runSim
-func runSim(json *C.char) *C.char {
-	input := &proto.RaidSimRequest{}
-	jsonString := C.GoString(json)
-	err := protojson.Unmarshal([]byte(jsonString), input)
-	if err != nil {
-		log.Fatalf("failed to load input json file: %s", err)
-	}
+var _last_error struct {
+	sync.Mutex
+	message string
+}
+
+func init() {
 	sim.RegisterAll()
-	result := core.RunSim(input, nil, simsignals.Signals{})
-	out, err := protojson.Marshal(result)
-	if err != nil {
-		panic(err)
+}
+
+func setLastError(err error) {
+	_last_error.Lock()
+	defer _last_error.Unlock()
+	_last_error.message = err.Error()
+}
+
+//export getLastError
+func getLastError() *C.char {
+	_last_error.Lock()
+	defer _last_error.Unlock()
+	if _last_error.message == "" {
+		return nil
 	}
-	return C.CString(string(out))
+	message := _last_error.message
+	_last_error.message = ""
+	return C.CString(message)
 }
 
-//export computeStats
-func computeStats(json *C.char) *C.char {
-	input := &proto.ComputeStatsRequest{}
-	jsonString := C.GoString(json)
-	err := protojson.Unmarshal([]byte(jsonString), input)
-	if err != nil {
-		log.Fatalf("failed to load input json file: %s", err)
+func panicMessage(err any) string {
+	errStr := ""
+	switch errt := err.(type) {
+	case string:
+		errStr = errt
+	case error:
+		errStr = errt.Error()
 	}
-	sim.RegisterAll()
-	result := core.ComputeStats(input)
+	return errStr + "\nStack Trace:\n" + string(debug.Stack())
+}
+
+// Runs an exported function and returns its result as JSON. Panics and invalid requests are returned as
+// the error of the result instead, so they don't take down the host process. Results without an error
+// field are nil on errors, which returns NULL.
+func runExport(run func() goproto.Message, errorResult func(message string) goproto.Message) *C.char {
+	result := func() (result goproto.Message) {
+		defer func() {
+			if err := recover(); err != nil {
+				result = errorResult(panicMessage(err))
+			}
+		}()
+		return run()
+	}()
+	if result == nil {
+		return nil
+	}
+
 	out, err := protojson.Marshal(result)
 	if err != nil {
-		panic(err)
+		setLastError(err)
+		return nil
 	}
 	return C.CString(string(out))
 }
 
+// Error result of exports whose results have no error field, see getLastError.
+func lastErrorResult(message string) goproto.Message {
+	setLastError(errors.New(message))
+	return nil
+}
+
+func parseRequest(json *C.char, request goproto.Message) error {
+	if json == nil {
+		return errors.New("request is NULL")
+	}
+	if err := protojson.Unmarshal([]byte(C.GoString(json)), request); err != nil {
+		return errors.New("failed to parse request: " + err.Error())
+	}
+	return nil
+}
+
+// Starts an async sim, and calls the callback with each progress update on the calling thread until the sim is
+// done. The sim is aborted if the callback returns non-zero.
+func runWithProgress(requestId *C.char, callback C.wowsim_progress_callback, userData unsafe.Pointer,
+	start func(progress chan *proto.ProgressMetrics, requestId string),
+	finalResult func(progress *proto.ProgressMetrics) (goproto.Message, bool)) (goproto.Message, error) {
+	if requestId == nil {
+		return nil, errors.New("request id is NULL")
+	}
+	id := C.GoString(requestId)
+
+	progress := make(chan *proto.ProgressMetrics, 100)
+	start(progress, id)
+	for metrics := range progress {
+		if result, ok := finalResult(metrics); ok {
+			return result, nil
+		}
+		if callback == nil {
+			continue
+		}
+		out, err := protojson.Marshal(metrics)
+		if err != nil {
+			continue
+		}
+		progressJson := C.CString(string(out))
+		abort := C.call_progress_callback(callback, progressJson, userData) != 0
+		C.free(unsafe.Pointer(progressJson))
+		if abort {
+			simsignals.AbortById(id)
+		}
+	}
+	return nil, errors.New("sim ended without a result")
+}
+
+func raidSimError(message string) goproto.Message {
+	return &proto.RaidSimResult{Error: &proto.ErrorOutcome{Message: message}}
+}
+
+func bulkSimError(message string) goproto.Message {
+	return &proto.BulkSimResult{Error: &proto.ErrorOutcome{Message: message}}
+}
+
+func statWeightsError(message string) goproto.Message {
+	return &proto.StatWeightsResult{Error: &proto.ErrorOutcome{Message: message}}
+}
+
+//export runSim
+func runSim(json *C.char) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.RaidSimRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return raidSimError(err.Error())
+		}
+		return core.RunRaidSim(input)
+	}, raidSimError)
+}
+
+//export runSimWithProgress
+func runSimWithProgress(json *C.char, requestId *C.char, callback C.wowsim_progress_callback, userData unsafe.Pointer) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.RaidSimRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return raidSimError(err.Error())
+		}
+		result, err := runWithProgress(requestId, callback, userData, func(progress chan *proto.ProgressMetrics, id string) {
+			core.RunRaidSimConcurrentAsync(input, progress, id)
+		}, func(progress *proto.ProgressMetrics) (goproto.Message, bool) {
+			return progress.FinalRaidResult, progress.FinalRaidResult != nil
+		})
+		if err != nil {
+			return raidSimError(err.Error())
+		}
+		return result
+	}, raidSimError)
+}
+
+//export bulkSim
+func bulkSim(json *C.char, requestId *C.char, callback C.wowsim_progress_callback, userData unsafe.Pointer) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.BulkSimRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return bulkSimError(err.Error())
+		}
+		result, err := runWithProgress(requestId, callback, userData, func(progress chan *proto.ProgressMetrics, id string) {
+			core.RunBulkSimAsync(input, progress, id)
+		}, func(progress *proto.ProgressMetrics) (goproto.Message, bool) {
+			return progress.FinalBulkResult, progress.FinalBulkResult != nil
+		})
+		if err != nil {
+			return bulkSimError(err.Error())
+		}
+		return result
+	}, bulkSimError)
+}
+
+//export statWeights
+func statWeights(json *C.char, requestId *C.char, callback C.wowsim_progress_callback, userData unsafe.Pointer) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.StatWeightsRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return statWeightsError(err.Error())
+		}
+		result, err := runWithProgress(requestId, callback, userData, func(progress chan *proto.ProgressMetrics, id string) {
+			core.StatWeightsAsync(input, progress, id)
+		}, func(progress *proto.ProgressMetrics) (goproto.Message, bool) {
+			return progress.FinalWeightResult, progress.FinalWeightResult != nil
+		})
+		if err != nil {
+			return statWeightsError(err.Error())
+		}
+		return result
+	}, statWeightsError)
+}
+
+//export statWeightRequests
+func statWeightRequests(json *C.char) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.StatWeightsRequest{}
+		if err := parseRequest(json, input); err != nil {
+			setLastError(err)
+			return nil
+		}
+		return core.StatWeightRequests(input)
+	}, lastErrorResult)
+}
+
+//export statWeightCompute
+func statWeightCompute(json *C.char) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.StatWeightsCalcRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return statWeightsError(err.Error())
+		}
+		return core.StatWeightCompute(input)
+	}, statWeightsError)
+}
+
+//export raidSimRequestSplit
+func raidSimRequestSplit(json *C.char) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.RaidSimRequestSplitRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return &proto.RaidSimRequestSplitResult{ErrorResult: err.Error()}
+		}
+		return core.SplitSimRequestForConcurrency(input.Request, input.SplitCount)
+	}, func(message string) goproto.Message {
+		return &proto.RaidSimRequestSplitResult{ErrorResult: message}
+	})
+}
+
+//export raidSimResultCombination
+func raidSimResultCombination(json *C.char) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.RaidSimResultCombinationRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return raidSimError(err.Error())
+		}
+		return core.CombineConcurrentSimResults(input.Results, false)
+	}, raidSimError)
+}
+
+//export abortById
+func abortById(requestId *C.char) bool {
+	if requestId == nil {
+		return false
+	}
+	return simsignals.AbortById(C.GoString(requestId))
+}
+
+//export computeStats
+func computeStats(json *C.char) *C.char {
+	return runExport(func() goproto.Message {
+		input := &proto.ComputeStatsRequest{}
+		if err := parseRequest(json, input); err != nil {
+			return &proto.ComputeStatsResult{ErrorResult: err.Error()}
+		}
+		return core.ComputeStats(input)
+	}, func(message string) goproto.Message {
+		return &proto.ComputeStatsResult{ErrorResult: message}
+	})
+}
+
 //export encodeSettings
 func encodeSettings(json *C.char) *C.char {
 	input := &proto.RaidSimRequest{}
-	jsonString := C.GoString(json)
-	err := protojson.Unmarshal([]byte(jsonString), input)
-	if err != nil {
-		log.Fatalf("failed to load input json file: %s", err)
+	if err := parseRequest(json, input); err != nil {
+		setLastError(err)
+		return nil
 	}
-	settings := &proto.IndividualSimSettings{
-		Settings: &proto.SimSettings{
-			Iterations: input.SimOptions.Iterations,
-		},
-		RaidBuffs:  input.Raid.Buffs,
-		Debuffs:    input.Raid.Debuffs,
-		Tanks:      input.Raid.Tanks,
-		PartyBuffs: input.Raid.Parties[0].Buffs,
-		Player:     input.Raid.Parties[0].Players[0],
-		Encounter:  input.Encounter,
+	settings, err := importer.RequestToIndividualSettings(input)
+	if err != nil {
+		setLastError(errors.New("failed to convert input: " + err.Error()))
+		return nil
 	}
-	var buffer bytes.Buffer
-	data, err := goproto.Marshal(settings)
+	out, err := importer.EncodeSettings(settings)
 	if err != nil {
-		panic(err)
+		setLastError(err)
+		return nil
 	}
-	writer := zlib.NewWriter(&buffer)
-	writer.Write(data)
-	writer.Close()
-	out := base64.StdEncoding.EncodeToString(buffer.Bytes())
 	return C.CString(string(out))
 }
 
 //export getDatabase
 func getDatabase(itemIds *int32, numItems int32, enchantIds *int32, numEnchants int32, gemIds *int32) *C.char {
-	ids := unsafe.Slice(itemIds, numItems)
-	eids := unsafe.Slice(enchantIds, numEnchants)
-	simDB := &proto.SimDatabase{
-		Items:    make([]*proto.SimItem, numItems),
-		Enchants: make([]*proto.SimEnchant, numEnchants),
-	}
-	for i, itemId := range ids {
-		item := core.ItemsByID[itemId]
-		simDB.Items[i] = &proto.SimItem{
-			Id:               item.ID,
-			RequiresLevel:    item.RequiresLevel,
-			ClassAllowlist:   item.ClassAllowlist,
-			Name:             item.Name,
-			Type:             item.Type,
-			ArmorType:        item.ArmorType,
-			WeaponType:       item.WeaponType,
-			HandType:         item.HandType,
-			RangedWeaponType: item.RangedWeaponType,
-			Stats:            item.Stats[:],
-			WeaponDamageMin:  item.WeaponDamageMin,
-			WeaponDamageMax:  item.WeaponDamageMax,
-			WeaponSpeed:      item.SwingSpeed,
-			SetName:          item.SetName,
-			SetId:            item.SetID,
+	return runExport(func() goproto.Message {
+		ids := unsafe.Slice(itemIds, numItems)
+		eids := unsafe.Slice(enchantIds, numEnchants)
+		simDB := &proto.SimDatabase{
+			Items:    make([]*proto.SimItem, numItems),
+			Enchants: make([]*proto.SimEnchant, numEnchants),
 		}
-	}
-	for i, enchantId := range eids {
-		enchant := core.EnchantsByEffectID[enchantId]
-		simDB.Enchants[i] = &proto.SimEnchant{
-			EffectId: enchant.EffectID,
-			Stats:    enchant.Stats[:],
+		for i, itemId := range ids {
+			item, ok := core.ItemsByID[itemId]
+			if !ok {
+				setLastError(fmt.Errorf("no item with id %d", itemId))
+				return nil
+			}
+			simDB.Items[i] = &proto.SimItem{
+				Id:               item.ID,
+				RequiresLevel:    item.RequiresLevel,
+				ClassAllowlist:   item.ClassAllowlist,
+				Name:             item.Name,
+				Type:             item.Type,
+				ArmorType:        item.ArmorType,
+				WeaponType:       item.WeaponType,
+				HandType:         item.HandType,
+				RangedWeaponType: item.RangedWeaponType,
+				Stats:            item.Stats[:],
+				WeaponDamageMin:  item.WeaponDamageMin,
+				WeaponDamageMax:  item.WeaponDamageMax,
+				WeaponSpeed:      item.SwingSpeed,
+				SetName:          item.SetName,
+				SetId:            item.SetID,
+			}
 		}
-	}
-	out, err := protojson.Marshal(simDB)
-	if err != nil {
-		panic(err)
-	}
-	return C.CString(string(out))
+		for i, enchantId := range eids {
+			enchant, ok := core.EnchantsByEffectID[enchantId]
+			if !ok {
+				setLastError(fmt.Errorf("no enchant with effect id %d", enchantId))
+				return nil
+			}
+			simDB.Enchants[i] = &proto.SimEnchant{
+				EffectId: enchant.EffectID,
+				Stats:    enchant.Stats[:],
+			}
+		}
+		return simDB
+	}, lastErrorResult)
 }
 
 //export new
-func new(json *C.char) {
+func new(json *C.char) (ok bool) {
+	defer func() {
+		if err := recover(); err != nil {
+			setLastError(errors.New(panicMessage(err)))
+			ok = false
+		}
+	}()
 	input := &proto.RaidSimRequest{}
-	jsonString := C.GoString(json)
-	err := protojson.Unmarshal([]byte(jsonString), input)
-	if err != nil {
-		log.Fatalf("failed to load input json file: %s", err)
+	if err := parseRequest(json, input); err != nil {
+		setLastError(err)
+		return false
 	}
-	sim.RegisterAll()
 	_active_sim = core.NewSim(input, simsignals.Signals{})
 	_active_sim.Reseed(_active_seed)
 	_active_seed += 1
 	_active_sim.Reset()
 	_active_sim.PrePull()
+	return true
 }
 
 //export trySpell
@@ -162,23 +400,31 @@ func trySpell(act int) bool {
 	target := player.GetCharacter().CurrentTarget
 	casted := false
 
+	// Actions go through Act, so forks of the sim replay them. Forks have their own units, so the
+	// spell and target are looked up in the sim the action runs in.
 	// FIXME : This is a hack to allow Heroic strike to work
 	if spell.ActionID.SpellID == 47450 {
-		aura := player.GetCharacter().GetAura("HS Queue Aura")
-		if aura.IsActive() {
+		if player.GetCharacter().GetAura("HS Queue Aura").IsActive() {
 			return false
 		}
-		aura.Activate(_active_sim)
+		_active_sim.Act(func(sim *core.Simulation) {
+			sim.Raid.Parties[0].Players[0].GetCharacter().GetAura("HS Queue Aura").Activate(sim)
+		})
 		return true
 	}
 	// End of Heroic strike hack
 
-	if spell.CanCast(_active_sim, target) {
-		casted = spell.Cast(_active_sim, target)
+	if !spell.CanCast(_active_sim, target) {
+		return false
+	}
+	_active_sim.Act(func(sim *core.Simulation) {
+		character := sim.Raid.Parties[0].Players[0].GetCharacter()
+		spell := character.Spellbook[act]
+		casted = spell.Cast(sim, character.CurrentTarget)
 		if casted && spell.CurCast.GCD > 0 {
-			_active_sim.NeedsInput = false
+			sim.NeedsInput = false
 		}
-	}
+	})
 	return casted
 }
 
@@ -309,7 +555,13 @@ func getDamageDone() float64 {
 }
 
 //export getSpellMetrics
-func getSpellMetrics() *C.char {
+func getSpellMetrics() (result *C.char) {
+	defer func() {
+		if err := recover(); err != nil {
+			setLastError(errors.New(panicMessage(err)))
+			result = nil
+		}
+	}()
 	all_metrics := make(map[int32][]core.SpellMetrics)
 	player := _active_sim.Raid.Parties[0].Players[0]
 	spellbook := player.GetCharacter().Spellbook
@@ -323,7 +575,8 @@ func getSpellMetrics() *C.char {
 	}
 	out, err := json.Marshal(all_metrics)
 	if err != nil {
-		panic(err)
+		setLastError(err)
+		return nil
 	}
 	return C.CString(string(out))
 }
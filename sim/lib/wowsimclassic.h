@@ -0,0 +1,121 @@
+/*
+ * C interface of the wowsims classic shared library, built with `make locallib`.
+ *
+ * Requests and results are protos in protojson format, see proto/api.proto.
+ *
+ * Memory ownership:
+ *  - Strings passed to the library are owned by the caller, and only read during the call.
+ *  - Strings returned by the library are owned by the caller, and must be freed with FreeCString.
+ *    Functions returning a string may return NULL on errors, see getLastError.
+ *  - Strings passed to a progress callback are owned by the library, and are only valid until the
+ *    callback returns. Copy them to keep them.
+ *
+ * Errors:
+ *  Invalid requests and failed sims are returned as the error field of the result, e.g.
+ *  RaidSimResult.error, and never exit the process. Functions whose result has no error field return
+ *  NULL or false instead, and set the message returned by getLastError.
+ *
+ * Threads:
+ *  The sim functions block until they are done and may be called from several threads at once. Progress
+ *  callbacks are called on the thread which called the sim function. The interactive functions (new, step,
+ *  trySpell, ...) share a single sim and must not be called concurrently.
+ */
+
+#ifndef WOWSIMCLASSIC_H
+#define WOWSIMCLASSIC_H
+
+#include <stddef.h>
+#include <stdint.h>
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+/*
+ * Called with each ProgressMetrics of a sim in protojson format, along with the user data given to the sim
+ * function. Return non-zero to abort the sim, whose result is then returned with an aborted error.
+ */
+typedef int32_t (*wowsim_progress_callback)(char* progress_json, void* user_data);
+
+/* Frees a string returned by the library. */
+void FreeCString(char* s);
+
+/* Returns the message of the last error which returned NULL or false and clears it, or NULL if there is none. */
+char* getLastError(void);
+
+/* Sims a RaidSimRequest on the calling thread. Returns a RaidSimResult. */
+char* runSim(char* json);
+
+/*
+ * Sims a RaidSimRequest on all cores, calling the callback (if not NULL) with its progress. The request id
+ * must be unique among running sims, and can be used to abort the sim with abortById. Returns a RaidSimResult.
+ */
+char* runSimWithProgress(char* json, char* requestId, wowsim_progress_callback callback, void* userData);
+
+/* Sims a BulkSimRequest, like runSimWithProgress. Returns a BulkSimResult. */
+char* bulkSim(char* json, char* requestId, wowsim_progress_callback callback, void* userData);
+
+/* Sims a StatWeightsRequest, like runSimWithProgress. Returns a StatWeightsResult. */
+char* statWeights(char* json, char* requestId, wowsim_progress_callback callback, void* userData);
+
+/*
+ * Returns the StatWeightRequestsData of a StatWeightsRequest, to sim the requests separately, or NULL on errors.
+ * Compute the weights from their results with statWeightCompute.
+ */
+char* statWeightRequests(char* json);
+
+/* Computes stat weights from a StatWeightsCalcRequest. Returns a StatWeightsResult. */
+char* statWeightCompute(char* json);
+
+/* Splits a RaidSimRequestSplitRequest into requests with fewer iterations. Returns a RaidSimRequestSplitResult. */
+char* raidSimRequestSplit(char* json);
+
+/* Combines the results of split requests from a RaidSimResultCombinationRequest. Returns a RaidSimResult. */
+char* raidSimResultCombination(char* json);
+
+/* Aborts the running sim with the request id. Returns false if there is no such sim. */
+uint8_t abortById(char* requestId);
+
+/* Computes the stats of a ComputeStatsRequest. Returns a ComputeStatsResult. */
+char* computeStats(char* json);
+
+/* Returns the settings link of a single player RaidSimRequest, or NULL on errors. */
+char* encodeSettings(char* json);
+
+/* Returns a SimDatabase with the given items and enchants. gemIds is unused. */
+char* getDatabase(int32_t* itemIds, int32_t numItems, int32_t* enchantIds, int32_t numEnchants, int32_t* gemIds);
+
+/*
+ * Interactive sim, for stepping through a fight of the first player of a RaidSimRequest.
+ * new returns false on errors. It can't be declared in C++, where it is a keyword.
+ */
+#ifndef __cplusplus
+uint8_t new(char* json);
+#endif
+uint8_t step(void);
+uint8_t needsInput(void);
+uint8_t trySpell(ptrdiff_t act);
+uint8_t doNothing(void);
+void cleanup(void);
+
+double getRemainingDuration(void);
+double getEnergy(void);
+ptrdiff_t getComboPoints(void);
+ptrdiff_t getUnitCount(void);
+ptrdiff_t getSpellCount(void);
+void getSpells(int32_t* storage, int32_t n);
+void getCooldowns(double* storage, int32_t* spellbookIndices, int32_t n);
+/* Aura labels are NULL terminated arrays. */
+void registerAuras(char** strings);
+void registerTargetAuras(char** strings);
+void getAuras(double* storage, int32_t n);
+void getTargetAuras(double* storage, int32_t n);
+double getDamageDone(void);
+/* Returns the spell metrics of the interactive sim, as JSON by spell id. */
+char* getSpellMetrics(void);
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif /* WOWSIMCLASSIC_H */
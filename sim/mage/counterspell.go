@@ -6,7 +6,7 @@ import (
 	"github.com/wowsims/classic/sim/core"
 )
 
-// This exists purely so that it can be used to extend the arcane buff from the mage T1 4pc
+// Also used to extend the arcane buff from the mage T1 4pc
 func (mage *Mage) registerCounterspellSpell() {
 	mage.RegisterSpell(core.SpellConfig{
 		ActionID:    core.ActionID{SpellID: 2139},
@@ -26,8 +26,8 @@ func (mage *Mage) registerCounterspellSpell() {
 		},
 
 		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
-			// Do nothing
 			// TODO: Generates a high amount of threat
+			target.Interrupt(sim, spell, time.Second*10)
 		},
 	})
 }
@@ -37,6 +37,10 @@ const (
 
 var TalentTreeSizes = [3]int{16, 16, 17}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassMage, TalentTreeSizes)
+}
+
 func RegisterMage() {
 	core.RegisterAgentFactory(
 		proto.Player_Mage{},
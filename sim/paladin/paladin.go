@@ -11,6 +11,10 @@ import (
 
 var TalentTreeSizes = [3]int{14, 15, 15}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassPaladin, TalentTreeSizes)
+}
+
 const (
 	SpellFlag_RV          = core.SpellFlagAgentReserved1
 	SpellFlag_Forbearance = core.SpellFlagAgentReserved2
@@ -9,6 +9,10 @@ import (
 
 var TalentTreeSizes = [3]int{15, 16, 16}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassPriest, TalentTreeSizes)
+}
+
 const (
 	SpellFlagPriest = core.SpellFlagAgentReserved1
 )
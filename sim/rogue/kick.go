@@ -0,0 +1,65 @@
+package rogue
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core"
+)
+
+func (rogue *Rogue) registerKickSpell() {
+	ranks := []struct {
+		level   int32
+		spellID int32
+		damage  float64
+	}{
+		{level: 12, spellID: 1766, damage: 15},
+		{level: 26, spellID: 1767, damage: 30},
+		{level: 42, spellID: 1768, damage: 45},
+		{level: 58, spellID: 1769, damage: 80},
+	}
+
+	rank := ranks[0]
+	for _, r := range ranks {
+		if rogue.Level >= r.level {
+			rank = r
+		}
+	}
+	damage := rank.damage
+
+	rogue.Kick = rogue.RegisterSpell(core.SpellConfig{
+		ActionID:    core.ActionID{SpellID: rank.spellID},
+		SpellSchool: core.SpellSchoolPhysical,
+		DefenseType: core.DefenseTypeMelee,
+		ProcMask:    core.ProcMaskMeleeMHSpecial,
+		Flags:       core.SpellFlagMeleeMetrics | core.SpellFlagAPL,
+
+		EnergyCost: core.EnergyCostOptions{
+			Cost:   25,
+			Refund: 0.8,
+		},
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				GCD: time.Second,
+			},
+			CD: core.Cooldown{
+				Timer:    rogue.NewTimer(),
+				Duration: time.Second * 10,
+			},
+			IgnoreHaste: true,
+		},
+
+		DamageMultiplier: 1,
+		ThreatMultiplier: 1,
+
+		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+			rogue.BreakStealth(sim)
+			result := spell.CalcAndDealDamage(sim, target, damage, spell.OutcomeMeleeSpecialHitAndCrit)
+
+			if result.Landed() {
+				target.Interrupt(sim, spell, time.Second*5)
+			} else {
+				spell.IssueRefund(sim)
+			}
+		},
+	})
+}
@@ -46,6 +46,10 @@ const (
 
 var TalentTreeSizes = [3]int{15, 19, 17}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassRogue, TalentTreeSizes)
+}
+
 const RogueBleedTag = "RogueBleed"
 
 type Rogue struct {
@@ -61,6 +65,7 @@ type Rogue struct {
 	Backstab            *core.Spell
 	BladeFlurry         *core.Spell
 	Feint               *core.Spell
+	Kick                *core.Spell
 	Garrote             *core.Spell
 	Ambush              *core.Spell
 	Hemorrhage          *core.Spell
@@ -156,6 +161,7 @@ func (rogue *Rogue) Initialize() {
 	rogue.registerEviscerate()
 	rogue.registerExposeArmorSpell()
 	rogue.registerFeintSpell()
+	rogue.registerKickSpell()
 	rogue.registerGarrote()
 	rogue.registerHemorrhageSpell()
 	rogue.registerRupture()
@@ -55,7 +55,7 @@ func (enh *EnhancementShaman) GetShaman() *shaman.Shaman {
 func (enh *EnhancementShaman) Initialize() {
 	enh.Shaman.Initialize()
 
-	if enh.ItemSwap.IsEnabled() {
+	if enh.HasItemSwap() {
 		enh.RegisterOnItemSwap(func(_ *core.Simulation) {
 			enh.ApplySyncType(proto.ShamanSyncType_Auto)
 		})
@@ -67,7 +67,7 @@ func (shaman *Shaman) ApplyFlametongueImbue(procMask core.ProcMask) {
 }
 
 func (shaman *Shaman) RegisterFlametongueImbue(procMask core.ProcMask) {
-	if procMask == core.ProcMaskUnknown && !shaman.ItemSwap.IsEnabled() {
+	if procMask == core.ProcMaskUnknown && !shaman.HasItemSwap() {
 		return
 	}
 
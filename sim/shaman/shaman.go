@@ -11,6 +11,10 @@ import (
 
 var TalentTreeSizes = [3]int{15, 16, 15}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassShaman, TalentTreeSizes)
+}
+
 const (
 	SpellFlagShaman    = core.SpellFlagAgentReserved1
 	SpellFlagTotem     = core.SpellFlagAgentReserved2
@@ -9,6 +9,10 @@ import (
 
 var TalentTreeSizes = [3]int{17, 17, 16}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassWarlock, TalentTreeSizes)
+}
+
 const (
 	WarlockFlagAffliction  = core.SpellFlagAgentReserved1
 	WarlockFlagDemonology  = core.SpellFlagAgentReserved2
@@ -0,0 +1,67 @@
+package warrior
+
+import (
+	"time"
+
+	"github.com/wowsims/classic/sim/core"
+)
+
+func (warrior *Warrior) registerPummelSpell() {
+	ranks := []struct {
+		level   int32
+		spellID int32
+		damage  float64
+	}{
+		{level: 38, spellID: 6552, damage: 20},
+		{level: 58, spellID: 6554, damage: 50},
+	}
+
+	if warrior.Level < ranks[0].level {
+		return
+	}
+
+	rank := ranks[0]
+	for _, r := range ranks {
+		if warrior.Level >= r.level {
+			rank = r
+		}
+	}
+	damage := rank.damage
+
+	warrior.Pummel = warrior.RegisterSpell(BerserkerStance, core.SpellConfig{
+		ActionID:    core.ActionID{SpellID: rank.spellID},
+		SpellSchool: core.SpellSchoolPhysical,
+		DefenseType: core.DefenseTypeMelee,
+		ProcMask:    core.ProcMaskMeleeMHSpecial,
+		Flags:       core.SpellFlagMeleeMetrics | core.SpellFlagAPL | SpellFlagOffensive,
+
+		RageCost: core.RageCostOptions{
+			Cost:   10,
+			Refund: 0.8,
+		},
+		Cast: core.CastConfig{
+			DefaultCast: core.Cast{
+				GCD: core.GCDDefault,
+			},
+			CD: core.Cooldown{
+				Timer:    warrior.NewTimer(),
+				Duration: time.Second * 10,
+			},
+		},
+
+		CritDamageBonus: warrior.impale(),
+
+		DamageMultiplier: 1,
+		ThreatMultiplier: 1,
+
+		ApplyEffects: func(sim *core.Simulation, target *core.Unit, spell *core.Spell) {
+			result := spell.CalcAndDealDamage(sim, target, damage, spell.OutcomeMeleeSpecialHitAndCrit)
+
+			if result.Landed() {
+				target.Interrupt(sim, spell, time.Second*4)
+			} else {
+				spell.IssueRefund(sim)
+			}
+		},
+	})
+}
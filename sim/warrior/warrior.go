@@ -40,6 +40,10 @@ const (
 
 var TalentTreeSizes = [3]int{18, 17, 17}
 
+func init() {
+	core.RegisterTalentTreeSizes(proto.Class_ClassWarrior, TalentTreeSizes)
+}
+
 type WarriorInputs struct {
 	StanceSnapshot bool
 	Stance         proto.WarriorStance
@@ -119,6 +123,7 @@ type Warrior struct {
 	ConcussionBlow    *WarriorSpell
 	RagingBlow        *WarriorSpell
 	Hamstring         *WarriorSpell
+	Pummel            *WarriorSpell
 	Rampage           *WarriorSpell
 	Shockwave         *WarriorSpell
 
@@ -248,6 +253,7 @@ func (warrior *Warrior) Initialize() {
 	warrior.registerWhirlwindSpell()
 	warrior.registerRendSpell()
 	warrior.registerHamstringSpell()
+	warrior.registerPummelSpell()
 
 	// The sim often re-enables heroic strike in an unrealistic amount of time.
 	// This can cause an unrealistic immediate double-hit around wild strikes procs
@@ -48,9 +48,13 @@ func main() {
 	var host = flag.String("host", "localhost:3333", "URL to host the interface on.")
 	var launch = flag.Bool("launch", true, "auto launch browser")
 	var skipVersionCheck = flag.Bool("nvc", false, "set true to skip version check")
+	var checkpointDir = flag.String("checkpointDir", "", "Directory for checkpoints of bulk sims and stat weights. Empty to disable.")
+	var resumeCheckpoints = flag.Bool("resumeCheckpoints", false, "Resume bulk sims and stat weights from the checkpoints in checkpointDir when the same request is simmed again. Only gives the same results as an uninterrupted run for requests with a fixed random seed.")
 
 	flag.Parse()
 
+	core.EnableCheckpoints(*checkpointDir, *resumeCheckpoints)
+
 	fmt.Printf("Version: %s\n", Version)
 	if !*skipVersionCheck && Version != "development" {
 		go func() {
@@ -123,6 +127,18 @@ var asyncAPIHandlers = map[string]asyncAPIHandler{
 	"/bulkSimAsync": {msg: func() googleProto.Message { return &proto.BulkSimRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics, requestId string) {
 		core.RunBulkSimAsync(msg.(*proto.BulkSimRequest), reporter, requestId)
 	}},
+	"/sweepAsync": {msg: func() googleProto.Message { return &proto.SweepRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics, requestId string) {
+		core.RunSweepAsync(msg.(*proto.SweepRequest), reporter, requestId)
+	}},
+	"/buffValueAsync": {msg: func() googleProto.Message { return &proto.BuffValueRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics, requestId string) {
+		core.RunBuffValueAsync(msg.(*proto.BuffValueRequest), reporter, requestId)
+	}},
+	"/cooldownOptimizerAsync": {msg: func() googleProto.Message { return &proto.CooldownOptimizerRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics, requestId string) {
+		core.RunCooldownOptimizerAsync(msg.(*proto.CooldownOptimizerRequest), reporter, requestId)
+	}},
+	"/trinketRankingAsync": {msg: func() googleProto.Message { return &proto.TrinketRankingRequest{} }, handle: func(msg googleProto.Message, reporter chan *proto.ProgressMetrics, requestId string) {
+		core.RunTrinketRankingAsync(msg.(*proto.TrinketRankingRequest), reporter, requestId)
+	}},
 }
 
 type server struct {
@@ -139,6 +155,17 @@ type asyncAPIHandler struct {
 	handle func(googleProto.Message, chan *proto.ProgressMetrics, string)
 }
 
+// Returns true if the progress message carries the final result of an async request.
+func hasFinalResult(progress *proto.ProgressMetrics) bool {
+	return progress.FinalRaidResult != nil ||
+		progress.FinalWeightResult != nil ||
+		progress.FinalBulkResult != nil ||
+		progress.FinalSweepResult != nil ||
+		progress.FinalBuffValueResult != nil ||
+		progress.FinalCooldownOptimizerResult != nil ||
+		progress.FinalTrinketRankingResult != nil
+}
+
 type asyncProgress struct {
 	id             string
 	latestProgress atomic.Value
@@ -203,7 +230,7 @@ func (s *server) handleAsyncAPI(w http.ResponseWriter, r *http.Request) {
 					return
 				}
 				simProgress.latestProgress.Store(progMetric)
-				if progMetric.FinalRaidResult != nil || progMetric.FinalWeightResult != nil || progMetric.FinalBulkResult != nil {
+				if hasFinalResult(progMetric) {
 					return
 				}
 			}
@@ -261,7 +288,7 @@ func (s *server) setupAsyncServer() {
 		}
 
 		// If this was the last result, delete the cache for this simulation.
-		if latest.FinalRaidResult != nil || latest.FinalWeightResult != nil || latest.FinalBulkResult != nil {
+		if hasFinalResult(latest) {
 			s.progMut.Lock()
 			delete(s.asyncProgresses, msg.ProgressId)
 			s.progMut.Unlock()
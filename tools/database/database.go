@@ -270,7 +270,14 @@ func (db *WowDatabase) ToUIProto() *proto.UIDatabase {
 		if v1.EffectId != v2.EffectId {
 			return int(v1.EffectId - v2.EffectId)
 		}
-		return int(v1.Type - v2.Type)
+		if v1.Type != v2.Type {
+			return int(v1.Type - v2.Type)
+		}
+		// Enchants can share an effect and type, so also sort by the rest of the key to keep the output stable.
+		if v1.ItemId != v2.ItemId {
+			return int(v1.ItemId - v2.ItemId)
+		}
+		return int(v1.SpellId - v2.SpellId)
 	})
 
 	return &proto.UIDatabase{
@@ -310,6 +317,7 @@ func ReadDatabaseFromJson(jsonStr string) *WowDatabase {
 		Items:          sliceToMap(dbProto.Items),
 		RandomSuffixes: sliceToMap(dbProto.RandomSuffixes),
 		Enchants:       enchants,
+		Runes:          sliceToMap(dbProto.Runes),
 		Zones:          sliceToMap(dbProto.Zones),
 		Npcs:           sliceToMap(dbProto.Npcs),
 		Factions:       sliceToMap(dbProto.Factions),
@@ -0,0 +1,196 @@
+package database
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/wowsims/classic/sim/core"
+	"github.com/wowsims/classic/sim/core/proto"
+	"golang.org/x/exp/maps"
+	"google.golang.org/protobuf/encoding/protojson"
+	googleProto "google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+type DBChangeType string
+
+const (
+	DBChangeAdded   DBChangeType = "added"
+	DBChangeRemoved DBChangeType = "removed"
+	DBChangeChanged DBChangeType = "changed"
+)
+
+// A single difference between two versions of the database.
+type DBChange struct {
+	Kind   string // 'item', 'enchant', 'rune' or 'randomSuffix'.
+	ID     string
+	Name   string
+	Change DBChangeType
+	Field  string // Changed field, e.g. 'stats.Strength' or 'phase'. Empty for added and removed entries.
+	Old    string
+	New    string
+}
+
+// Returns the changed items, enchants, runes and random suffixes between two versions of the database,
+// ordered by kind, id and field.
+func DiffDatabases(oldDB, newDB *WowDatabase) []DBChange {
+	idToString := func(id int32) string { return fmt.Sprint(id) }
+	// Enchants are identified by 'effect/item/spell' ids, see EnchantDBKey.
+	enchantKeyToString := func(key EnchantDBKey) string {
+		return fmt.Sprintf("%d/%d/%d", key.EffectID, key.ItemID, key.SpellID)
+	}
+	compareEnchantKeys := func(a, b EnchantDBKey) int {
+		if a.EffectID != b.EffectID {
+			return cmp.Compare(a.EffectID, b.EffectID)
+		}
+		if a.ItemID != b.ItemID {
+			return cmp.Compare(a.ItemID, b.ItemID)
+		}
+		return cmp.Compare(a.SpellID, b.SpellID)
+	}
+
+	var changes []DBChange
+	changes = append(changes, diffEntries("item", oldDB.Items, newDB.Items, idToString, cmp.Compare[int32])...)
+	changes = append(changes, diffEntries("enchant", oldDB.Enchants, newDB.Enchants, enchantKeyToString, compareEnchantKeys)...)
+	changes = append(changes, diffEntries("rune", oldDB.Runes, newDB.Runes, idToString, cmp.Compare[int32])...)
+	changes = append(changes, diffEntries("randomSuffix", oldDB.RandomSuffixes, newDB.RandomSuffixes, idToString, cmp.Compare[int32])...)
+	return changes
+}
+
+type namedMessage interface {
+	googleProto.Message
+	GetName() string
+}
+
+func diffEntries[K comparable, T namedMessage](kind string, oldEntries, newEntries map[K]T, keyToID func(K) string, compareKeys func(a, b K) int) []DBChange {
+	keys := maps.Keys(oldEntries)
+	for key := range newEntries {
+		if _, ok := oldEntries[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.SortFunc(keys, compareKeys)
+
+	var changes []DBChange
+	for _, key := range keys {
+		id := keyToID(key)
+		oldEntry, inOld := oldEntries[key]
+		newEntry, inNew := newEntries[key]
+		switch {
+		case !inOld:
+			changes = append(changes, DBChange{Kind: kind, ID: id, Name: newEntry.GetName(), Change: DBChangeAdded})
+		case !inNew:
+			changes = append(changes, DBChange{Kind: kind, ID: id, Name: oldEntry.GetName(), Change: DBChangeRemoved})
+		default:
+			for _, fieldChange := range diffMessages(oldEntry.ProtoReflect(), newEntry.ProtoReflect()) {
+				fieldChange.Kind = kind
+				fieldChange.ID = id
+				fieldChange.Name = newEntry.GetName()
+				fieldChange.Change = DBChangeChanged
+				changes = append(changes, fieldChange)
+			}
+		}
+	}
+	return changes
+}
+
+// Returns the changed fields of two entries, with stats split into one change per stat and repeated messages
+// (e.g. sources) reported as the removed and added elements.
+func diffMessages(oldMsg, newMsg protoreflect.Message) []DBChange {
+	var changes []DBChange
+	fields := oldMsg.Descriptor().Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		if fd.Name() == "id" {
+			continue
+		}
+		name := fd.JSONName()
+
+		switch {
+		case fd.Name() == "stats":
+			oldStats, newStats := oldMsg.Get(fd).List(), newMsg.Get(fd).List()
+			for stat := 0; stat < max(oldStats.Len(), newStats.Len()); stat++ {
+				oldValue, newValue := listFloat(oldStats, stat), listFloat(newStats, stat)
+				if oldValue != newValue {
+					changes = append(changes, DBChange{
+						Field: name + "." + strings.TrimPrefix(proto.Stat(stat).String(), "Stat"),
+						Old:   fmt.Sprint(oldValue),
+						New:   fmt.Sprint(newValue),
+					})
+				}
+			}
+		case fd.IsList() && fd.Message() != nil:
+			oldElements := formatElements(oldMsg.Get(fd).List())
+			newElements := formatElements(newMsg.Get(fd).List())
+			removed := core.FilterSlice(oldElements, func(e string) bool { return !slices.Contains(newElements, e) })
+			added := core.FilterSlice(newElements, func(e string) bool { return !slices.Contains(oldElements, e) })
+			if len(removed) > 0 || len(added) > 0 {
+				changes = append(changes, DBChange{Field: name, Old: strings.Join(removed, "; "), New: strings.Join(added, "; ")})
+			}
+		default:
+			oldValue, newValue := formatField(fd, oldMsg.Get(fd)), formatField(fd, newMsg.Get(fd))
+			if oldValue != newValue {
+				changes = append(changes, DBChange{Field: name, Old: oldValue, New: newValue})
+			}
+		}
+	}
+	return changes
+}
+
+func listFloat(list protoreflect.List, i int) float64 {
+	if i >= list.Len() {
+		return 0
+	}
+	return list.Get(i).Float()
+}
+
+func formatElements(list protoreflect.List) []string {
+	elements := make([]string, list.Len())
+	for i := range elements {
+		elements[i] = formatMessage(list.Get(i).Message().Interface())
+	}
+	return elements
+}
+
+func formatMessage(msg googleProto.Message) string {
+	jsonBytes, err := protojson.Marshal(msg)
+	if err != nil {
+		panic(err)
+	}
+	// Compact the output, as protojson adds random whitespace.
+	buffer := new(bytes.Buffer)
+	json.Compact(buffer, jsonBytes)
+	return buffer.String()
+}
+
+func formatField(fd protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	if fd.IsList() {
+		list := value.List()
+		values := make([]string, list.Len())
+		for i := range values {
+			values[i] = formatValue(fd, list.Get(i))
+		}
+		return strings.Join(values, ", ")
+	}
+	return formatValue(fd, value)
+}
+
+func formatValue(fd protoreflect.FieldDescriptor, value protoreflect.Value) string {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if enumValue := fd.Enum().Values().ByNumber(value.Enum()); enumValue != nil {
+			return string(enumValue.Name())
+		}
+		return fmt.Sprint(value.Enum())
+	case protoreflect.MessageKind:
+		if !value.Message().IsValid() {
+			return ""
+		}
+		return formatMessage(value.Message().Interface())
+	}
+	return value.String()
+}
@@ -0,0 +1,48 @@
+package database
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/wowsims/classic/sim/core/proto"
+	"github.com/wowsims/classic/sim/core/stats"
+)
+
+func TestDiffDatabases(t *testing.T) {
+	newStats := func(stamina float64) []float64 {
+		itemStats := stats.Stats{}
+		itemStats[stats.Stamina] = stamina
+		return itemStats[:]
+	}
+	dropSource := func(npcID int32) *proto.UIItemSource {
+		return &proto.UIItemSource{Source: &proto.UIItemSource_Drop{Drop: &proto.DropSource{NpcId: npcID}}}
+	}
+
+	oldDB := NewWowDatabase()
+	oldDB.Items[2] = &proto.UIItem{Id: 2, Name: "Changed", Phase: 1, Stats: newStats(10), Sources: []*proto.UIItemSource{dropSource(100)}}
+	oldDB.Items[3] = &proto.UIItem{Id: 3, Name: "Removed"}
+	oldDB.Items[4] = &proto.UIItem{Id: 4, Name: "Unchanged", Quality: proto.ItemQuality_ItemQualityEpic}
+	oldDB.Enchants[EnchantDBKey{EffectID: 5, SpellID: 6}] = &proto.UIEnchant{EffectId: 5, SpellId: 6, Name: "Enchant"}
+
+	newDB := NewWowDatabase()
+	newDB.Items[1] = &proto.UIItem{Id: 1, Name: "Added"}
+	newDB.Items[2] = &proto.UIItem{Id: 2, Name: "Changed", Phase: 2, Stats: newStats(12), Sources: []*proto.UIItemSource{dropSource(101)}}
+	newDB.Items[4] = &proto.UIItem{Id: 4, Name: "Unchanged", Quality: proto.ItemQuality_ItemQualityEpic}
+	newDB.Enchants[EnchantDBKey{EffectID: 5, SpellID: 6}] = &proto.UIEnchant{EffectId: 5, SpellId: 6, Name: "Enchant", Quality: proto.ItemQuality_ItemQualityRare}
+
+	expected := []DBChange{
+		{Kind: "item", ID: "1", Name: "Added", Change: DBChangeAdded},
+		{Kind: "item", ID: "2", Name: "Changed", Change: DBChangeChanged, Field: "stats.Stamina", Old: "10", New: "12"},
+		{Kind: "item", ID: "2", Name: "Changed", Change: DBChangeChanged, Field: "phase", Old: "1", New: "2"},
+		{Kind: "item", ID: "2", Name: "Changed", Change: DBChangeChanged, Field: "sources", Old: `{"drop":{"npcId":100}}`, New: `{"drop":{"npcId":101}}`},
+		{Kind: "item", ID: "3", Name: "Removed", Change: DBChangeRemoved},
+		{Kind: "enchant", ID: "5/0/6", Name: "Enchant", Change: DBChangeChanged, Field: "quality", Old: "ItemQualityJunk", New: "ItemQualityRare"},
+	}
+	if changes := DiffDatabases(oldDB, newDB); !reflect.DeepEqual(changes, expected) {
+		t.Fatalf("Unexpected changes:\n%v\nexpected:\n%v", changes, expected)
+	}
+
+	if changes := DiffDatabases(newDB, newDB); len(changes) != 0 {
+		t.Fatalf("Expected no changes between the same databases, got %v", changes)
+	}
+}
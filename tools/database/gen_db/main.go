@@ -30,12 +30,31 @@ import (
 // Lastly run the following to generate db.json (ensure to delete cached versions and/or rebuild for copying of assets during local development)
 // Note: This does not make network requests, only regenerates core db binary and json files from existing inputs
 // go run ./tools/database/gen_db -outDir=assets -gen=db
+//
+// To build from local dumps instead of the scraped inputs, point -inputsDir at a directory with the same files
+// (tooltip CSVs, the gearplanner db, the atlasloot json and the ItemSparse DB2 CSV export). The output only
+// depends on the inputs and the overrides, so building twice from the same inputs gives identical files.
+// go run ./tools/database/gen_db -outDir=assets -gen=db -inputsDir=/path/to/dumps
+//
+// Review the changes of a rebuild with:
+// go run ./cmd/wowsimcli db diff old_db.json assets/database/db.json
 
 var exactId = flag.Int("id", 0, "ID to scan for")
 var minId = flag.Int("minid", 1, "Minimum ID to scan for")
 var maxId = flag.Int("maxid", 31000, "Maximum ID to scan for")
 var outDir = flag.String("outDir", "assets", "Path to output directory for writing generated .go files.")
 var genAsset = flag.String("gen", "", "Asset to generate. Valid values are 'db', 'atlasloot', 'wowhead-items', 'wowhead-spells', 'wowhead-itemdb', 'wotlk-items', and 'wago-db2-items'")
+var inputsDirFlag = flag.String("inputsDir", "", "Path to the directory of db inputs. Defaults to <outDir>/db_inputs.")
+
+// Inputs read by -gen=db, none of which are optional.
+var dbInputFiles = []string{
+	"wowhead_item_tooltips.csv",
+	"wowhead_spell_tooltips.csv",
+	"wowhead_rune_tooltips.csv",
+	"wowhead_gearplannerdb.txt",
+	"atlasloot_db.json",
+	"wago_db2_items.csv",
+}
 
 func main() {
 	flag.Parse()
@@ -51,6 +70,9 @@ func main() {
 
 	dbDir := fmt.Sprintf("%s/database", *outDir)
 	inputsDir := fmt.Sprintf("%s/db_inputs", *outDir)
+	if *inputsDirFlag != "" {
+		inputsDir = *inputsDirFlag
+	}
 
 	if *genAsset == "atlasloot" {
 		db := database.ReadAtlasLootData(inputsDir)
@@ -72,6 +94,19 @@ func main() {
 		panic("Invalid gen value")
 	}
 
+	// Missing tooltip files would otherwise be read as empty, silently dropping most of the db.
+	for _, inputFile := range dbInputFiles {
+		if _, err := os.Stat(fmt.Sprintf("%s/%s", inputsDir, inputFile)); err != nil {
+			log.Fatalf("Missing db input: %s", err)
+		}
+	}
+
+	generateDB(inputsDir, dbDir, fmt.Sprintf("%s/../ui/core/talents/trees", *outDir))
+}
+
+// Builds db.bin, db.json and the leftover db from the inputs, writing them to dbDir. The output only
+// depends on the inputs, the overrides and the talent trees.
+func generateDB(inputsDir string, dbDir string, talentsDir string) {
 	itemTooltips := database.NewWowheadItemTooltipManager(fmt.Sprintf("%s/wowhead_item_tooltips.csv", inputsDir)).Read()
 	spellTooltips := database.NewWowheadSpellTooltipManager(fmt.Sprintf("%s/wowhead_spell_tooltips.csv", inputsDir)).Read()
 	runeTooltips := database.NewWowheadSpellTooltipManager(fmt.Sprintf("%s/wowhead_rune_tooltips.csv", inputsDir)).Read()
@@ -209,7 +244,7 @@ func main() {
 		db.AddSpellIcon(spellId, spellTooltips)
 	}
 
-	for _, spellIds := range GetAllTalentSpellIds(&inputsDir) {
+	for _, spellIds := range GetAllTalentSpellIds(&talentsDir) {
 		for _, spellId := range spellIds {
 			db.AddSpellIcon(spellId, spellTooltips)
 		}
@@ -378,8 +413,7 @@ func getSpellIdsFromTalentJson(infile *string) []int32 {
 	return spellIds
 }
 
-func GetAllTalentSpellIds(inputsDir *string) map[string][]int32 {
-	talentsDir := fmt.Sprintf("%s/../../ui/core/talents/trees", *inputsDir)
+func GetAllTalentSpellIds(talentsDir *string) map[string][]int32 {
 	specFiles := []string{
 		"druid.json",
 		"hunter.json",
@@ -395,7 +429,7 @@ func GetAllTalentSpellIds(inputsDir *string) map[string][]int32 {
 	ret_db := make(map[string][]int32, 0)
 
 	for _, specFile := range specFiles {
-		specPath := fmt.Sprintf("%s/%s", talentsDir, specFile)
+		specPath := fmt.Sprintf("%s/%s", *talentsDir, specFile)
 		ret_db[specFile[:len(specFile)-5]] = getSpellIdsFromTalentJson(&specPath)
 	}
 
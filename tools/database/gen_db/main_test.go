@@ -0,0 +1,69 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"testing"
+
+	"github.com/wowsims/classic/tools/database"
+)
+
+// Builds the db twice from the same inputs, which has to give identical files. Only the runes,
+// the atlasloot data and the overrides are used as inputs, as the scraped tooltips aren't checked in.
+func TestGenerateDBIsReproducible(t *testing.T) {
+	inputsDir := t.TempDir()
+	for _, inputFile := range dbInputFiles {
+		contents, err := os.ReadFile(fmt.Sprintf("../../../assets/db_inputs/%s", inputFile))
+		if os.IsNotExist(err) {
+			contents = nil
+		} else if err != nil {
+			t.Fatal(err)
+		}
+		switch {
+		case inputFile == "wowhead_item_tooltips.csv" && contents == nil:
+			contents = stubItemTooltips()
+		case inputFile == "wago_db2_items.csv" && contents == nil:
+			contents = []byte("ID,Flags_1,ItemSet\n")
+		}
+		if err := os.WriteFile(fmt.Sprintf("%s/%s", inputsDir, inputFile), contents, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	talentsDir := "../../../ui/core/talents/trees"
+	dbDir1, dbDir2 := t.TempDir(), t.TempDir()
+	generateDB(inputsDir, dbDir1, talentsDir)
+	generateDB(inputsDir, dbDir2, talentsDir)
+
+	for _, outputFile := range []string{"db.bin", "db.json", "leftover_db.bin", "leftover_db.json"} {
+		output1, err := os.ReadFile(fmt.Sprintf("%s/%s", dbDir1, outputFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		output2, err := os.ReadFile(fmt.Sprintf("%s/%s", dbDir2, outputFile))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(output1) == 0 || !bytes.Equal(output1, output2) {
+			t.Fatalf("Expected %s to be the same non-empty file in both builds, got %d and %d bytes", outputFile, len(output1), len(output2))
+		}
+	}
+}
+
+// Returns tooltips for the items whose icons are always added to the db, which fails without them.
+func stubItemTooltips() []byte {
+	itemIDs := slices.Clone(database.ExtraItemIcons)
+	for _, enchant := range database.EnchantOverrides {
+		if enchant.ItemId != 0 {
+			itemIDs = append(itemIDs, enchant.ItemId)
+		}
+	}
+
+	buffer := new(bytes.Buffer)
+	for _, id := range itemIDs {
+		fmt.Fprintf(buffer, "%d,{\"name\":\"Item %d\",\"quality\":1,\"icon\":\"inv_misc_questionmark\",\"tooltip\":\"\"}\n", id, id)
+	}
+	return buffer.Bytes()
+}
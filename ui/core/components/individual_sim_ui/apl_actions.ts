@@ -6,9 +6,12 @@ import {
 	APLActionActivateAuraWithStacks,
 	APLActionAddComboPoints,
 	APLActionAutocastOtherCooldowns,
+	APLActionBattleRes,
 	APLActionCancelAura,
 	APLActionCastPaladinPrimarySeal,
 	APLActionCastSpell,
+	APLActionCastSpellRank,
+	APLActionCastSpellRank_RankPolicy as RankPolicy,
 	APLActionCatOptimalRotationAction,
 	APLActionChangeTarget,
 	APLActionChannelSpell,
@@ -22,6 +25,7 @@ import {
 	APLActionSchedule,
 	APLActionSequence,
 	APLActionStrictSequence,
+	APLActionSwapToItemSet,
 	APLActionTriggerICD,
 	APLActionWait,
 	APLActionWaitUntil,
@@ -271,6 +275,26 @@ function itemSwapSetFieldConfig(field: string): AplHelpers.APLPickerBuilderField
 	};
 }
 
+function rankPolicyFieldConfig(field: string): AplHelpers.APLPickerBuilderFieldConfig<any, any> {
+	return {
+		field: field,
+		newValue: () => RankPolicy.RankPolicyMaxRank,
+		factory: (parent, player, config) =>
+			new TextDropdownPicker(parent, player, {
+				id: randomUUID(),
+				...config,
+				defaultLabel: 'Max Rank',
+				equals: (a, b) => a == b,
+				values: [
+					{ value: RankPolicy.RankPolicyMaxRank, label: 'Max Rank' },
+					{ value: RankPolicy.RankPolicyDamagePerMana, label: 'Damage per Mana' },
+					{ value: RankPolicy.RankPolicyManaBudget, label: 'Mana Budget' },
+					{ value: RankPolicy.RankPolicyDeadline, label: 'Deadline' },
+				],
+			}),
+	};
+}
+
 function actionFieldConfig(field: string): AplHelpers.APLPickerBuilderFieldConfig<any, any> {
 	return {
 		field: field,
@@ -340,6 +364,29 @@ const actionKindFactories: { [f in NonNullable<APLActionKind>]: ActionKindConfig
 		newValue: APLActionCastSpell.create,
 		fields: [AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''), AplHelpers.unitFieldConfig('target', 'targets')],
 	}),
+	['castSpellRank']: inputBuilder({
+		label: 'Cast Rank',
+		submenu: ['Casting'],
+		shortDescription: 'Casts one of the ranks of the spell, chosen by the policy.',
+		fullDescription: `
+			<ul>
+				<li><b>Max Rank</b>: The highest rank which can be cast, downranking when the higher ranks can't be afforded.</li>
+				<li><b>Damage per Mana</b>: The rank with the most damage and healing per mana, based on the casts so far in the iteration. Every rank is cast once per iteration to find out how much it does.</li>
+				<li><b>Mana Budget</b>: The highest rank which can be cast for the rest of the fight, with the current mana and regen.</li>
+				<li><b>Deadline</b>: The highest rank which finishes casting before the deadline, which defaults to the remaining fight time.</li>
+			</ul>
+		`,
+		newValue: APLActionCastSpellRank.create,
+		fields: [
+			AplHelpers.actionIdFieldConfig('spellId', 'castable_spells', ''),
+			AplHelpers.unitFieldConfig('target', 'targets'),
+			rankPolicyFieldConfig('policy'),
+			AplValues.valueFieldConfig('deadline', {
+				label: 'Deadline',
+				labelTooltip: 'Time from now by which the cast must finish, for the Deadline policy.',
+			}),
+		],
+	}),
 	['multidot']: inputBuilder({
 		label: 'Multi Dot',
 		submenu: ['Casting'],
@@ -538,6 +585,24 @@ const actionKindFactories: { [f in NonNullable<APLActionKind>]: ActionKindConfig
 		newValue: () => APLActionChangeTarget.create(),
 		fields: [AplHelpers.unitFieldConfig('newTarget', 'targets')],
 	}),
+	['battleRes']: inputBuilder({
+		label: 'Battle Res',
+		submenu: ['Misc'],
+		shortDescription: 'Resurrects a dead player, e.g. with Rebirth.',
+		fullDescription: `
+			<p>Only works if player deaths are enabled, and counts towards the maximum number of resurrections of the raid.</p>
+			<p>The player comes back after the <b>delay</b>, which should cover the cast time and the time to accept the resurrection.</p>
+		`,
+		includeIf: (player: Player<any>, isPrepull: boolean) => !isPrepull,
+		newValue: () => APLActionBattleRes.create({ delaySeconds: 2 }),
+		fields: [
+			AplHelpers.unitFieldConfig('target', 'players'),
+			AplHelpers.numberFieldConfig('delaySeconds', true, {
+				label: 'delay',
+				labelTooltip: 'Seconds until the player is back.',
+			}),
+		],
+	}),
 	['activateAura']: inputBuilder({
 		label: 'Activate Aura',
 		submenu: ['Misc'],
@@ -601,6 +666,17 @@ const actionKindFactories: { [f in NonNullable<APLActionKind>]: ActionKindConfig
 		newValue: () => APLActionItemSwap.create(),
 		fields: [itemSwapSetFieldConfig('swapSet')],
 	}),
+	['swapToItemSet']: inputBuilder({
+		label: 'Swap to Item Set',
+		submenu: ['Misc'],
+		shortDescription: 'Swaps to one of the named item swap sets, or back to the main gear.',
+		fullDescription: `
+			<p>Use <b>Main</b> as the set name to swap back to the gear the character started with.</p>
+			<p>Swapped in items with an on-use effect are put on a 30 second cooldown.</p>
+		`,
+		newValue: () => APLActionSwapToItemSet.create({ setName: 'Main' }),
+		fields: [AplHelpers.stringFieldConfig('setName', { label: 'Set Name' })],
+	}),
 	['move']: inputBuilder({
 		label: 'Move',
 		submenu: ['Misc'],
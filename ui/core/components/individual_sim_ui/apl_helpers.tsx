@@ -359,7 +359,7 @@ export class APLRunePicker extends DropdownPicker<Player<any>, ActionID, Rune> {
 	}
 }
 
-export type UNIT_SET = 'aura_sources' | 'aura_sources_targets_first' | 'targets';
+export type UNIT_SET = 'aura_sources' | 'aura_sources_targets_first' | 'players' | 'targets';
 
 const unitSets: Record<
 	UNIT_SET,
@@ -396,6 +396,14 @@ const unitSets: Record<
 			].flat();
 		},
 	},
+	players: {
+		getUnits: player => {
+			return player.sim.raid
+				.getActivePlayers()
+				.filter(raidPlayer => raidPlayer != player)
+				.map(raidPlayer => UnitReference.create({ type: UnitType.Player, index: raidPlayer.getRaidIndex() }));
+		},
+	},
 	targets: {
 		targetUI: true,
 		getUnits: player => {
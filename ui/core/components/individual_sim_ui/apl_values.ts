@@ -24,6 +24,7 @@ import {
 	APLValueCurrentEnergy,
 	APLValueCurrentHealth,
 	APLValueCurrentHealthPercent,
+	APLValueCurrentItemSwapSet,
 	APLValueCurrentMana,
 	APLValueCurrentManaPercent,
 	APLValueCurrentRage,
@@ -61,6 +62,8 @@ import {
 	APLValueSpellIsReady,
 	APLValueSpellTimeToReady,
 	APLValueSpellTravelTime,
+	APLValueTargetCastRemainingTime,
+	APLValueTargetIsCastingInterruptible,
 	APLValueTimeToEnergyTick,
 	APLValueTotemRemainingTime,
 	APLValueWarlockCurrentPetMana,
@@ -596,6 +599,20 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		newValue: APLValueFrontOfTarget.create,
 		fields: [],
 	}),
+	targetIsCastingInterruptible: inputBuilder({
+		label: 'Target Is Casting Interruptible',
+		submenu: ['Encounter'],
+		shortDescription: '<b>True</b> if the target is casting a spell which can be interrupted, otherwise <b>False</b>.',
+		newValue: APLValueTargetIsCastingInterruptible.create,
+		fields: [AplHelpers.unitFieldConfig('targetUnit', 'targets')],
+	}),
+	targetCastRemainingTime: inputBuilder({
+		label: 'Target Cast Remaining Time',
+		submenu: ['Encounter'],
+		shortDescription: 'Time remaining on the cast bar of the target, or 0 if it is not casting.',
+		newValue: APLValueTargetCastRemainingTime.create,
+		fields: [AplHelpers.unitFieldConfig('targetUnit', 'targets')],
+	}),
 
 	// Resources
 	currentHealth: inputBuilder({
@@ -794,6 +811,12 @@ const valueKindFactories: { [f in NonNullable<APLValueKind>]: ValueKindConfig<AP
 		newValue: APLValueChannelClipDelay.create,
 		fields: [],
 	}),
+	currentItemSwapSet: inputBuilder({
+		label: 'Current Item Swap Set',
+		shortDescription: 'Name of the currently equipped item swap set, <b>Main</b> for the main gear.',
+		newValue: APLValueCurrentItemSwapSet.create,
+		fields: [],
+	}),
 
 	// Auras
 	auraIsKnown: inputBuilder({
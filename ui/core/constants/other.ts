@@ -18,6 +18,10 @@ export const LEVEL_THRESHOLDS: Record<Phase, number> = {
 
 export const CURRENT_PHASE = Phase.Phase3;
 
+// Version of the settings format of sim requests and exported settings.
+// Must match CurrentAPIVersion in sim/core/settings_migration.go.
+export const CURRENT_API_VERSION = 1;
+
 export const LEVEL_BRACKETS = [25, 40, 50, 60];
 
 // Github pages serves our site under the /classic directory
@@ -58,6 +58,8 @@ export class ActionId {
 					name += ' (Casting)';
 				} else if (tag === 2) {
 					name += ' (Not Casting)';
+				} else if (tag === 3) {
+					name += ' (MP5)';
 				}
 				break;
 			case OtherAction.OtherActionEnergyRegen:
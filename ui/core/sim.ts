@@ -226,6 +226,7 @@ export class Sim {
 		// TODO: remove any replenishment from sim request here? probably makes more sense to do it inside the sim to protect against accidents
 
 		return RaidSimRequest.create({
+			apiVersion: OtherConstants.CURRENT_API_VERSION,
 			raid: raid,
 			encounter: encounter,
 			simOptions: SimOptions.create({